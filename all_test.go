@@ -5,14 +5,20 @@
 package minigmp
 
 import (
+	"context"
+	cryptorand "crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
+	"math/bits"
 	"math/rand"
 	"os"
 	"path"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 	"unsafe"
 
 	"github.com/cznic/ccgo/crt"
@@ -85,6 +91,10 @@ func test(t *testing.T, op string) {
 			ba[i] = byte('0' + rand.Intn(10))
 			bb[i] = byte('0' + rand.Intn(10))
 		}
+		ba[0] = sgn(ba[0])
+		bb[0] = sgn(bb[0])
+		ba = ba[:rand.Intn(nDigits-1)+3]
+		bb = bb[:rand.Intn(nDigits-1)+3]
 		ok := false
 		for _, v := range bb[1:] {
 			if v != '0' {
@@ -94,10 +104,6 @@ func test(t *testing.T, op string) {
 		if !ok {
 			bb[1] = '1'
 		}
-		ba[0] = sgn(ba[0])
-		bb[0] = sgn(bb[0])
-		ba = ba[:rand.Intn(nDigits-1)+3]
-		bb = bb[:rand.Intn(nDigits-1)+3]
 		func() {
 			var r, x, y [1]Xmpz_srcptr
 			Xmpz_init(tls, &r)
@@ -403,3 +409,3076 @@ func benchGmpRem(b *testing.B, sx, sy string) {
 	crt.Free(cy)
 	tls.Close()
 }
+
+func TestMulParallel(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	defer SetMulParallelThreshold(SetMulParallelThreshold(3))
+	defer SetMulParallelism(SetMulParallelism(1))
+
+	var x, y, seq, par [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&x, &y, &seq, &par} {
+		Xmpz_init(tls, v)
+	}
+	defer func() {
+		for _, v := range []*[1]Xmpz_srcptr{&x, &y, &seq, &par} {
+			Xmpz_clear(tls, v)
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		for j, v := range []*[1]Xmpz_srcptr{&x, &y} {
+			s := crt.CString(bigRnd(rnd.Intn(4000) + 1))
+			Xmpz_set_str(tls, v, (*int8)(s), 10)
+			crt.Free(s)
+			if rnd.Intn(2) == 0 {
+				Xmpz_neg(tls, v, v)
+			}
+			if j == 1 && i%3 == 0 {
+				Xmpz_set(tls, v, &x)
+			}
+		}
+		SetMulParallelism(1)
+		Xmpz_mul(tls, &seq, &x, &y)
+		SetMulParallelism(1 + rnd.Intn(8))
+		Xmpz_mul(tls, &par, &x, &y)
+		if Xmpz_cmp(tls, &seq, &par) != 0 {
+			t.Fatalf("%v: parallel and sequential products differ", i)
+		}
+	}
+}
+
+// rndLimbs returns n random limbs biased towards the all zeros and all ones
+// patterns that exercise carry propagation.
+func rndLimbs(n int) []limb {
+	a := make([]limb, n)
+	for i := range a {
+		switch rnd.Intn(4) {
+		case 0:
+			a[i] = 0
+		case 1:
+			a[i] = ^limb(0)
+		default:
+			a[i] = limb(rnd.Uint64())
+		}
+	}
+	return a
+}
+
+// cLimbs returns n > 0 limbs of C memory, released when t completes. The
+// tests comparing with the generated code pass it C memory only, the checkptr
+// instrumentation enabled by -race rejects its pointer arithmetic on Go
+// memory.
+func cLimbs(t testing.TB, tls *crt.TLS, n int) []limb {
+	p := crt.Xmalloc(tls, sizeT(n)*sizeT(unsafe.Sizeof(limb(0))))
+	t.Cleanup(func() { crt.Xfree(tls, p) })
+	return unsafe.Slice((*limb)(p), n)
+}
+
+func eqLimbs(a, b []limb) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i, v := range a {
+		if v != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMpnBits(t *testing.T) {
+	tls := crt.NewTLS()
+	t.Cleanup(tls.Close)
+	for n := 1; n < 20; n++ {
+		a, b, rc := cLimbs(t, tls, n), cLimbs(t, tls, n), cLimbs(t, tls, n)
+		for i := 0; i < 100; i++ {
+			copy(a, rndLimbs(n))
+			copy(b, rndLimbs(n))
+			r1 := rndLimbs(n)
+			r2 := append([]limb(nil), r1...)
+			v := limb(rnd.Uint64())
+			cnt := uint(1 + rnd.Intn(limbBits-1))
+			for _, op := range []struct {
+				name string
+				f, g func(r []limb) limb
+			}{
+				{"add_n", func(r []limb) limb { return addN(r, a, b) }, func(r []limb) limb { return _mpn_add_n_generic(tls, &r[0], &a[0], &b[0], mpSize(n)) }},
+				{"sub_n", func(r []limb) limb { return subN(r, a, b) }, func(r []limb) limb { return _mpn_sub_n_generic(tls, &r[0], &a[0], &b[0], mpSize(n)) }},
+				{"lshift", func(r []limb) limb { return lshift(r, a, cnt) }, func(r []limb) limb { return _mpn_lshift_generic(tls, &r[0], &a[0], mpSize(n), uint32(cnt)) }},
+				{"rshift", func(r []limb) limb { return rshift(r, a, cnt) }, func(r []limb) limb { return _mpn_rshift_generic(tls, &r[0], &a[0], mpSize(n), uint32(cnt)) }},
+				{"mul_1", func(r []limb) limb { return mul1(r, a, v) }, func(r []limb) limb { return _mpn_mul_1_generic(tls, &r[0], &a[0], mpSize(n), v) }},
+				{"addmul_1", func(r []limb) limb { return addmul1(r, a, v) }, func(r []limb) limb { return _mpn_addmul_1_generic(tls, &r[0], &a[0], mpSize(n), v) }},
+				{"submul_1", func(r []limb) limb { return submul1(r, a, v) }, func(r []limb) limb { return _mpn_submul_1_generic(tls, &r[0], &a[0], mpSize(n), v) }},
+			} {
+				copy(r1, r2)
+				copy(rc, r2)
+				if c1, c2 := op.f(r1), op.g(rc); c1 != c2 || !eqLimbs(r1, rc) {
+					t.Fatalf("%s n=%v: got %#x %#x, expected %#x %#x", op.name, n, c1, r1, c2, rc)
+				}
+			}
+		}
+	}
+
+	for i := 0; i < 10000; i++ {
+		u1 := limb(rnd.Uint64()) | 1<<(limbBits-1)
+		u0 := rndLimbs(1)[0]
+		if g, e := Xmpn_invert_3by2(tls, u1, u0), _mpn_invert_3by2_generic(tls, u1, u0); g != e {
+			t.Fatalf("invert_3by2(%#x, %#x): got %#x, expected %#x", u1, u0, g, e)
+		}
+	}
+}
+
+func TestMpnDivBits(t *testing.T) {
+	tls := crt.NewTLS()
+	t.Cleanup(tls.Close)
+	for dn := 1; dn < 8; dn++ {
+		dp, nc, qc := cLimbs(t, tls, dn), cLimbs(t, tls, dn+9), cLimbs(t, tls, dn+9)
+		for i := 0; i < 1000; i++ {
+			nn := dn + rnd.Intn(10)
+			np := rndLimbs(nn)
+			copy(dp, rndLimbs(dn))
+			if dp[dn-1] == 0 {
+				dp[dn-1] = 1
+			}
+			dp[dn-1] >>= uint(rnd.Intn(limbBits))
+			if dp[dn-1] == 0 {
+				dp[dn-1] = 1
+			}
+			var inv Tgmp_div_inverse
+			_mpn_div_qr_invert(tls, &inv, &dp[0], mpSize(dn))
+			n1 := append([]limb(nil), np...)
+			n2 := nc[:nn]
+			copy(n2, np)
+			q1 := make([]limb, nn)
+			q2 := qc[:nn]
+			clear(q2)
+			switch dn {
+			case 1:
+				r1 := _mpn_div_qr_1_preinv(tls, &q1[0], &n1[0], mpSize(nn), &inv)
+				r2 := _mpn_div_qr_1_preinv_generic(tls, &q2[0], &n2[0], mpSize(nn), &inv)
+				if r1 != r2 || !eqLimbs(q1, q2) {
+					t.Fatalf("div_qr_1_preinv %#x / %#x: got %#x %#x, expected %#x %#x", np, dp, q1, r1, q2, r2)
+				}
+			case 2:
+				var r1, r2 [2]limb
+				_mpn_div_qr_2_preinv(tls, &q1[0], &r1[0], &n1[0], mpSize(nn), &inv)
+				_mpn_div_qr_2_preinv_generic(tls, &q2[0], &r2[0], &n2[0], mpSize(nn), &inv)
+				if r1 != r2 || !eqLimbs(q1, q2) {
+					t.Fatalf("div_qr_2_preinv %#x / %#x: got %#x %#x, expected %#x %#x", np, dp, q1, r1, q2, r2)
+				}
+			default:
+				var nh limb
+				if inv.Xshift > 0 {
+					Xmpn_lshift(tls, &dp[0], &dp[0], mpSize(dn), inv.Xshift)
+					nh = Xmpn_lshift(tls, &n1[0], &n1[0], mpSize(nn), inv.Xshift)
+					copy(n2, n1)
+				}
+				_mpn_div_qr_pi1(tls, &q1[0], &n1[0], mpSize(nn), nh, &dp[0], mpSize(dn), inv.Xdi)
+				_mpn_div_qr_pi1_generic(tls, &q2[0], &n2[0], mpSize(nn), nh, &dp[0], mpSize(dn), inv.Xdi)
+				if !eqLimbs(n1, n2) || !eqLimbs(q1, q2) {
+					t.Fatalf("div_qr_pi1 %#x / %#x: got %#x %#x, expected %#x %#x", np, dp, q1, n1, q2, n2)
+				}
+			}
+		}
+	}
+}
+
+func mpzSetString(tls *crt.TLS, x *[1]Xmpz_srcptr, s string) {
+	cs := crt.CString(s)
+	if Xmpz_set_str(tls, x, (*int8)(cs), 10) != 0 {
+		panic(s)
+	}
+	crt.Free(cs)
+}
+
+func mpzString(tls *crt.TLS, x *[1]Xmpz_srcptr) string {
+	cs := Xmpz_get_str(tls, nil, 10, x)
+	s := crt.GoString(cs)
+	crt.Free(unsafe.Pointer(cs))
+	return s
+}
+
+func TestPowm(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var b, e, m, r, g [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&b, &e, &m, &r, &g} {
+		Xmpz_init(tls, v)
+	}
+	defer func() {
+		for _, v := range []*[1]Xmpz_srcptr{&b, &e, &m, &r, &g} {
+			Xmpz_clear(tls, v)
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		bb, _ := big.NewInt(0).SetString(bigRnd(rnd.Intn(1000)+1), 10)
+		if rnd.Intn(2) == 0 {
+			bb.Neg(bb)
+		}
+		mb, _ := big.NewInt(0).SetString(bigRnd(rnd.Intn(600)+1), 10)
+		switch i % 4 {
+		case 0:
+			mb.SetBit(mb, 0, 1)
+		case 1:
+			mb.SetBit(mb, 0, 0)
+		case 2:
+			mb.Lsh(mb, uint(rnd.Intn(3*limbBits)))
+		}
+		if mb.Sign() == 0 {
+			mb.SetInt64(1)
+		}
+		if rnd.Intn(4) == 0 {
+			mb.Neg(mb)
+		}
+		var eb *big.Int
+		switch rnd.Intn(10) {
+		case 0:
+			eb = big.NewInt(int64(rnd.Intn(3)))
+		default:
+			eb, _ = big.NewInt(0).SetString(bigRnd(rnd.Intn(1500)+1), 10)
+		}
+		// mpz_invert fails for |m| == 1.
+		if rnd.Intn(5) == 0 && mb.CmpAbs(big.NewInt(1)) != 0 && big.NewInt(0).ModInverse(bb, big.NewInt(0).Abs(mb)) != nil {
+			eb.Neg(eb)
+		}
+		mpzSetString(tls, &b, bb.String())
+		mpzSetString(tls, &e, eb.String())
+		mpzSetString(tls, &m, mb.String())
+
+		Xmpz_powm(tls, &r, &b, &e, &m)
+		_mpz_powm_generic(tls, &g, &b, &e, &m)
+		if Xmpz_cmp(tls, &r, &g) != 0 {
+			t.Fatalf("%v^%v mod %v: got %v, expected %v", bb, eb, mb, mpzString(tls, &r), mpzString(tls, &g))
+		}
+
+		if eb.Sign() != 0 {
+			// big.Int.Exp gets the sign wrong for a negative base and a
+			// negative odd exponent, so the base is reduced first.
+			x := big.NewInt(0).Mod(bb, big.NewInt(0).Abs(mb))
+			x.Exp(x, eb, mb)
+			if g, e := mpzString(tls, &r), x.String(); g != e {
+				t.Fatalf("%v^%v mod %v: got %v, expected %v", bb, eb, mb, g, e)
+			}
+		}
+
+		// Aliasing.
+		for _, v := range []*[1]Xmpz_srcptr{&b, &e, &m} {
+			Xmpz_set(tls, &g, v)
+			switch v {
+			case &b:
+				Xmpz_powm(tls, &b, &b, &e, &m)
+			case &e:
+				Xmpz_powm(tls, &e, &b, &e, &m)
+			case &m:
+				Xmpz_powm(tls, &m, &b, &e, &m)
+			}
+			if Xmpz_cmp(tls, v, &r) != 0 {
+				t.Fatalf("%v^%v mod %v: aliased result %v, expected %v", bb, eb, mb, mpzString(tls, v), mpzString(tls, &r))
+			}
+
+			Xmpz_set(tls, v, &g)
+		}
+
+		el := limb(rnd.Uint64())
+		Xmpz_powm_ui(tls, &r, &b, el, &m)
+		Xmpz_set_ui(tls, &e, ulong(el))
+		_mpz_powm_generic(tls, &g, &b, &e, &m)
+		if Xmpz_cmp(tls, &r, &g) != 0 {
+			t.Fatalf("%v^%v mod %v: got %v, expected %v", bb, el, mb, mpzString(tls, &r), mpzString(tls, &g))
+		}
+	}
+}
+
+func TestModulus(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var a, b, e, m, r, x [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&a, &b, &e, &m, &r, &x} {
+		Xmpz_init(tls, v)
+	}
+	defer func() {
+		for _, v := range []*[1]Xmpz_srcptr{&a, &b, &e, &m, &r, &x} {
+			Xmpz_clear(tls, v)
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		mb, _ := big.NewInt(0).SetString(bigRnd(rnd.Intn(600)+1), 10)
+		if i%2 == 0 {
+			mb.SetBit(mb, 0, 1)
+		}
+		if mb.Sign() == 0 {
+			mb.SetInt64(2)
+		}
+		ab, _ := big.NewInt(0).SetString(bigRnd(rnd.Intn(800)+1), 10)
+		bb, _ := big.NewInt(0).SetString(bigRnd(rnd.Intn(800)+1), 10)
+		eb, _ := big.NewInt(0).SetString(bigRnd(rnd.Intn(300)+1), 10)
+		if rnd.Intn(2) == 0 {
+			ab.Neg(ab)
+		}
+		if rnd.Intn(10) == 0 {
+			eb.SetInt64(0)
+		}
+		if rnd.Intn(5) == 0 && mb.Cmp(big.NewInt(1)) != 0 && big.NewInt(0).ModInverse(ab, mb) != nil {
+			eb.Neg(eb)
+		}
+		mpzSetString(tls, &a, ab.String())
+		mpzSetString(tls, &b, bb.String())
+		mpzSetString(tls, &e, eb.String())
+		mpzSetString(tls, &m, mb.String())
+		if rnd.Intn(2) == 0 {
+			Xmpz_neg(tls, &m, &m)
+		}
+		mod := NewModulus(tls, &m)
+		if g, e := mod.Montgomery(), mb.Bit(0) == 1; g != e {
+			t.Fatalf("%v: Montgomery() = %v", mb, g)
+		}
+
+		mod.Modulus(tls, &r)
+		if g, e := mpzString(tls, &r), mb.String(); g != e {
+			t.Fatalf("Modulus: got %v, expected %v", g, e)
+		}
+
+		check := func(op, g string, e *big.Int) {
+			if e.Sign() < 0 {
+				e.Add(e, mb)
+			}
+			if g != e.String() {
+				t.Fatalf("%v: %s(%v, %v) mod %v: got %v, expected %v", i, op, ab, bb, mb, g, e)
+			}
+		}
+
+		mod.ToMontgomery(tls, &r, &a)
+		mod.FromMontgomery(tls, &x, &r)
+		check("roundtrip", mpzString(tls, &x), big.NewInt(0).Mod(ab, mb))
+
+		mod.ToMontgomery(tls, &x, &b)
+		mod.MulMod(tls, &x, &r, &x)
+		mod.FromMontgomery(tls, &x, &x)
+		check("MulMod", mpzString(tls, &x), big.NewInt(0).Mod(big.NewInt(0).Mul(ab, bb), mb))
+
+		mod.SqrMod(tls, &x, &r)
+		mod.FromMontgomery(tls, &x, &x)
+		check("SqrMod", mpzString(tls, &x), big.NewInt(0).Mod(big.NewInt(0).Mul(ab, ab), mb))
+
+		mod.AddMod(tls, &x, &a, &b)
+		check("AddMod", mpzString(tls, &x), big.NewInt(0).Mod(big.NewInt(0).Add(ab, bb), mb))
+
+		mod.SubMod(tls, &x, &a, &b)
+		check("SubMod", mpzString(tls, &x), big.NewInt(0).Mod(big.NewInt(0).Sub(ab, bb), mb))
+
+		mod.ExpMod(tls, &x, &r, &e)
+		mod.FromMontgomery(tls, &x, &x)
+		Xmpz_powm(tls, &r, &a, &e, &m)
+		if eb.Sign() == 0 {
+			// mpz_powm returns 1 even for m == 1.
+			Xmpz_mod(tls, &r, &r, &m)
+		}
+		check("ExpMod", mpzString(tls, &x), mustBig(mpzString(tls, &r)))
+	}
+}
+
+func mustBig(s string) *big.Int {
+	n, ok := big.NewInt(0).SetString(s, 10)
+	if !ok {
+		panic(s)
+	}
+
+	return n
+}
+
+func TestMpnSec(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var x, y, z, w [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&x, &y} {
+		Xmpz_init(tls, v)
+	}
+	defer func() {
+		for _, v := range []*[1]Xmpz_srcptr{&x, &y} {
+			Xmpz_clear(tls, v)
+		}
+	}()
+
+	for n := 1; n < 8; n++ {
+		ns := mpSize(n)
+		for i := 0; i < 200; i++ {
+			a := rndLimbs(n)
+			b := rndLimbs(n)
+			cnd := limb(rnd.Intn(2)) << uint(rnd.Intn(limbBits))
+			r1 := make([]limb, n)
+			r2 := make([]limb, n)
+			c1 := Xmpn_cnd_add_n(tls, cnd, &r1[0], &a[0], &b[0], ns)
+			c2 := limb(0)
+			copy(r2, a)
+			if cnd != 0 {
+				c2 = Xmpn_add_n(tls, &r2[0], &a[0], &b[0], ns)
+			}
+			if c1 != c2 || !eqLimbs(r1, r2) {
+				t.Fatalf("cnd_add_n(%#x, %#x, %#x): got %#x %#x, expected %#x %#x", cnd, a, b, c1, r1, c2, r2)
+			}
+
+			c1 = Xmpn_cnd_sub_n(tls, cnd, &r1[0], &a[0], &b[0], ns)
+			c2 = 0
+			copy(r2, a)
+			if cnd != 0 {
+				c2 = Xmpn_sub_n(tls, &r2[0], &a[0], &b[0], ns)
+			}
+			if c1 != c2 || !eqLimbs(r1, r2) {
+				t.Fatalf("cnd_sub_n(%#x, %#x, %#x): got %#x %#x, expected %#x %#x", cnd, a, b, c1, r1, c2, r2)
+			}
+
+			copy(r1, a)
+			copy(r2, b)
+			Xmpn_cnd_swap(tls, cnd, &r1[0], &r2[0], ns)
+			if cnd != 0 {
+				r1, r2 = r2, r1
+			}
+			if !eqLimbs(r1, a) || !eqLimbs(r2, b) {
+				t.Fatalf("cnd_swap(%#x, %#x, %#x): got %#x %#x", cnd, a, b, r1, r2)
+			}
+
+			v := rndLimbs(1)[0]
+			if c1, c2 := Xmpn_sec_add_1(tls, &r1[0], &a[0], ns, v, nil), Xmpn_add_1(tls, &r2[0], &a[0], ns, v); c1 != c2 || !eqLimbs(r1, r2) {
+				t.Fatalf("sec_add_1(%#x, %#x): got %#x %#x, expected %#x %#x", a, v, c1, r1, c2, r2)
+			}
+
+			if c1, c2 := Xmpn_sec_sub_1(tls, &r1[0], &a[0], ns, v, nil), Xmpn_sub_1(tls, &r2[0], &a[0], ns, v); c1 != c2 || !eqLimbs(r1, r2) {
+				t.Fatalf("sec_sub_1(%#x, %#x): got %#x %#x, expected %#x %#x", a, v, c1, r1, c2, r2)
+			}
+
+			bn := 1 + rnd.Intn(n)
+			p1 := make([]limb, n+bn)
+			p2 := make([]limb, n+bn)
+			Xmpn_sec_mul(tls, &p1[0], &a[0], ns, &b[0], mpSize(bn), nil)
+			Xmpn_mul(tls, &p2[0], &a[0], ns, &b[0], mpSize(bn))
+			if !eqLimbs(p1, p2) {
+				t.Fatalf("sec_mul(%#x, %#x): got %#x, expected %#x", a, b[:bn], p1, p2)
+			}
+
+			p1 = make([]limb, 2*n)
+			p2 = make([]limb, 2*n)
+			Xmpn_sec_sqr(tls, &p1[0], &a[0], ns, nil)
+			Xmpn_sqr(tls, &p2[0], &a[0], ns)
+			if !eqLimbs(p1, p2) {
+				t.Fatalf("sec_sqr(%#x): got %#x, expected %#x", a, p1, p2)
+			}
+
+			nents := 1 + rnd.Intn(9)
+			tab := rndLimbs(n * nents)
+			which := rnd.Intn(nents)
+			Xmpn_sec_tabselect(tls, &r1[0], &tab[0], ns, mpSize(nents), mpSize(which))
+			if !eqLimbs(r1, tab[which*n:(which+1)*n]) {
+				t.Fatalf("sec_tabselect(%#x, %v): got %#x", tab, which, r1)
+			}
+
+			// Division by d with a non zero most significant limb.
+			nn := n + rnd.Intn(8)
+			np := rndLimbs(nn)
+			d := append([]limb(nil), a...)
+			if d[n-1] == 0 {
+				d[n-1] = 1
+			}
+			n1 := append([]limb(nil), np...)
+			n2 := append([]limb(nil), np...)
+			n3 := append([]limb(nil), np...)
+			q1 := make([]limb, nn-n+1)
+			q2 := make([]limb, nn-n+1)
+			q1[nn-n] = Xmpn_sec_div_qr(tls, &q1[0], &n1[0], mpSize(nn), &d[0], ns, &make([]limb, Xmpn_sec_div_qr_itch(tls, mpSize(nn), ns))[0])
+			_mpn_div_qr(tls, &q2[0], &n2[0], mpSize(nn), &d[0], ns)
+			if !eqLimbs(q1, q2) || !eqLimbs(n1[:n], n2[:n]) {
+				t.Fatalf("sec_div_qr(%#x, %#x): got %#x %#x, expected %#x %#x", np, d, q1, n1[:n], q2, n2[:n])
+			}
+
+			Xmpn_sec_div_r(tls, &n3[0], mpSize(nn), &d[0], ns, &make([]limb, Xmpn_sec_div_r_itch(tls, mpSize(nn), ns))[0])
+			if !eqLimbs(n3[:n], n2[:n]) {
+				t.Fatalf("sec_div_r(%#x, %#x): got %#x, expected %#x", np, d, n3[:n], n2[:n])
+			}
+
+			// Odd modulus, powm and invert.
+			m := append([]limb(nil), d...)
+			m[0] |= 1
+			Xmpz_roinit_n(tls, &z, &m[0], ns)
+			bn = 1 + rnd.Intn(2*n)
+			bp := rndLimbs(bn)
+			enb := ulong(1 + rnd.Intn(n*limbBits))
+			ep := rndLimbs(int((enb + limbBits - 1) / limbBits))
+			if r := enb % limbBits; r != 0 {
+				ep[len(ep)-1] &= 1<<r - 1
+			}
+			Xmpn_sec_powm(tls, &r1[0], &bp[0], mpSize(bn), &ep[0], enb, &m[0], ns, &make([]limb, Xmpn_sec_powm_itch(tls, mpSize(bn), enb, ns))[0])
+			Xmpz_roinit_n(tls, &w, &bp[0], mpSize(bn))
+			Xmpz_roinit_n(tls, &y, &ep[0], mpSize(len(ep)))
+			Xmpz_powm(tls, &x, &w, &y, &z)
+			Xmpz_mod(tls, &x, &x, &z) // mpz_powm returns 1 for m == 1 and e == 0.
+			if r2 := padLimbs(mpzLimbs(&x), n); !eqLimbs(r1, r2) {
+				t.Fatalf("sec_powm(%#x, %#x, %#x): got %#x, expected %#x", bp, ep, m, r1, r2)
+			}
+
+			Xmpz_roinit_n(tls, &w, &b[0], ns)
+			Xmpz_mod(tls, &y, &w, &z)
+			u := padLimbs(mpzLimbs(&y), n)
+			ok1 := Xmpn_sec_invert(tls, &r1[0], &u[0], &m[0], ns, ulong(2*n*limbBits), &make([]limb, Xmpn_sec_invert_itch(tls, ns))[0])
+			ok2 := Xmpz_invert(tls, &x, &y, &z)
+			if n == 1 && m[0] == 1 {
+				// mpz_invert fails for a modulus of 1, mpn_sec_invert
+				// does not.
+				ok2 = 1
+				Xmpz_set_ui(tls, &x, 0)
+			}
+			if ok1 != ok2 || ok1 != 0 && !eqLimbs(r1, padLimbs(mpzLimbs(&x), n)) {
+				t.Fatalf("sec_invert(%#x, %#x): got %v %#x, expected %v %#x", mpzLimbs(&y), m, ok1, r1, ok2, mpzLimbs(&x))
+			}
+		}
+	}
+}
+
+func TestSecureMemory(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	blocks := map[unsafe.Pointer]sizeT{}
+	var reallocs, dirty int
+	alloc := func(tls *crt.TLS, size sizeT) unsafe.Pointer {
+		p := crt.Xmalloc(tls, size)
+		blocks[p] = size
+		return p
+	}
+	realloc := func(tls *crt.TLS, old unsafe.Pointer, oldSize, newSize sizeT) unsafe.Pointer {
+		reallocs++
+		delete(blocks, old)
+		p := crt.Xrealloc(tls, old, newSize)
+		blocks[p] = newSize
+		return p
+	}
+	free := func(tls *crt.TLS, p unsafe.Pointer, size sizeT) {
+		n, ok := blocks[p]
+		if !ok {
+			t.Fatalf("free of an unknown block %p", p)
+		}
+
+		for _, v := range unsafe.Slice((*byte)(p), n) {
+			if v != 0 {
+				dirty++
+				break
+			}
+		}
+		delete(blocks, p)
+		crt.Xfree(tls, p)
+	}
+
+	Xmp_set_memory_functions(tls, alloc, realloc, free)
+
+	defer Xmp_set_memory_functions(tls, nil, nil, nil)
+
+	run := func() {
+		var x, y [1]Xmpz_srcptr
+		Xmpz_init(tls, &x)
+		Xmpz_init(tls, &y)
+		mpzSetString(tls, &x, bigRnd(1000))
+		mpzSetString(tls, &y, bigRnd(900))
+		Xmpz_mul(tls, &x, &x, &x)
+		Xmpz_tdiv_qr(tls, &x, &y, &x, &y)
+		Xmpz_powm(tls, &x, &x, &x, &y)
+		Xmpz_realloc2(tls, &x, 64) // Shrink.
+		var freeFunc func(*crt.TLS, unsafe.Pointer, sizeT)
+		Xmp_get_memory_functions(tls, nil, nil, &freeFunc)
+		// The caller owns the string, mpz_get_str allocates
+		// mpz_sizeinbase+2 bytes for it.
+		n := sizeT(Xmpz_sizeinbase(tls, &y, 10) + 2)
+		s := Xmpz_get_str(tls, nil, 10, &y)
+		wipe(unsafe.Pointer(s), n)
+		freeFunc(tls, unsafe.Pointer(s), n)
+		Xmpz_clear(tls, &x)
+		Xmpz_clear(tls, &y)
+	}
+
+	// Make sure the test detects data left behind.
+	run()
+	if dirty == 0 || reallocs == 0 {
+		t.Fatalf("dirty %v, reallocs %v", dirty, reallocs)
+	}
+
+	defer SetSecureMemory(SetSecureMemory(true))
+
+	// Saving and restoring the memory functions must keep the secure
+	// mode.
+	var a func(*crt.TLS, sizeT) unsafe.Pointer
+	var r func(*crt.TLS, unsafe.Pointer, sizeT, sizeT) unsafe.Pointer
+	var f func(*crt.TLS, unsafe.Pointer, sizeT)
+	Xmp_get_memory_functions(tls, &a, &r, &f)
+	Xmp_set_memory_functions(tls, a, r, f)
+
+	dirty, reallocs = 0, 0
+	run()
+	if dirty != 0 || reallocs != 0 {
+		t.Fatalf("dirty %v, reallocs %v", dirty, reallocs)
+	}
+
+	if len(blocks) != 0 {
+		t.Fatalf("%v blocks leaked", len(blocks))
+	}
+}
+
+func TestContext(t *testing.T) {
+	type counter struct {
+		allocs, frees int
+		blocks        map[unsafe.Pointer]struct{}
+	}
+	newCounter := func() *counter { return &counter{blocks: map[unsafe.Pointer]struct{}{}} }
+	install := func(c *Context, n *counter) {
+		c.SetMemoryFunctions(
+			func(tls *crt.TLS, size sizeT) unsafe.Pointer {
+				n.allocs++
+				p := crt.Xmalloc(tls, size)
+				n.blocks[p] = struct{}{}
+				return p
+			},
+			func(tls *crt.TLS, old unsafe.Pointer, oldSize, newSize sizeT) unsafe.Pointer {
+				if _, ok := n.blocks[old]; !ok {
+					panic("realloc of a foreign block")
+				}
+
+				delete(n.blocks, old)
+				p := crt.Xrealloc(tls, old, newSize)
+				n.blocks[p] = struct{}{}
+				return p
+			},
+			func(tls *crt.TLS, p unsafe.Pointer, size sizeT) {
+				if _, ok := n.blocks[p]; !ok {
+					panic("free of a foreign block")
+				}
+
+				n.frees++
+				delete(n.blocks, p)
+				crt.Xfree(tls, p)
+			},
+		)
+	}
+
+	// The default context must not be affected.
+	dflt := newCounter()
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	Xmp_set_memory_functions(tls, nil, nil, nil)
+	install(defaultContext, dflt)
+
+	defer Xmp_set_memory_functions(tls, nil, nil, nil)
+
+	const workers = 4
+	var wg sync.WaitGroup
+	counters := make([]*counter, workers)
+	errs := make([]error, workers)
+	for i := range counters {
+		counters[i] = newCounter()
+		var ops [][2]string
+		for j := 0; j < 100; j++ {
+			ops = append(ops, [2]string{bigRnd(200 + j), bigRnd(100 + j)})
+		}
+		c := NewContext()
+		install(c, counters[i])
+		wg.Add(1)
+		go func(i int, c *Context, ops [][2]string) {
+			defer wg.Done()
+			defer c.Close()
+
+			if old := c.SetSecureMemory(i&1 != 0); old {
+				errs[i] = fmt.Errorf("new context in secure mode")
+				return
+			}
+
+			tls := c.TLS()
+			var x, y [1]Xmpz_srcptr
+			Xmpz_init(tls, &x)
+			Xmpz_init(tls, &y)
+			_, _, free := c.MemoryFunctions()
+			for _, v := range ops {
+				mpzSetString(tls, &x, v[0])
+				mpzSetString(tls, &y, v[1])
+				Xmpz_mul(tls, &x, &x, &y)
+				cs := Xmpz_get_str(tls, nil, 10, &x)
+				g := crt.GoString(cs)
+				free(tls, unsafe.Pointer(cs), 0)
+				if e := new(big.Int).Mul(mustBig(v[0]), mustBig(v[1])).String(); g != e {
+					errs[i] = fmt.Errorf("got %v, expected %v", g, e)
+					break
+				}
+			}
+			Xmpz_clear(tls, &x)
+			Xmpz_clear(tls, &y)
+		}(i, c, ops)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatal(i, err)
+		}
+	}
+
+	for i, n := range counters {
+		if n.allocs == 0 || n.frees == 0 || len(n.blocks) != 0 {
+			t.Fatalf("context %v: allocs %v, frees %v, leaked %v", i, n.allocs, n.frees, len(n.blocks))
+		}
+	}
+	if dflt.allocs != 0 || dflt.frees != 0 {
+		t.Fatalf("default context: allocs %v, frees %v", dflt.allocs, dflt.frees)
+	}
+
+	var x [1]Xmpz_srcptr
+	Xmpz_init_set_ui(tls, &x, 42)
+	Xmpz_mul(tls, &x, &x, &x)
+	Xmpz_clear(tls, &x)
+	if dflt.allocs == 0 || dflt.frees == 0 || len(dflt.blocks) != 0 {
+		t.Fatalf("default context: allocs %v, frees %v, leaked %v", dflt.allocs, dflt.frees, len(dflt.blocks))
+	}
+
+	// A Context not closed is released when it becomes unreachable.
+	n := liveContexts.Load()
+	for i := 0; i < 10; i++ {
+		NewContext().SetMaxBits(1)
+	}
+	for i := 0; liveContexts.Load() != n; i++ {
+		if i == 100 {
+			t.Fatalf("%v contexts not released", liveContexts.Load()-n)
+		}
+
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// concurrencyScenario calls every Xmpz_* function, except Xmpz_out_str,
+// which writes to stdout, and returns a transcript of the results. a and b
+// are only read.
+func concurrencyScenario(tls *crt.TLS, seed int64, a, b *[1]Xmpz_srcptr) string {
+	rng := rand.New(rand.NewSource(seed))
+	var w strings.Builder
+	out := func(v ...interface{}) { fmt.Fprintln(&w, v...) }
+	z := func(v *[1]Xmpz_srcptr) {
+		// Conversion to a power of two base is fast.
+		s := Xmpz_get_str(tls, nil, 16, v)
+		out(crt.GoString(s))
+		crt.Free(unsafe.Pointer(s))
+	}
+	rndInt := func(bits int) string {
+		n := new(big.Int).Rand(rng, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		if rng.Intn(2) == 0 {
+			n.Neg(n)
+		}
+		return n.String()
+	}
+
+	var x, y, q, r, s, t [1]Xmpz_srcptr
+	Xmpz_init(tls, &x)
+	Xmpz_init2(tls, &y, 1000)
+	cs := crt.CString(rndInt(700))
+	Xmpz_init_set_str(tls, &q, (*int8)(cs), 10)
+	crt.Free(cs)
+	Xmpz_init_set_si(tls, &r, -long(rng.Int31()))
+	Xmpz_init_set_ui(tls, &s, ulong(rng.Uint32()))
+	Xmpz_init_set_d(tls, &t, rng.NormFloat64()*1e30)
+	z(&q)
+	z(&r)
+	z(&s)
+	z(&t)
+
+	mpzSetString(tls, &x, rndInt(1+rng.Intn(3000)))
+	mpzSetString(tls, &y, rndInt(1+rng.Intn(2000)))
+	if Xmpz_sgn(tls, &y) == 0 {
+		Xmpz_set_ui(tls, &y, 7)
+	}
+
+	// Arithmetic.
+	Xmpz_add(tls, &q, &x, a)
+	z(&q)
+	Xmpz_sub(tls, &q, b, &x)
+	z(&q)
+	Xmpz_mul(tls, &q, &x, a)
+	z(&q)
+	Xmpz_add_ui(tls, &q, &x, 12345)
+	z(&q)
+	Xmpz_sub_ui(tls, &q, &x, 12345)
+	z(&q)
+	Xmpz_ui_sub(tls, &q, 12345, &x)
+	z(&q)
+	Xmpz_mul_ui(tls, &q, a, 98765)
+	z(&q)
+	Xmpz_mul_si(tls, &q, b, -98765)
+	z(&q)
+	Xmpz_mul_2exp(tls, &q, &x, 77)
+	z(&q)
+	Xmpz_addmul(tls, &q, &x, &y)
+	z(&q)
+	Xmpz_addmul_ui(tls, &q, a, 3)
+	z(&q)
+	Xmpz_submul(tls, &q, b, &y)
+	z(&q)
+	Xmpz_submul_ui(tls, &q, &x, 5)
+	z(&q)
+	Xmpz_neg(tls, &q, &q)
+	z(&q)
+	Xmpz_abs(tls, &q, &q)
+	z(&q)
+	Xmpz_pow_ui(tls, &q, &y, 3)
+	z(&q)
+	Xmpz_ui_pow_ui(tls, &q, 3, ulong(rng.Intn(200)))
+	z(&q)
+
+	// Division.
+	for _, f := range []func(*crt.TLS, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr){
+		Xmpz_cdiv_q, Xmpz_cdiv_r, Xmpz_fdiv_q, Xmpz_fdiv_r, Xmpz_tdiv_q, Xmpz_tdiv_r, Xmpz_mod,
+	} {
+		f(tls, &q, a, &y)
+		z(&q)
+	}
+	for _, f := range []func(*crt.TLS, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr){
+		Xmpz_cdiv_qr, Xmpz_fdiv_qr, Xmpz_tdiv_qr,
+	} {
+		f(tls, &q, &r, &x, b)
+		z(&q)
+		z(&r)
+	}
+	for _, f := range []func(*crt.TLS, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr, ulong){
+		Xmpz_cdiv_q_2exp, Xmpz_cdiv_r_2exp, Xmpz_fdiv_q_2exp, Xmpz_fdiv_r_2exp, Xmpz_tdiv_q_2exp, Xmpz_tdiv_r_2exp,
+	} {
+		f(tls, &q, &x, 65)
+		z(&q)
+	}
+	for _, f := range []func(*crt.TLS, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr, ulong) ulong{
+		Xmpz_cdiv_q_ui, Xmpz_cdiv_r_ui, Xmpz_fdiv_q_ui, Xmpz_fdiv_r_ui, Xmpz_tdiv_q_ui, Xmpz_tdiv_r_ui, Xmpz_mod_ui,
+	} {
+		out(f(tls, &q, &x, 1000003))
+		z(&q)
+	}
+	for _, f := range []func(*crt.TLS, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr, ulong) ulong{
+		Xmpz_cdiv_qr_ui, Xmpz_fdiv_qr_ui, Xmpz_tdiv_qr_ui,
+	} {
+		out(f(tls, &q, &r, a, 1000003))
+		z(&q)
+		z(&r)
+	}
+	for _, f := range []func(*crt.TLS, *[1]Xmpz_srcptr, ulong) ulong{
+		Xmpz_cdiv_ui, Xmpz_fdiv_ui, Xmpz_tdiv_ui,
+	} {
+		out(f(tls, b, 1000003))
+	}
+	Xmpz_mul(tls, &q, &x, &y)
+	Xmpz_divexact(tls, &r, &q, &y)
+	z(&r)
+	Xmpz_mul_ui(tls, &q, &x, 1000003)
+	Xmpz_divexact_ui(tls, &r, &q, 1000003)
+	z(&r)
+	out(Xmpz_divisible_p(tls, &q, &x), Xmpz_divisible_ui_p(tls, &q, 1000003), Xmpz_congruent_p(tls, &q, a, &y))
+
+	// Number theory.
+	Xmpz_gcd(tls, &q, &x, a)
+	z(&q)
+	out(Xmpz_gcd_ui(tls, &q, &x, 3*5*7*11*13))
+	z(&q)
+	Xmpz_gcdext(tls, &q, &r, &s, &x, &y)
+	z(&q)
+	z(&r)
+	z(&s)
+	Xmpz_lcm(tls, &q, &x, &y)
+	z(&q)
+	Xmpz_lcm_ui(tls, &q, b, 1000003)
+	z(&q)
+	out(Xmpz_invert(tls, &q, &x, &y))
+	z(&q)
+	Xmpz_abs(tls, &r, &y)
+	Xmpz_abs(tls, &s, &s)
+	Xmpz_powm(tls, &q, a, &s, &r)
+	z(&q)
+	Xmpz_powm_ui(tls, &q, &x, 65537, &r)
+	z(&q)
+	Xmpz_abs(tls, &r, &x)
+	Xmpz_sqrt(tls, &q, &r)
+	z(&q)
+	Xmpz_sqrtrem(tls, &q, &s, &r)
+	z(&q)
+	z(&s)
+	Xmpz_rootrem(tls, &q, &s, &x, 3)
+	z(&q)
+	z(&s)
+	out(Xmpz_root(tls, &q, &r, 5), Xmpz_perfect_square_p(tls, &r))
+	z(&q)
+	out(Xmpz_probab_prime_p(tls, &r, 10))
+	Xmpz_fac_ui(tls, &q, ulong(rng.Intn(100)))
+	z(&q)
+	Xmpz_bin_uiui(tls, &q, ulong(rng.Intn(200)), ulong(rng.Intn(100)))
+	z(&q)
+
+	// Logic and bits.
+	for _, f := range []func(*crt.TLS, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr, *[1]Xmpz_srcptr){
+		Xmpz_and, Xmpz_ior, Xmpz_xor,
+	} {
+		f(tls, &q, &x, a)
+		z(&q)
+	}
+	Xmpz_com(tls, &q, &x)
+	z(&q)
+	Xmpz_set(tls, &q, &x)
+	Xmpz_setbit(tls, &q, 100)
+	Xmpz_clrbit(tls, &q, 3)
+	Xmpz_combit(tls, &q, 200)
+	z(&q)
+	Xmpz_abs(tls, &r, &x)
+	out(Xmpz_tstbit(tls, &x, 7), Xmpz_popcount(tls, &r), Xmpz_hamdist(tls, &r, &q), Xmpz_scan0(tls, &x, 5), Xmpz_scan1(tls, &x, 5))
+
+	// Comparison and conversion.
+	out(Xmpz_cmp(tls, &x, a), Xmpz_cmp_si(tls, &x, -5), Xmpz_cmp_ui(tls, &x, 5), Xmpz_cmp_d(tls, &x, 1e40))
+	out(Xmpz_cmpabs(tls, &x, b), Xmpz_cmpabs_ui(tls, &x, 5), Xmpz_cmpabs_d(tls, &x, 1e40), Xmpz_sgn(tls, &x))
+	out(Xmpz_get_d(tls, &x), Xmpz_get_si(tls, &x), Xmpz_get_ui(tls, &x), Xmpz_fits_slong_p(tls, &x), Xmpz_fits_ulong_p(tls, &x))
+	out(Xmpz_size(tls, &x), Xmpz_sizeinbase(tls, &x, 7), Xmpz_getlimbn(tls, &x, 1))
+	Xmpz_set_d(tls, &q, -rng.ExpFloat64()*1e50)
+	z(&q)
+	Xmpz_set_si(tls, &q, -long(rng.Int31()))
+	z(&q)
+	Xmpz_set_ui(tls, &q, ulong(rng.Uint32()))
+	z(&q)
+	cs = crt.CString("-0x" + strings.TrimPrefix(mpzString(tls, &x), "-"))
+	out(Xmpz_set_str(tls, &q, (*int8)(cs), 0))
+	crt.Free(cs)
+	z(&q)
+	str := Xmpz_get_str(tls, nil, 36, &x)
+	out(crt.GoString(str))
+	crt.Free(unsafe.Pointer(str))
+	Xmpz_swap(tls, &q, &y)
+	z(&q)
+	z(&y)
+
+	// Import, export and limbs.
+	var count sizeT
+	p := Xmpz_export(tls, nil, &count, 1, 3, 0, 0, &x)
+	Xmpz_import(tls, &q, count, 1, 3, 0, 0, p)
+	crt.Free(p)
+	z(&q)
+	n := mpSize(1 + rng.Intn(10))
+	d := limbs(Xmpz_limbs_write(tls, &q, n), n)
+	for i := range d {
+		d[i] = limb(rng.Uint64())
+	}
+	Xmpz_limbs_finish(tls, &q, -n)
+	z(&q)
+	m := Xmpz_size(tls, &q)
+	d = limbs(Xmpz_limbs_modify(tls, &q, mpSize(m)+1), mpSize(m)+1)
+	d[m] = 1
+	Xmpz_limbs_finish(tls, &q, mpSize(m)+1)
+	z(&q)
+	var ro [1]Xmpz_srcptr
+	Xmpz_roinit_n(tls, &ro, Xmpz_limbs_read(tls, &q[0]), -mpSize(Xmpz_size(tls, &q)))
+	z(&ro)
+	Xmpz_realloc2(tls, &q, 64)
+	z(&q)
+
+	for _, v := range []*[1]Xmpz_srcptr{&x, &y, &q, &r, &s, &t} {
+		Xmpz_clear(tls, v)
+	}
+	return w.String()
+}
+
+func TestConcurrency(t *testing.T) {
+	defer SetMulParallelism(SetMulParallelism(4))
+	defer SetMulParallelThreshold(SetMulParallelThreshold(8))
+
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var a, b [1]Xmpz_srcptr
+	Xmpz_init(tls, &a)
+	Xmpz_init(tls, &b)
+	mpzSetString(tls, &a, bigRnd(5000))
+	mpzSetString(tls, &b, "-"+bigRnd(3000))
+
+	defer Xmpz_clear(tls, &a)
+	defer Xmpz_clear(tls, &b)
+
+	goroutines, rounds := 32, 20
+	if testing.Short() {
+		goroutines, rounds = 8, 5
+	}
+	expect := map[int64]string{}
+	for seed := int64(0); seed < int64(rounds); seed++ {
+		expect[seed] = concurrencyScenario(tls, seed, &a, &b)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var tls *crt.TLS
+			switch i % 2 {
+			case 0:
+				tls = crt.NewTLS()
+				defer tls.Close()
+			default:
+				c := NewContext()
+				defer c.Close()
+				tls = c.TLS()
+			}
+			for j := 0; j < rounds; j++ {
+				seed := int64((i + j) % rounds)
+				if g, e := concurrencyScenario(tls, seed, &a, &b), expect[seed]; g != e {
+					errs <- fmt.Errorf("goroutine %v, seed %v: transcripts differ", i, seed)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// The limb shared by all values without allocated limbs is never
+	// written to.
+	if g, e := _mpz_initØ00dummy_limbØ001, limb(0xc1a0); g != e {
+		t.Errorf("dummy limb %#x, expected %#x", g, e)
+	}
+}
+
+func TestNoTLS(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var x, y, z [1]Xmpz_srcptr
+	Mpz_init(&x)
+	Mpz_init(&y)
+	Xmpz_init(tls, &z)
+	for i := 0; i < 100; i++ {
+		a, b := bigRnd(1+rnd.Intn(2000)), bigRnd(1+rnd.Intn(1000))
+		cs := crt.CString(a)
+		Mpz_set_str(&x, (*int8)(cs), 10)
+		crt.Free(cs)
+		mpzSetString(tls, &y, b)
+		Mpz_mul(&x, &x, &y)
+		Mpz_sub_ui(&x, &x, 42)
+		Xmpz_tdiv_r(tls, &z, &x, &y)
+		if g, e := Mpz_cmp(&z, &y), Xmpz_cmp(tls, &z, &y); g != e {
+			t.Fatal(g, e)
+		}
+
+		e := new(big.Int).Mul(mustBig(a), mustBig(b))
+		e.Sub(e, big.NewInt(42))
+		if g := mpzString(tls, &x); g != e.String() {
+			t.Fatalf("got %v, expected %v", g, e)
+		}
+
+		if g, e := Mpz_sizeinbase(&x, 2), sizeT(e.BitLen()); g != e {
+			t.Fatalf("got %v, expected %v", g, e)
+		}
+	}
+	Mpz_clear(&x)
+	Mpz_clear(&y)
+	Xmpz_clear(tls, &z)
+}
+
+func wordsBig(a []Word) *big.Int {
+	b := make([]big.Word, len(a))
+	for i, v := range a {
+		b[i] = big.Word(v)
+	}
+	return new(big.Int).SetBits(b)
+}
+
+func TestMpnWords(t *testing.T) {
+	one := big.NewInt(1)
+	for i := 0; i < 2000; i++ {
+		n := rnd.Intn(12)
+		m := rnd.Intn(n + 1)
+		a, b, c := rndLimbs(n), rndLimbs(n), rndLimbs(m)
+		ba, bb, bc := wordsBig(a), wordsBig(b), wordsBig(c)
+		bn := new(big.Int).Lsh(one, uint(n*WordBits)) // B^n
+		w := Word(rnd.Uint64())
+		bw := wordsBig([]Word{w})
+		r := make([]Word, n)
+		check := func(op string, r []Word, carry Word, e *big.Int, ec *big.Int) {
+			t.Helper()
+			if g := wordsBig(r); g.Cmp(e) != 0 || wordsBig([]Word{carry}).Cmp(ec) != 0 {
+				t.Fatalf("%s(%#x, %#x, %#x): got %#x %#x, expected %#x %#x", op, a, b, c, g, carry, e, ec)
+			}
+		}
+		divmod := func(x *big.Int) (*big.Int, *big.Int) { return new(big.Int).DivMod(x, bn, new(big.Int)) }
+
+		q, e := divmod(new(big.Int).Add(ba, bb))
+		check("MpnAddN", r, MpnAddN(r, a, b), e, q)
+		q, e = divmod(new(big.Int).Sub(ba, bb))
+		check("MpnSubN", r, MpnSubN(r, a, b), e, q.Neg(q))
+		q, e = divmod(new(big.Int).Add(ba, bc))
+		check("MpnAdd", r, MpnAdd(r, a, c), e, q)
+		q, e = divmod(new(big.Int).Sub(ba, bc))
+		check("MpnSub", r, MpnSub(r, a, c), e, q.Neg(q))
+		q, e = divmod(new(big.Int).Add(ba, bw))
+		check("MpnAdd1", r, MpnAdd1(r, a, w), e, q)
+		q, e = divmod(new(big.Int).Sub(ba, bw))
+		check("MpnSub1", r, MpnSub1(r, a, w), e, q.Neg(q))
+		q, e = divmod(new(big.Int).Mul(ba, bw))
+		check("MpnMul1", r, MpnMul1(r, a, w), e, q)
+		copy(r, b)
+		q, e = divmod(new(big.Int).Add(bb, new(big.Int).Mul(ba, bw)))
+		check("MpnAddMul1", r, MpnAddMul1(r, a, w), e, q)
+		copy(r, b)
+		q, e = divmod(new(big.Int).Sub(bb, new(big.Int).Mul(ba, bw)))
+		check("MpnSubMul1", r, MpnSubMul1(r, a, w), e, q.Neg(q))
+		q, e = divmod(new(big.Int).Neg(ba))
+		check("MpnNeg", r, MpnNeg(r, a), e, big.NewInt(int64(ba.Sign())))
+		MpnCom(r, a)
+		if g, e := wordsBig(r), new(big.Int).Sub(new(big.Int).Sub(bn, one), ba); g.Cmp(e) != 0 {
+			t.Fatalf("MpnCom(%#x): got %#x, expected %#x", a, g, e)
+		}
+
+		if g, e := MpnCmp(a, b), ba.Cmp(bb); g != e {
+			t.Fatalf("MpnCmp(%#x, %#x): got %v, expected %v", a, b, g, e)
+		}
+
+		pop := 0
+		for _, v := range a {
+			pop += bits.OnesCount64(uint64(v))
+		}
+		if g := MpnPopcount(a); g != pop {
+			t.Fatalf("MpnPopcount(%#x): got %v, expected %v", a, g, pop)
+		}
+
+		if g, e := MpnZeroP(a), ba.Sign() == 0; g != e {
+			t.Fatalf("MpnZeroP(%#x): got %v, expected %v", a, g, e)
+		}
+
+		if g, e := len(MpnNormalize(a)), (ba.BitLen()+WordBits-1)/WordBits; g != e {
+			t.Fatalf("MpnNormalize(%#x): got %v, expected %v", a, g, e)
+		}
+
+		if n == 0 {
+			continue
+		}
+
+		cnt := 1 + uint(rnd.Intn(WordBits-1))
+		q, e = divmod(new(big.Int).Lsh(ba, cnt))
+		check("MpnLshift", r, MpnLshift(r, a, cnt), e, q)
+		q = new(big.Int).Rsh(ba, cnt)
+		e = new(big.Int).Lsh(new(big.Int).Sub(ba, new(big.Int).Lsh(q, cnt)), WordBits-cnt)
+		check("MpnRshift", r, MpnRshift(r, a, cnt), q, e)
+
+		if m == 0 {
+			c = rndLimbs(1)
+			bc = wordsBig(c)
+		}
+		p := make([]Word, len(a)+len(c))
+		MpnMul(p, c, a)
+		if g, e := wordsBig(p), new(big.Int).Mul(ba, bc); g.Cmp(e) != 0 {
+			t.Fatalf("MpnMul(%#x, %#x): got %#x, expected %#x", c, a, g, e)
+		}
+
+		p = make([]Word, 2*len(a))
+		MpnSqr(p, a)
+		if g, e := wordsBig(p), new(big.Int).Mul(ba, ba); g.Cmp(e) != 0 {
+			t.Fatalf("MpnSqr(%#x): got %#x, expected %#x", a, g, e)
+		}
+
+		if w != 0 {
+			copy(r, a)
+			q, e := new(big.Int).QuoRem(ba, bw, new(big.Int))
+			check("MpnDivRem1", r, MpnDivRem1(r, r, w), q, e)
+		}
+
+		d := MpnNormalize(c)
+		if len(d) == 0 || len(d) > len(a) {
+			continue
+		}
+
+		qq, rr := make([]Word, len(a)-len(d)+1), make([]Word, len(d))
+		MpnDivQR(qq, rr, a, d)
+		eq, er := new(big.Int).QuoRem(ba, bc, new(big.Int))
+		if wordsBig(qq).Cmp(eq) != 0 || wordsBig(rr).Cmp(er) != 0 || wordsBig(a).Cmp(ba) != 0 {
+			t.Fatalf("MpnDivQR(%#x, %#x): got %#x %#x, expected %#x %#x", a, d, qq, rr, eq, er)
+		}
+
+		x := MpnNormalize(a)
+		if len(x) == 0 {
+			continue
+		}
+
+		s, rem := make([]Word, (len(x)+1)/2), make([]Word, len(x))
+		rn := MpnSqrtRem(s, rem, x)
+		es := new(big.Int).Sqrt(ba)
+		er = new(big.Int).Sub(ba, new(big.Int).Mul(es, es))
+		if wordsBig(s).Cmp(es) != 0 || wordsBig(rem).Cmp(er) != 0 || rn != len(MpnNormalize(rem)) {
+			t.Fatalf("MpnSqrtRem(%#x): got %#x %#x %v, expected %#x %#x", x, s, rem, rn, es, er)
+		}
+
+		sq := make([]Word, 2*len(s))
+		MpnSqr(sq, s)
+		if !MpnPerfectSquareP(MpnNormalize(sq)) || MpnPerfectSquareP(x) != (er.Sign() == 0) {
+			t.Fatalf("MpnPerfectSquareP(%#x)", x)
+		}
+	}
+}
+
+func TestMpnWordsPanics(t *testing.T) {
+	a := make([]Word, 8)
+	for i := range a {
+		a[i] = Word(i + 1)
+	}
+	for i, f := range []func(){
+		func() { MpnAddN(a[:2], a[:2], a[:3]) },
+		func() { MpnAddN(a[1:3], a[:2], a[4:6]) },
+		func() { MpnSubN(a[:3], a[:2], a[:2]) },
+		func() { MpnAdd(a[:2], a[2:4], a[4:7]) },
+		func() { MpnAdd(a[:3], a[:3], a[:2]) },
+		func() { MpnSub1(a[:2], a[1:3], 1) },
+		func() { MpnMul1(a[:2], a[:3], 1) },
+		func() { MpnAddMul1(a[:2], a[:2], 1) },
+		func() { MpnSubMul1(a[:2], a[1:3], 1) },
+		func() { MpnMul(a[:3], a[4:6], a[6:8]) },
+		func() { MpnMul(a[:4], a[3:5], a[6:8]) },
+		func() { MpnMul(a[:2], a[2:4], nil) },
+		func() { MpnSqr(a[:4], a[3:5]) },
+		func() { MpnLshift(a[:2], a[2:4], 0) },
+		func() { MpnRshift(a[:2], a[2:4], WordBits) },
+		func() { MpnCmp(a[:2], a[:3]) },
+		func() { MpnDivQR(a[:2], a[2:4], a[:3], []Word{1, 0}) },
+		func() { MpnDivQR(a[:2], a[2:4], a[:3], a[1:3]) },
+		func() { MpnDivQR(a[:2], a[1:3], a[:3], []Word{1, 1}) },
+		func() { MpnDivRem1(a[:2], a[2:4], 0) },
+		func() { MpnSqrtRem(a[:1], nil, []Word{1, 0}) },
+		func() { MpnSqrtRem(a[:1], a[1:3], a[2:4]) },
+		func() { MpnPerfectSquareP(nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%v: missing panic", i)
+				}
+			}()
+
+			f()
+		}()
+	}
+
+	// The permitted aliasing.
+	b := []Word{1, 2, 3}
+	MpnAddN(b, b, b)
+	MpnAdd(b, b, b[:0])
+	MpnLshift(b, b, 1)
+	MpnDivRem1(b, b, 3)
+	MpnDivQR(b, make([]Word, 1), b, []Word{1})
+}
+
+func TestBig(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var x, y, ro [1]Xmpz_srcptr
+	Xmpz_init(tls, &x)
+	Xmpz_init(tls, &y)
+	z := new(big.Int)
+	for i := 0; i < 1000; i++ {
+		s := "0"
+		if i != 0 {
+			s = bigRnd(1 + rnd.Intn(3000))
+		}
+		if rnd.Intn(2) == 0 {
+			s = "-" + s
+		}
+		e := mustBig(s)
+		Xmpz_set_big(tls, &x, e)
+		if g := mpzString(tls, &x); g != e.String() {
+			t.Fatalf("Xmpz_set_big: got %v, expected %v", g, e)
+		}
+
+		if g := Xmpz_get_big(tls, z, &x); g != z || g.Cmp(e) != 0 {
+			t.Fatalf("Xmpz_get_big: got %v, expected %v", g, e)
+		}
+
+		// The read only view is usable as an operand and does not copy.
+		Xmpz_roinit_big(tls, &ro, e)
+		if len(e.Bits()) != 0 && ro[0].X_mp_d != (*limb)(unsafe.Pointer(&e.Bits()[0])) {
+			t.Fatal("Xmpz_roinit_big copied the limbs")
+		}
+
+		Xmpz_mul(tls, &y, &ro, &x)
+		if g, e := ToBig(&y), new(big.Int).Mul(e, e); g.Cmp(e) != 0 {
+			t.Fatalf("Xmpz_roinit_big: got %v, expected %v", g, e)
+		}
+
+		f := FromBig(e)
+		if Xmpz_cmp(tls, f, &x) != 0 {
+			t.Fatalf("FromBig: got %v, expected %v", mpzString(tls, f), e)
+		}
+
+		Mpz_clear(f)
+	}
+	Xmpz_clear(tls, &x)
+	Xmpz_clear(tls, &y)
+}
+
+func BenchmarkBig(b *testing.B) {
+	e := mustBig(bigRnd(1e5))
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var x [1]Xmpz_srcptr
+	Xmpz_init(tls, &x)
+
+	defer Xmpz_clear(tls, &x)
+
+	b.Run("set_big", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Xmpz_set_big(tls, &x, e)
+		}
+	})
+	z := new(big.Int)
+	b.Run("get_big", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Xmpz_get_big(tls, z, &x)
+		}
+	})
+	b.Run("string", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			mpzSetString(tls, &x, e.String())
+		}
+	})
+}
+
+func TestLimit(t *testing.T) {
+	c := NewContext()
+
+	defer c.Close()
+
+	tls := c.TLS()
+	const max = 1000
+	if old := c.SetMaxBits(max); old != 0 {
+		t.Fatal(old)
+	}
+
+	var x, y [1]Xmpz_srcptr
+	Xmpz_init(tls, &x)
+	Xmpz_init(tls, &y)
+
+	defer Xmpz_clear(tls, &x)
+	defer Xmpz_clear(tls, &y)
+
+	Xmpz_set_ui(tls, &y, 3)
+	for i, v := range []struct {
+		op string
+		f  func()
+	}{
+		{"mpz_pow_ui", func() { Xmpz_pow_ui(tls, &x, &y, 1<<30) }},
+		{"mpz_ui_pow_ui", func() { Xmpz_ui_pow_ui(tls, &x, 7, 1000) }},
+		{"mpz_mul_2exp", func() { Xmpz_mul_2exp(tls, &x, &y, max) }},
+		{"mpz_fac_ui", func() { Xmpz_fac_ui(tls, &x, 1<<30) }},
+		{"mpz_setbit", func() { Xmpz_setbit(tls, &x, max) }},
+		{"mpz_init2", func() { var z [1]Xmpz_srcptr; Xmpz_init2(tls, &z, 1<<20) }},
+		{"mpz_realloc", func() { Xmpz_realloc2(tls, &x, 1<<20) }},
+		{"mpz_init2", func() { Xmpz_mul(tls, &x, &y, &y) }},
+		{"mpz_realloc", func() { Xmpz_limbs_write(tls, &x, 2+(max+limbBits-1)/limbBits) }},
+	} {
+		Xmpz_set_ui(tls, &x, 42)
+		Xmpz_setbit(tls, &y, max-1)
+		err := Try(v.f)
+		var le *LimitError
+		if !errors.As(err, &le) || le.Op != v.op || le.Max != max || le.Bits <= max {
+			t.Fatalf("%v: %v", i, err)
+		}
+
+		if Xmpz_cmp_ui(tls, &x, 42) != 0 {
+			t.Fatalf("%v: destination modified", i)
+		}
+	}
+
+	// Results within the limit.
+	if err := Try(func() {
+		Xmpz_ui_pow_ui(tls, &x, 3, 300)
+		Xmpz_mul(tls, &x, &x, &x)
+		Xmpz_ui_pow_ui(tls, &x, 2, max-1)
+		Xmpz_setbit(tls, &x, max-1)
+		Xmpz_neg(tls, &x, &x)
+		Xmpz_setbit(tls, &x, 1<<30)
+		Xmpz_ui_pow_ui(tls, &y, 3, 600)
+		Xmpz_fac_ui(tls, &y, 100)
+		Xmpz_mul_2exp(tls, &y, &y, 400)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other panics propagate.
+	func() {
+		defer func() {
+			if e := recover(); e == nil {
+				t.Fatal("missing panic")
+			}
+		}()
+
+		Try(func() { Xmpz_tdiv_q(tls, &x, &x, &[1]Xmpz_srcptr{}) })
+	}()
+
+	// The default context is not affected.
+	var z [1]Xmpz_srcptr
+	Mpz_init(&z)
+	Mpz_ui_pow_ui(&z, 3, 2000)
+	Mpz_clear(&z)
+
+	defer SetMaxBits(SetMaxBits(max))
+
+	if err := Try(func() { Mpz_init2(&z, 1<<20) }); err == nil {
+		t.Fatal("missing error")
+	}
+}
+
+func TestLimitCtx(t *testing.T) {
+	c := NewContext()
+
+	defer c.Close()
+
+	tls := c.TLS()
+	const max = 1000
+	c.SetMaxBits(max)
+	var x, y [1]Xmpz_srcptr
+	Xmpz_init(tls, &x)
+	Xmpz_init(tls, &y)
+
+	defer Xmpz_clear(tls, &x)
+	defer Xmpz_clear(tls, &y)
+
+	bg := context.Background()
+	for i, v := range []struct {
+		op string
+		f  func() error
+	}{
+		{"mpz_pow_ui", func() error { return Xmpz_pow_ui_ctx(tls, bg, &x, &y, 1<<30) }},
+		{"mpz_ui_pow_ui", func() error { return Xmpz_ui_pow_ui_ctx(tls, bg, &x, 7, 1000) }},
+		{"mpz_mul_2exp", func() error { return Xmpz_mul_2exp_ctx(tls, bg, &x, &y, max) }},
+		{"mpz_fac_ui", func() error { return Xmpz_fac_ui_ctx(tls, bg, &x, 1<<30) }},
+		{"mpz_realloc", func() error { return Xmpz_bin_uiui_ctx(tls, bg, &x, 2000, 1000) }},
+		{"mpz_setbit", func() error { return Xmpz_setbit_ctx(tls, bg, &x, max) }},
+		{"mpz_realloc", func() error { return Xmpz_powm_ctx(tls, bg, &x, &y, &y, &y) }},
+	} {
+		Xmpz_set_ui(tls, &x, 42)
+		c.SetMaxBits(0)
+		Xmpz_ui_pow_ui(tls, &y, 3, 2*max)
+		c.SetMaxBits(max)
+		err := v.f()
+		var le *LimitError
+		if !errors.As(err, &le) || le.Op != v.op || le.Max != max || le.Bits <= max {
+			t.Fatalf("%v: %v", i, err)
+		}
+
+		if Xmpz_cmp_ui(tls, &x, 42) != 0 {
+			t.Fatalf("%v: destination modified", i)
+		}
+	}
+
+	// Results within the limit.
+	Xmpz_set_ui(tls, &y, 3)
+	for i, f := range []func() error{
+		func() error { return Xmpz_pow_ui_ctx(tls, bg, &x, &y, 600) },
+		func() error { return Xmpz_ui_pow_ui_ctx(tls, bg, &x, 3, 600) },
+		func() error { return Xmpz_mul_2exp_ctx(tls, bg, &x, &x, 10) },
+		func() error { return Xmpz_setbit_ctx(tls, bg, &x, max-1) },
+		func() error { return Xmpz_fac_ui_ctx(tls, bg, &x, 100) },
+		func() error { return Xmpz_bin_uiui_ctx(tls, bg, &x, 100, 50) },
+	} {
+		if err := f(); err != nil {
+			t.Fatalf("%v: %v", i, err)
+		}
+	}
+	if g, e := mpzString(tls, &x), new(big.Int).Binomial(100, 50).String(); g != e {
+		t.Fatalf("got %v, expected %v", g, e)
+	}
+
+	// A canceled context has priority.
+	canceled, cancel := context.WithCancel(bg)
+	cancel()
+	if err := Xmpz_mul_2exp_ctx(tls, canceled, &x, &y, max); err != context.Canceled {
+		t.Fatal(err)
+	}
+}
+
+func TestLimitLeak(t *testing.T) {
+	c := NewContext()
+
+	defer c.Close()
+
+	tls := c.TLS()
+	blocks := map[unsafe.Pointer]struct{}{}
+	c.SetMemoryFunctions(
+		func(tls *crt.TLS, size sizeT) unsafe.Pointer {
+			p := crt.Xmalloc(tls, size)
+			blocks[p] = struct{}{}
+			return p
+		},
+		func(tls *crt.TLS, old unsafe.Pointer, oldSize, newSize sizeT) unsafe.Pointer {
+			delete(blocks, old)
+			p := crt.Xrealloc(tls, old, newSize)
+			blocks[p] = struct{}{}
+			return p
+		},
+		func(tls *crt.TLS, p unsafe.Pointer, size sizeT) {
+			delete(blocks, p)
+			crt.Xfree(tls, p)
+		},
+	)
+	var x, y, q, r, s [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&x, &y, &q, &r, &s} {
+		Xmpz_init(tls, v)
+
+		defer Xmpz_clear(tls, v)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	ops := []struct {
+		name string
+		f    func()
+	}{
+		{"mul", func() { Xmpz_mul(tls, &q, &x, &y) }},
+		{"addmul", func() { Xmpz_addmul(tls, &q, &x, &y) }},
+		{"submul", func() { Xmpz_submul(tls, &q, &x, &y) }},
+		{"addmul_ui", func() { Xmpz_addmul_ui(tls, &q, &x, 3) }},
+		{"submul_ui", func() { Xmpz_submul_ui(tls, &q, &x, 3) }},
+		{"pow_ui", func() { Xmpz_pow_ui(tls, &q, &y, 3) }},
+		{"ui_pow_ui", func() { Xmpz_ui_pow_ui(tls, &q, 3, ulong(rng.Intn(2000))) }},
+		{"fac_ui", func() { Xmpz_fac_ui(tls, &q, ulong(rng.Intn(300))) }},
+		{"bin_uiui", func() { Xmpz_bin_uiui(tls, &q, ulong(rng.Intn(600)), ulong(rng.Intn(300))) }},
+		{"fdiv_qr", func() { Xmpz_fdiv_qr(tls, &q, &r, &x, &y) }},
+		{"gcd", func() { Xmpz_gcd(tls, &q, &x, &y) }},
+		{"gcdext", func() { Xmpz_gcdext(tls, &q, &r, &s, &x, &y) }},
+		{"invert", func() { Xmpz_invert(tls, &q, &x, &y) }},
+		{"lcm", func() { Xmpz_lcm(tls, &q, &x, &y) }},
+		{"powm", func() { Xmpz_powm(tls, &q, &x, &y, &y) }},
+		{"rootrem", func() { Xmpz_rootrem(tls, &q, &s, &x, 3) }},
+		{"root", func() { Xmpz_root(tls, &q, &x, 3) }},
+		{"sqrtrem", func() { Xmpz_sqrtrem(tls, &q, &s, &x) }},
+		{"congruent_p", func() { Xmpz_congruent_p(tls, &x, &q, &y) }},
+		{"probab_prime_p", func() { Xmpz_probab_prime_p(tls, &x, 5) }},
+		{"powm_ui", func() { Xmpz_powm_ui(tls, &q, &x, 5, &y) }},
+		{"tdiv_qr", func() { Xmpz_tdiv_qr(tls, &q, &r, &x, &y) }},
+		{"nextprime", func() { Xmpz_nextprime(tls, &q, &x) }},
+	}
+	for i := 0; i < 100; i++ {
+		max := uint64(64 + rng.Intn(1500))
+		c.SetMaxBits(0)
+		// The operands exceed the limit sometimes.
+		mpzSetString(tls, &x, bigRnd(1+rng.Intn(int(2*max))))
+		mpzSetString(tls, &y, bigRnd(1+rng.Intn(int(2*max))))
+		c.SetMaxBits(max)
+		for _, v := range ops {
+			n := len(blocks)
+			if err := Try(v.f); err != nil && len(blocks) != n {
+				t.Fatalf("%v %s: %v: %v blocks leaked", i, v.name, err, len(blocks)-n)
+			}
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var b, e, m, r, g [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&b, &e, &m, &r, &g} {
+		Xmpz_init(tls, v)
+
+		defer Xmpz_clear(tls, v)
+	}
+
+	bg := context.Background()
+	canceled, cancel := context.WithCancel(bg)
+	cancel()
+	check := func(op string, err error, f func()) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", op, err)
+		}
+
+		f()
+		if Xmpz_cmp(tls, &r, &g) != 0 {
+			t.Fatalf("%s: got %v, expected %v", op, mpzString(tls, &r), mpzString(tls, &g))
+		}
+	}
+	checkCanceled := func(op string, err error) {
+		t.Helper()
+		if err != context.Canceled || Xmpz_cmp_ui(tls, &r, 42) != 0 {
+			t.Fatalf("%s: %v %v", op, err, mpzString(tls, &r))
+		}
+	}
+	for i := 0; i < 100; i++ {
+		mpzSetString(tls, &b, bigRnd(1+rnd.Intn(300)))
+		mpzSetString(tls, &e, bigRnd(1+rnd.Intn(300)))
+		mpzSetString(tls, &m, bigRnd(1+rnd.Intn(300)))
+		n, k := ulong(rnd.Intn(300)), ulong(rnd.Intn(300))
+		ui := ulong(rnd.Intn(50))
+
+		check("powm", Xmpz_powm_ctx(tls, bg, &r, &b, &e, &m), func() { Xmpz_powm(tls, &g, &b, &e, &m) })
+		check("pow_ui", Xmpz_pow_ui_ctx(tls, bg, &r, &b, ui), func() { Xmpz_pow_ui(tls, &g, &b, ui) })
+		check("fac_ui", Xmpz_fac_ui_ctx(tls, bg, &r, n), func() { Xmpz_fac_ui(tls, &g, n) })
+		check("bin_uiui", Xmpz_bin_uiui_ctx(tls, bg, &r, n, k), func() { Xmpz_bin_uiui(tls, &g, n, k) })
+
+		Xmpz_setbit(tls, &m, 0)
+		if rnd.Intn(2) == 0 {
+			Xmpz_neg(tls, &m, &m)
+		}
+		for _, v := range []*[1]Xmpz_srcptr{&m, &b} {
+			reps := int32(rnd.Intn(30))
+			p, err := Xmpz_probab_prime_p_ctx(tls, bg, v, reps)
+			if g, e := p, Xmpz_probab_prime_p(tls, v, reps); err != nil || g != e {
+				t.Fatalf("probab_prime_p(%v, %v): got %v %v, expected %v", mpzString(tls, v), reps, g, err, e)
+			}
+		}
+
+		Xmpz_set_ui(tls, &r, 42)
+		checkCanceled("powm", Xmpz_powm_ctx(tls, canceled, &r, &b, &e, &m))
+		checkCanceled("pow_ui", Xmpz_pow_ui_ctx(tls, canceled, &r, &b, ui))
+		checkCanceled("fac_ui", Xmpz_fac_ui_ctx(tls, canceled, &r, n))
+		checkCanceled("bin_uiui", Xmpz_bin_uiui_ctx(tls, canceled, &r, n, k))
+		if _, err := Xmpz_probab_prime_p_ctx(tls, canceled, &m, 10); err != context.Canceled {
+			t.Fatal(err)
+		}
+	}
+
+	// Long running operations return promptly.
+	Xmpz_ui_pow_ui(tls, &m, 2, 4423) // Mersenne prime.
+	Xmpz_sub_ui(tls, &m, &m, 1)
+	Xmpz_set(tls, &e, &m)
+	Xmpz_mul_2exp(tls, &e, &e, 1<<16)
+	Xmpz_set_ui(tls, &r, 42)
+	const timeout = 20 * time.Millisecond
+	for _, v := range []struct {
+		op string
+		f  func(context.Context) error
+	}{
+		{"powm", func(ctx context.Context) error { return Xmpz_powm_ctx(tls, ctx, &r, &b, &e, &m) }},
+		{"fac_ui", func(ctx context.Context) error { return Xmpz_fac_ui_ctx(tls, ctx, &r, 1<<20) }},
+		{"bin_uiui", func(ctx context.Context) error { return Xmpz_bin_uiui_ctx(tls, ctx, &r, 1<<21, 1<<20) }},
+		{"probab_prime_p", func(ctx context.Context) error {
+			_, err := Xmpz_probab_prime_p_ctx(tls, ctx, &m, 1000)
+			return err
+		}},
+	} {
+		ctx, cancel := context.WithTimeout(bg, timeout)
+		t0 := time.Now()
+		err := v.f(ctx)
+		d := time.Since(t0)
+		cancel()
+		if err != context.DeadlineExceeded || Xmpz_cmp_ui(tls, &r, 42) != 0 {
+			t.Fatalf("%s: %v", v.op, err)
+		}
+
+		if d > 50*timeout {
+			t.Errorf("%s: returned after %v", v.op, d)
+		}
+	}
+}
+
+func TestMaxWords(t *testing.T) {
+	c := NewContext()
+
+	defer c.Close()
+
+	tls := c.TLS()
+	var x, y [1]Xmpz_srcptr
+	Xmpz_init_set_ui(tls, &x, 42)
+	Xmpz_init_set_ui(tls, &y, 3)
+
+	defer Xmpz_clear(tls, &x)
+	defer Xmpz_clear(tls, &y)
+
+	// MaxWords fits in the size fields and the size in bits of the sum of
+	// two values fits in an unsigned long.
+	if n := 8*unsafe.Sizeof(x[0].X_mp_size) - 1; uint64(MaxWords) > 1<<n-1 || uint64(MaxWords) >= uint64(1)<<(limbBits-1)/WordBits {
+		t.Fatal(MaxWords)
+	}
+
+	const bits = ulong(MaxWords) * WordBits
+	var p limb
+	for i, v := range []struct {
+		op string
+		f  func()
+	}{
+		{"mpz_init2", func() { var z [1]Xmpz_srcptr; Xmpz_init2(tls, &z, bits+1) }},
+		{"mpz_realloc", func() { Xmpz_realloc2(tls, &x, bits+1) }},
+		{"mpz_realloc", func() { Xmpz_mul_2exp(tls, &x, &y, bits) }},
+		{"mpz_realloc", func() { Xmpz_setbit(tls, &x, bits) }},
+		{"mpz_realloc", func() { Xmpz_limbs_write(tls, &x, MaxWords+1) }},
+		{"mpz_limbs_finish", func() { var z [1]Xmpz_srcptr; Xmpz_roinit_n(tls, &z, &p, MaxWords+1) }},
+		{"mpz_limbs_finish", func() { var z [1]Xmpz_srcptr; Xmpz_roinit_n(tls, &z, &p, -MaxWords-1) }},
+	} {
+		err := Try(v.f)
+		var le *LimitError
+		if !errors.As(err, &le) || le.Op != v.op || le.Max != MaxWords*WordBits || le.Bits <= le.Max {
+			t.Fatalf("%v: %v", i, err)
+		}
+
+		if Xmpz_cmp_ui(tls, &x, 42) != 0 {
+			t.Fatalf("%v: %v", i, mpzString(tls, &x))
+		}
+	}
+}
+
+// kronecker returns the Kronecker symbol (a/b) using big.Jacobi for the odd
+// part of b.
+func kronecker(a, b *big.Int) int {
+	if b.Sign() == 0 {
+		if a.CmpAbs(big.NewInt(1)) == 0 {
+			return 1
+		}
+
+		return 0
+	}
+
+	if a.Bit(0) == 0 && b.Bit(0) == 0 {
+		return 0
+	}
+
+	k := 1
+	if b.Sign() < 0 && a.Sign() < 0 {
+		k = -k
+	}
+	y := new(big.Int).Abs(b)
+	v := y.TrailingZeroBits()
+	y.Rsh(y, v)
+	if m := new(big.Int).Mod(a, big.NewInt(8)).Int64(); v%2 != 0 && (m == 3 || m == 5) {
+		k = -k
+	}
+	return k * big.Jacobi(a, y)
+}
+
+func TestKronecker(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var a, b [1]Xmpz_srcptr
+	Xmpz_init(tls, &a)
+	Xmpz_init(tls, &b)
+
+	defer Xmpz_clear(tls, &a)
+	defer Xmpz_clear(tls, &b)
+
+	isPrime := func(n int64) bool { return n > 1 && big.NewInt(n).ProbablyPrime(0) }
+	const n = 300
+	for i := int64(-n); i <= n; i++ {
+		Xmpz_set_si(tls, &a, long(i))
+		for j := int64(-n); j <= n; j++ {
+			Xmpz_set_si(tls, &b, long(j))
+			x, y := big.NewInt(i), big.NewInt(j)
+			e := int32(kronecker(x, y))
+			if g := Xmpz_kronecker(tls, &a, &b); g != e {
+				t.Fatalf("kronecker(%v, %v): got %v, expected %v", i, j, g, e)
+			}
+
+			if j%2 != 0 {
+				if g, e := Xmpz_jacobi(tls, &a, &b), int32(big.Jacobi(x, y)); g != e {
+					t.Fatalf("jacobi(%v, %v): got %v, expected %v", i, j, g, e)
+				}
+			}
+
+			if j > 2 && isPrime(j) {
+				if g := Xmpz_legendre(tls, &a, &b); g != e {
+					t.Fatalf("legendre(%v, %v): got %v, expected %v", i, j, g, e)
+				}
+			}
+
+			if g := Xmpz_kronecker_si(tls, &a, long(j)); g != e {
+				t.Fatalf("kronecker_si(%v, %v): got %v, expected %v", i, j, g, e)
+			}
+
+			if g := Xmpz_si_kronecker(tls, long(i), &b); g != e {
+				t.Fatalf("si_kronecker(%v, %v): got %v, expected %v", i, j, g, e)
+			}
+
+			if j >= 0 {
+				if g := Xmpz_kronecker_ui(tls, &a, ulong(j)); g != e {
+					t.Fatalf("kronecker_ui(%v, %v): got %v, expected %v", i, j, g, e)
+				}
+			}
+
+			if i >= 0 {
+				if g := Xmpz_ui_kronecker(tls, ulong(i), &b); g != e {
+					t.Fatalf("ui_kronecker(%v, %v): got %v, expected %v", i, j, g, e)
+				}
+			}
+		}
+	}
+
+	for i := 0; i < 2000; i++ {
+		x, y := mustBig(bigRnd(1+rnd.Intn(500))), mustBig(bigRnd(1+rnd.Intn(500)))
+		if rnd.Intn(2) == 0 {
+			x.Neg(x)
+		}
+		if rnd.Intn(2) == 0 {
+			y.Neg(y)
+		}
+		if rnd.Intn(3) == 0 {
+			y.Lsh(y, uint(rnd.Intn(100)))
+		}
+		if rnd.Intn(10) == 0 {
+			x.Mul(x, y)
+		}
+		Xmpz_set_big(tls, &a, x)
+		Xmpz_set_big(tls, &b, y)
+		if g, e := Xmpz_kronecker(tls, &a, &b), int32(kronecker(x, y)); g != e {
+			t.Fatalf("kronecker(%v, %v): got %v, expected %v", x, y, g, e)
+		}
+	}
+}
+
+var (
+	// Strong pseudoprimes to base 2 below 10^6, OEIS A001262.
+	spsp2 = []uint64{
+		2047, 3277, 4033, 4681, 8321, 15841, 29341, 42799, 49141, 52633, 65281,
+		74665, 80581, 85489, 88357, 90751, 104653, 130561, 196093, 220729,
+		233017, 252601, 253241, 256999, 271951, 280601, 314821, 357761, 390937,
+		458989, 476971, 486737, 489997, 514447, 580337, 635401, 647089, 741751,
+		800605, 818201, 838861, 873181, 877099, 916327, 976873, 983401,
+	}
+
+	// Strong Lucas pseudoprimes, Selfridge's method A, below 10^6, OEIS
+	// A217255.
+	slpsp = []uint64{
+		5459, 5777, 10877, 16109, 18971, 22499, 24569, 25199, 40309, 58519,
+		75077, 97439, 100127, 113573, 115639, 130139, 155819, 158399, 161027,
+		162133, 176399, 176471, 189419, 192509, 197801, 224369, 230691, 231703,
+		243629, 253259, 268349, 288919, 313499, 324899, 353219, 366799, 391169,
+		430127, 436409, 455519, 487199, 510479, 572669, 611399, 622169, 635627,
+		636199, 701999, 794611, 835999, 839159, 851927, 871859, 875879, 887879,
+		895439, 950821, 960859,
+	}
+
+	// Composites passing Miller-Rabin for several bases.
+	strongPseudoprimes = []string{
+		"3215031751",                // Bases 2 to 7.
+		"341550071728321",           // Bases 2 to 19.
+		"3825123056546413051",       // Bases 2 to 31.
+		"318665857834031151167461",  // Bases 2 to 37.
+		"3317044064679887385961981", // Bases 2 to 41.
+	}
+
+	// Primes.
+	bigPrimes = []string{
+		"2305843009213693951",                     // 2^61-1
+		"18446744073709551557",                    // The largest prime below 2^64.
+		"170141183460469231731687303715884105727", // 2^127-1
+		"6864797660130609714981900799081393217269435300143305409394463459185543183397656052122559640661454554977296311391480858037121987999716643812574028291115057151", // 2^521-1
+	}
+)
+
+func TestPrime(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var n [1]Xmpz_srcptr
+	Xmpz_init(tls, &n)
+
+	defer Xmpz_clear(tls, &n)
+
+	for _, v := range spsp2 {
+		Xmpz_set_ui(tls, &n, ulong(v))
+		if big.NewInt(0).SetUint64(v).ProbablyPrime(20) {
+			t.Fatalf("%v is prime", v)
+		}
+
+		if v > 31*31 {
+			var nm1, q, y [1]Xmpz_srcptr
+			Xmpz_init(tls, &nm1)
+			Xmpz_init(tls, &q)
+			Xmpz_init_set_ui(tls, &y, 2)
+			Xmpz_sub_ui(tls, &nm1, &n, 1)
+			k := Xmpz_scan1(tls, &nm1, 0)
+			Xmpz_tdiv_q_2exp(tls, &q, &nm1, k)
+			if _gmp_millerrabin(tls, &n, &nm1, &y, &q, k) == 0 {
+				t.Fatalf("%v is not a base 2 strong pseudoprime", v)
+			}
+
+			Xmpz_clear(tls, &nm1)
+			Xmpz_clear(tls, &q)
+			Xmpz_clear(tls, &y)
+		}
+		if g := Xmpz_prime_p(tls, &n, PrimeBPSW, 0); g != 0 {
+			t.Fatalf("%v: %v", v, g)
+		}
+	}
+	for _, v := range slpsp {
+		Xmpz_set_ui(tls, &n, ulong(v))
+		if big.NewInt(0).SetUint64(v).ProbablyPrime(20) {
+			t.Fatalf("%v is prime", v)
+		}
+
+		if !strongLucas(tls, &n) {
+			t.Fatalf("%v is not a strong Lucas pseudoprime", v)
+		}
+
+		if g := Xmpz_prime_p(tls, &n, PrimeBPSW, 0); g != 0 {
+			t.Fatalf("%v: %v", v, g)
+		}
+	}
+	for _, v := range strongPseudoprimes {
+		if mustBig(v).ProbablyPrime(20) {
+			t.Fatalf("%v is prime", v)
+		}
+
+		mpzSetString(tls, &n, v)
+		if g := Xmpz_prime_p(tls, &n, PrimeBPSW, 0); g != 0 {
+			t.Fatalf("%v: %v", v, g)
+		}
+	}
+	for _, v := range bigPrimes {
+		mpzSetString(tls, &n, v)
+		e := int32(1)
+		if len(v) <= 20 {
+			e = 2
+		}
+		for _, reps := range []int32{0, 10} {
+			if g := Xmpz_prime_p(tls, &n, PrimeBPSW, reps); g != e {
+				t.Fatalf("%v: %v", v, g)
+			}
+		}
+		Xmpz_neg(tls, &n, &n)
+		if g := Xmpz_prime_p(tls, &n, PrimeBPSW, 0); g != e {
+			t.Fatalf("-%v: %v", v, g)
+		}
+	}
+
+	// Exhaustive small range and random 64 bit values.
+	check := func(x *big.Int) {
+		t.Helper()
+		Xmpz_set_big(tls, &n, x)
+		e := int32(0)
+		if new(big.Int).Abs(x).ProbablyPrime(0) {
+			e = 2
+		}
+		if g := Xmpz_prime_p(tls, &n, PrimeBPSW, 0); g != e {
+			t.Fatalf("%v: got %v, expected %v", x, g, e)
+		}
+
+		if g, e := Xmpz_prime_p(tls, &n, PrimeMillerRabin, 25), Xmpz_probab_prime_p(tls, &n, 25); g != e {
+			t.Fatalf("%v: got %v, expected %v", x, g, e)
+		}
+	}
+	for i := int64(0); i < 1<<15; i++ {
+		x := big.NewInt(i)
+		check(x)
+		check(x.Neg(x))
+	}
+	for i := 0; i < 5000; i++ {
+		x := mustBig(bigRnd(1 + rnd.Intn(64)))
+		x.SetBit(x, 0, 1)
+		check(x)
+	}
+	// Products of two primes.
+	for i := 0; i < 1000; i++ {
+		p, q := bigRandPrime(2+rnd.Intn(31)), bigRandPrime(2+rnd.Intn(31))
+		check(p.Mul(p, q))
+	}
+}
+
+// bigRandPrime returns a random prime of the given size in bits.
+func bigRandPrime(bits int) *big.Int {
+	for {
+		if p := mustBig(bigRnd(bits)); p.ProbablyPrime(20) {
+			return p
+		}
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, fmt.Errorf("errReader") }
+
+func TestNextPrime(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var n, r [1]Xmpz_srcptr
+	Xmpz_init(tls, &n)
+	Xmpz_init(tls, &r)
+
+	defer Xmpz_clear(tls, &n)
+	defer Xmpz_clear(tls, &r)
+
+	isPrime := func(x *big.Int) bool { return x.Sign() > 0 && x.ProbablyPrime(20) }
+	check := func(x *big.Int) {
+		t.Helper()
+		Xmpz_set_big(tls, &n, x)
+		Xmpz_nextprime(tls, &r, &n)
+		g := Xmpz_get_big(tls, nil, &r)
+		e := new(big.Int).Add(x, big.NewInt(1))
+		if e.Sign() <= 0 {
+			e.SetInt64(2)
+		}
+		for !isPrime(e) {
+			e.Add(e, big.NewInt(1))
+		}
+		if g.Cmp(e) != 0 {
+			t.Fatalf("nextprime(%v): got %v, expected %v", x, g, e)
+		}
+
+		Xmpz_set_si(tls, &r, -1)
+		p := Xmpz_prevprime(tls, &r, &n)
+		g = Xmpz_get_big(tls, nil, &r)
+		e = new(big.Int).Sub(x, big.NewInt(1))
+		for e.Cmp(big.NewInt(2)) >= 0 && !isPrime(e) {
+			e.Sub(e, big.NewInt(1))
+		}
+		switch {
+		case e.Cmp(big.NewInt(2)) < 0:
+			if p != 0 || Xmpz_cmp_si(tls, &r, -1) != 0 {
+				t.Fatalf("prevprime(%v): got %v %v, expected none", x, p, g)
+			}
+		default:
+			ep := int32(2)
+			if e.BitLen() > 64 {
+				ep = 1
+			}
+			if p != ep || g.Cmp(e) != 0 {
+				t.Fatalf("prevprime(%v): got %v %v, expected %v %v", x, p, g, ep, e)
+			}
+		}
+	}
+	for i := int64(-10); i < 2000; i++ {
+		check(big.NewInt(i))
+	}
+	for i := int64(sieveMin - 1000); i < sieveMin+2*sieveSize+1000; i++ {
+		check(big.NewInt(i))
+	}
+	for i := 0; i < 100; i++ {
+		check(mustBig(bigRnd(1 + rnd.Intn(300))))
+	}
+
+	// The maximal prime gap of 1132 after 1693182318746371.
+	check(mustBig("1693182318746371"))
+	check(mustBig("1693182318747503"))
+
+	for _, bits := range []ulong{2, 3, 4, 5, 8, 17, 31, 32, 33, 63, 64, 65, 100, 256, 512} {
+		for i := 0; i < 5; i++ {
+			if err := Xmpz_rand_prime(tls, &r, rnd, bits); err != nil {
+				t.Fatal(err)
+			}
+
+			if g := Xmpz_get_big(tls, nil, &r); g.BitLen() != int(bits) || !isPrime(g) {
+				t.Fatalf("rand_prime(%v): %v", bits, g)
+			}
+
+			if bits < 3 || bits > 128 {
+				continue
+			}
+
+			if err := Xmpz_rand_safe_prime(tls, &r, rnd, bits); err != nil {
+				t.Fatal(err)
+			}
+
+			g := Xmpz_get_big(tls, nil, &r)
+			if q := new(big.Int).Rsh(g, 1); g.BitLen() != int(bits) || !isPrime(g) || !isPrime(q) {
+				t.Fatalf("rand_safe_prime(%v): %v", bits, g)
+			}
+		}
+	}
+
+	Xmpz_set_ui(tls, &r, 42)
+	for _, err := range []error{
+		Xmpz_rand_prime(tls, &r, rnd, 1),
+		Xmpz_rand_prime(tls, &r, errReader{}, 100),
+		Xmpz_rand_safe_prime(tls, &r, rnd, 2),
+		Xmpz_rand_safe_prime(tls, &r, errReader{}, 100),
+	} {
+		if err == nil || Xmpz_cmp_ui(tls, &r, 42) != 0 {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRandState(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var r, n [1]Xmpz_srcptr
+	Xmpz_init(tls, &r)
+	Xmpz_init(tls, &n)
+
+	defer Xmpz_clear(tls, &r)
+	defer Xmpz_clear(tls, &n)
+
+	var s, s2 RandState
+	Xgmp_randinit_default(tls, &s)
+	Xgmp_randinit_default(tls, &s2)
+
+	defer Xgmp_randclear(tls, &s)
+	defer Xgmp_randclear(tls, &s2)
+
+	sequence := func(s *RandState) (a []string) {
+		for i := 0; i < 10; i++ {
+			Xmpz_urandomb(tls, &r, s, 200)
+			a = append(a, mpzString(tls, &r))
+		}
+		return a
+	}
+
+	// Same seed, same sequence.
+	a, b := sequence(&s), sequence(&s2)
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Fatalf("%v %v", a, b)
+	}
+
+	Xgmp_randseed_ui(tls, &s, 42)
+	a = sequence(&s)
+	Xmpz_set_ui(tls, &n, 42)
+	Xgmp_randseed(tls, &s2, &n)
+	if b := sequence(&s2); fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Fatalf("%v %v", a, b)
+	}
+
+	if b := sequence(&s2); fmt.Sprint(a) == fmt.Sprint(b) {
+		t.Fatal(a)
+	}
+
+	for _, bits := range []ulong{0, 1, 2, 31, 32, 33, 63, 64, 65, 100, 1000} {
+		max := 0
+		for i := 0; i < 100; i++ {
+			Xmpz_urandomb(tls, &r, &s, bits)
+			if g := mpzBitLen(&r); g > int(bits) || Xmpz_sgn(tls, &r) < 0 {
+				t.Fatalf("urandomb(%v): %v", bits, mpzString(tls, &r))
+			} else if g > max {
+				max = g
+			}
+
+			Xmpz_rrandomb(tls, &r, &s, bits)
+			if g := mpzBitLen(&r); g != int(bits) {
+				t.Fatalf("rrandomb(%v): %v", bits, mpzString(tls, &r))
+			}
+
+			if g, e := Xgmp_urandomb_ui(tls, &s, bits), uint64(1)<<bits-1; bits < limbBits && uint64(g) > e {
+				t.Fatalf("urandomb_ui(%v): %v", bits, g)
+			}
+		}
+		if bits != 0 && max != int(bits) {
+			t.Fatalf("urandomb(%v): max %v", bits, max)
+		}
+	}
+
+	// Long runs.
+	const bits = 10000
+	runs := 0
+	for i := 0; i < 10; i++ {
+		Xmpz_rrandomb(tls, &r, &s, bits)
+		for j := 1; j < bits; j++ {
+			if mpzBit(&r, j) != mpzBit(&r, j-1) {
+				runs++
+			}
+		}
+	}
+	if runs > bits {
+		t.Fatalf("rrandomb: %v runs", runs)
+	}
+
+	for i := 0; i < 1000; i++ {
+		x := mustBig(bigRnd(1 + rnd.Intn(300)))
+		switch rnd.Intn(4) {
+		case 0:
+			x.SetBit(big.NewInt(0), x.BitLen()-1, 1)
+		case 1:
+			x.Neg(x)
+		}
+		Xmpz_set_big(tls, &n, x)
+		Xmpz_urandomm(tls, &r, &s, &n)
+		if g := Xmpz_get_big(tls, nil, &r); g.Sign() < 0 || g.CmpAbs(x) >= 0 {
+			t.Fatalf("urandomm(%v): %v", x, g)
+		}
+
+		Xmpz_urandomm(tls, &n, &s, &n)
+		if g := Xmpz_get_big(tls, nil, &n); g.Sign() < 0 || g.CmpAbs(x) >= 0 {
+			t.Fatalf("urandomm(%v): %v", x, g)
+		}
+
+		u := ulong(x.Uint64())
+		if u == 0 {
+			u = 1
+		}
+		if g := Xgmp_urandomm_ui(tls, &s, u); g >= u {
+			t.Fatalf("urandomm_ui(%v): %v", u, g)
+		}
+	}
+
+	// Uniformity.
+	var hist, histUI [10]int
+	Xmpz_set_ui(tls, &n, 10)
+	for i := 0; i < 10000; i++ {
+		Xmpz_urandomm(tls, &r, &s, &n)
+		hist[Xmpz_get_ui(tls, &r)]++
+		histUI[Xgmp_urandomm_ui(tls, &s, 10)]++
+	}
+	for i := range hist {
+		if hist[i] < 800 || hist[i] > 1200 || histUI[i] < 800 || histUI[i] > 1200 {
+			t.Fatal(hist, histUI)
+		}
+	}
+
+	// Other sources.
+	Xgmp_randinit_reader(tls, &s2, cryptorand.Reader)
+	Xmpz_urandomb(tls, &r, &s2, 100)
+	Xgmp_randinit_reader(tls, &s2, errReader{})
+	if err := func() (err interface{}) {
+		defer func() { err = recover() }()
+
+		Xmpz_urandomb(tls, &r, &s2, 100)
+		return nil
+	}(); err == nil {
+		t.Fatal(err)
+	}
+}
+
+// gmpRandVectors are the outputs of GMP 6.2.1 on linux/amd64 for the sequence
+// of TestGMPRand. The vectors of the LC generators may depend on the limb size
+// and are checked only for 64 bit limbs.
+var gmpRandVectors = []struct {
+	gen   string
+	seed  string
+	a     string
+	c     uint64
+	m2exp ulong
+	want  string
+}{
+	{"mt", "", "", 0, 0, "0 4b 2902d2f 7647f3c3 1680bbdc8 6580d17dd84a873b 1eff7e89bf767863 3c0d9fcfaa3dc18b 518f204fe6846aeb6f58174d5 4c904dce9a0f53d4a88b3e558ef7612f 1fa212b90c98229bbb79bece734a62215 75f5c732865701ae9349ea8729cde0bbade38204e63359a46e672a8d0a2fd5300692ab48f9e 0 1 0 5 e67781b6 83e49377820195c8 d5c3f27a310115d2 8593f0d764150a6d2e5d3fea7d9d0d33 a186c8ee6196954170eb806 19b 1 2 339 f0001fff0ff00008 1e0007ffc00003f00 ffffffffffffffffffffffffffffffffffffffffffffff8000 0 1 7003fcff d2c92c8 0 1 1 209f4814 1cbe5ac9"},
+	{"mt", "0", "", 0, 0, "1 18 35696741 45558c73 fde90ec7 7b34411325e1217a 15a0d413f32420fc 2fd6dfdbd477a877 d09c764c8f3c413cfd57c8a5d e209a0277038ce55ddae57fbe0cec83f 2d56a81322aa13a270c77f95a51d16e8 4b6cf826d6755c413f33fe7a349851698569993e83a65eb9c589d31d57613b9e0d304af253e 0 1 2 2 c60377e6 38b2a7215fe7610 feb504d4d313692b db8ce72430d9eefe1fc622b02906e975 2247230b2023fda8c4c07921 1ae 1 2 2d9 80000000001fffff 1fffc3ffc03ffc00f fffffffffffffffffff8000000000000000000000000000000 0 1 5fb26798 684116f2 0 0 1 4e02947 3a4cf35"},
+	{"mt", "42", "", 0, 0, "1 19 708dc2c7 7e2e2691 15438a19c 4be543734c7950c9 1238c359b0c34b82 f767496c8e3c6fde 33d8782becf5239ddd7f5730f 81d7f082095c5fc64c48d74fddfd64fd 17966b9b43ec652fde51a422143050aaa 5ea39f6940ca558021234a77c5d5059afd764495b6857200b37461b71181b5f3763de849733 0 1 2 2 e505b5f9 54a4c503dc251c7c 5c480a5d216ade6e 140433ad1e05041270971fbd6f9167d7 234ede3dcccad78cc0aacb21 217 1 2 2c4 ffffffbfffffe000 1ffffffffff800000 ffffffffffffffffffffffffffffffffffffff8fffffffffff 0 1 3bc9f569 776e6a4f 0 1 7 425ba68 71c224fa"},
+	{"mt", "123456789012345678901234567890123456789012345678901234567890", "", 0, 0, "0 48 5ff37139 46d5be4 1ba689e21 21874c74eea4f87a 3b8922c1b8819a88 d653cd8c663510ee 20fab64fc28d84ef1c11e7ce8 1aa237533dd09b0a07beedde3d550f97 12bb3ee20cb2348f87c73c05897f86c44 62487fc0c6743bfd0cf4e9767972aa373c3443d67a07afc52fff83031ce8a19cb61dfb21018 0 0 1 1 d5f899c5 cd65c9308183c53e a83974b31ad24895 a28f487e243a6281e3b6e8948aace8e6 143de87f8b88022f3143728e 4d 1 2 26d ff80007ff8000780 1fffffffff001ffff e000007fffffffffffe000000000003ffffffe000000007fff 0 1 4c0b02c4 314c8828 0 0 5 1c39441 4179473f"},
+	{"mt", "-5", "", 0, 0, "1 8 97d1485 66c89778 1a91a8fae 3f4b3c5913006a58 3af41cf14cd5ee14 a584443745d93037 37aa69610c42ca6291f0a6114 29c19d5c67a9e2174fe3daea2bd0f8f 16ccfb9f1445c6986c231c0aaf0190e5e 9855892b249816e429ada7cac202810e575061d3cd5e11e6f03ef4c7e133323829df73dcb9d 0 1 0 1 56b6493d af3f9350630cc7dc 4e51f7c0529e7657 18e63a91e251c05f7c97525ff845b9d1 94891d9ca453d64a50ec657 32f 1 2 2e7 ffffe0000007ffff 18003ffe00f000fff fffc000000000007fe000003fc000007ffe007fffffe000000 0 1 65a6677a d495ed82 0 1 1 13befeb0 bf09af9"},
+	{"lc", "", "1", 0, 2, "0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 1 2 2aa aaaaaaaaaaaaaaaa 15555555555555555 aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa 0 0 0 0 0 0 0 0 0"},
+	{"lc", "", "1103515245", 12345, 31, "0 7d 446bcf03 d9e2abf6 8ff6fea9 7f78578e637c63bf d8f7b118d2bfc403 db19d423d00fa6e5 756f59ee7992226d53359850e a9d4f34ec59a15b0ac74a53de113acad f3840b1ed745bbccbeabad9bd02ffaaa 1bef99fd7d0d3c9afde1021c8ee1e6d24ffa8fb88f0f32a367d2f858e96ec7c6b071a8cc0731 0 1 0 9 148e0b0d d8bff9a74c6db253 b5a43982d3588963 a59e66b24773bc755ef375a99ddcf013 feb2b2936e624099c06cd84 23c 1 2 3de f000000000180000 1ffffe02003ffffc0 fffff0000000000000000000f000000000000000000000001f 0 0 762eb0fd 9194d6d6 0 0 2 2c0c8e8f 4ba5cd4d"},
+	{"lc", "7", "6364136223846793005", 1, 33, "0 3 416ded29 a2b42f38 164379a3c 42ddb457b21aa488 bf719f67d1e85fde 10941df1a7aada6ba 508f54563a9b33fcf537f19fa 208d3c63467370eaf20ea5edd49fac0f 1edf2fa9a993b9f3523bd3b1a9c6b63e6 ef94974f2993a89eb5332ed1e4196207c25af3f41035827b215df0da1c28e435e3721ea0de8 0 0 0 6 5df4f4c1 67dd0542347123e3 c64646919dd2b752 5cc1b030fa54938a2e65b1c1b7eb7c1b 2486224a58f55c2fb92b7ccd 340 1 2 2cb fe00000001fff000 1f000fffc0ffff3f8 fffffc00000001fffff00000ffff0000000003ffffffffffff 0 0 28d9f890 ce5b59ee 0 0 7 2188d842 3e2b1a49"},
+	{"lc", "12345", "6364136223846793005", 1442695040888963407, 64, "1 6e 62b84105 d5f2e30a 535c8ea8 6438f14dcb3af636 302bd02bd0af9825 1fba9a163fd70522e f552e42187a5b1baad37bf421 7727df45cd158ba618d3e485fe48bde 1a7e5b8ad8ade8c3738c4ac2616e635e9 d9ec360b934e78608600c996e22087799f3f33082c17402f3fb4b0e14fa41b96ab1dab33022 0 0 1 7 4a8fc827 e3ac8802a0254f4 c22299e5eb7d5555 7b3c5add0342921f597820df8f5f6fac e2f82f1532b85a8350acc18 3c7 1 2 27c ffffffffffc00000 1fffe3fec0c7fff80 f00000000007fffffffffffffffc0000000000ffffffffc000 0 0 69a9a4e9 d60bc6a3 0 0 1 2ad607fc c9b76b6f"},
+	{"lc", "12345", "6364136223846793005", 1, 65, "0 a 87355b2 3af2be67 1b82a72f8 23a2f939d7f7c07a 69622f5da540963b 126fa0bbdc7538637 52014d1d8398304332b3688d2 996f37c369c2047b382bc2a7da8b8735 1f319d0f7b306d8d504a8f3dec6c5fc24 121ebe555123077b6f6313f184832287fa9e201c9d9ca3615a3445789fb4055b398b1a0c2ef 0 0 1 9 6ac15467 b8723856a2cdafc 9849d53391b01183 330f1ba5e4b849bf514354f3731a8506 16c1e1772b293aa721bdd04d 1ea 1 2 233 fff03f80007f003c 1fffc3feff8003fff ffffff8000000000000000000000000000007fffffffffffff 0 1 6ccae534 cf5b18a6 0 0 9 169d9124 c96c0c66"},
+	{"lc", "99999999999999999999", "3208233125391488787", 3, 100, "0 70 49a9dbb9 93ce1405 6a143136 574cba1c949adad 2ec3ff4aca15860f 14ac7543d665e65e 4206b1f6e0783949c0e89e87 740b798c459e21ec9ee62447ee072183 18d349ca068aadf69223882b9f1c7284e ce835232ffa81b989d8741bc6b9e00f44b98dc98b49b5335202569ed39fe56ca1b27a5c3a78 0 1 1 5 fc7dd138 b5f56d891f0bccb4 1891b481d819ef77 f17add6a1213cbc48fc8f3e63cf09ecb 14a18f2787334eea6f94c6fb a8 1 2 24d 80ffff0000300080 1c03ff0007ffffc7f fffffffffffff000000000000ffffffffffffff8000000ffff 0 1 2aaa75a6 5e082303 0 1 6 1a7407a4 e80f4985"},
+	{"lc", "12345", "47026247687942121848144207491837523525", 1, 128, "0 10 65039928 51e36461 cd49f63e 260fe8eeffbe7df3 1e5f735c17e0d681 1ae8813a1ecc1989f ec1266b5f1859381572298050 389ab3d566e7a784edc438031ea74efc 185746928240b0eb7d7b8f5f8c645d5b8 49a2051b89100e7f99d6addafe653ec5f9f87888b52be1e9de7311f7048288b6d94a7166d1c 0 1 2 2 c7929b58 e6f74426d6262d99 1d3e165a25b416ea 6e664f09cba307d3ef2b6695c6e0b0bd 30426d14bfda9e60929f66b c2 1 2 31c fe38001ffe003fff 1ffffe03fffffe000 ff007fffffffffff81ffffffffc0000000000000001fffffff 0 0 30b78bea 1ad5d582 0 1 8 90b1153 df2f733a"},
+	{"lc", "-12345", "123456789123456789123456789123456789123456789", 17, 200, "0 41 20104562 ae1ca809 113dd72e8 59fd28a3fa21b135 15f1a3443d14fca4 aca16defa06553fe fffeafd5f74748b223f4d95e 27eb01cdcf137f7f54bb58d4b1c448ca 5ef6b252a84b6dc5e4dc11edac357f6d 9ac0d8580e0621682573ee45f73a0552754097b271dd2aa5b43c0879a9e26ff8a2f534cb4d5 0 0 2 3 39e5f5b5 f3b3bcff275816e0 8fb419f50249a770 15c2561549fb6475492a71ad931890bc 1c393638785ee560bfba9669 1 1 2 380 f8000403fff0f800 1fffffffffffffffc ffffffc0000007ffffffffffffe000000000000001fffffffc 0 1 139af4fe fb69c071 0 1 0 2a32115f 130bef1c"},
+	{"lc", "", "-7", 5, 128, "1 0 7fffffff 0 1ffffffff 0 ffffffffffffffff 10000000000000000 fffffffff0000000000000000 ffffffffffffffff0000000000000000 ffffffffffffffff0000000000000000 fffffffffff0000000000000000ffffffffffffffff0000000000000000ffffffffffffffff 0 0 0 8 2486 ffffffffffff004f 15695df fffffffe34fd321e000000004192afb2 247697e30000000c8d13a128 c7 1 2 324 fc000003ffffff00 1fffffffff8000000 ffffc000000000000000000000000000000000000000003fff 0 1 5054738b 4db0d730 0 1 0 280fad5d a7924273"},
+}
+
+func TestGMPRand(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var r, n [1]Xmpz_srcptr
+	Xmpz_init(tls, &r)
+	Xmpz_init(tls, &n)
+
+	defer Xmpz_clear(tls, &r)
+	defer Xmpz_clear(tls, &n)
+
+	hex := func(x *[1]Xmpz_srcptr) string { return Xmpz_get_big(tls, nil, x).Text(16) }
+	for i, v := range gmpRandVectors {
+		var s RandState
+		switch v.gen {
+		case "mt":
+			Xgmp_randinit_mt(tls, &s)
+		case "lc":
+			if limbBits != 64 {
+				continue
+			}
+
+			Xmpz_set_big(tls, &n, mustBig(v.a))
+			Xgmp_randinit_lc_2exp(tls, &s, &n, ulong(v.c), v.m2exp)
+		}
+		if v.seed != "" {
+			Xmpz_set_big(tls, &n, mustBig(v.seed))
+			Xgmp_randseed(tls, &s, &n)
+		}
+
+		var a []string
+		for _, bits := range []ulong{1, 7, 31, 32, 33, 63, 64, 65, 100, 128, 129, 300} {
+			Xmpz_urandomb(tls, &r, &s, bits)
+			a = append(a, hex(&r))
+		}
+		for _, m := range []string{
+			"1", "2", "3", "10", "4294967296", "18446744073709551616", "18446744073709551617",
+			"340282366920938463463374607431768211456", "12345678901234567890123456789", "-1000",
+		} {
+			Xmpz_set_big(tls, &n, mustBig(m))
+			Xmpz_urandomm(tls, &r, &s, &n)
+			a = append(a, hex(&r))
+		}
+		for _, bits := range []ulong{1, 2, 10, 64, 65, 200} {
+			Xmpz_rrandomb(tls, &r, &s, bits)
+			a = append(a, hex(&r))
+		}
+		for _, bits := range []ulong{0, 1, 31, 32} {
+			a = append(a, fmt.Sprintf("%x", Xgmp_urandomb_ui(tls, &s, bits)))
+		}
+		for _, m := range []ulong{1, 2, 10, 1000000007, 4294967295} {
+			a = append(a, fmt.Sprintf("%x", Xgmp_urandomm_ui(tls, &s, m)))
+		}
+		Xgmp_randclear(tls, &s)
+		if g, e := strings.Join(a, " "), v.want; g != e {
+			t.Errorf("#%v %v seed %q a %v c %v m2exp %v\ngot  %v\nwant %v", i, v.gen, v.seed, v.a, v.c, v.m2exp, g, e)
+		}
+	}
+}
+
+func TestFactor(t *testing.T) {
+	bg := context.Background()
+	check := func(n *big.Int, want map[string]int) {
+		t.Helper()
+		g, err := Factor(bg, n)
+		if err != nil {
+			t.Fatalf("%v: %v", n, err)
+		}
+
+		if len(g) != len(want) {
+			t.Fatalf("%v: got %v, expected %v", n, g, want)
+		}
+
+		for i, v := range g {
+			if i != 0 && g[i-1].P.Cmp(v.P) >= 0 {
+				t.Fatalf("%v: not ordered %v", n, g)
+			}
+
+			if want[v.P.String()] != v.K {
+				t.Fatalf("%v: got %v, expected %v", n, g, want)
+			}
+		}
+	}
+
+	check(big.NewInt(1), nil)
+	check(big.NewInt(-1), nil)
+	check(big.NewInt(2), map[string]int{"2": 1})
+	check(big.NewInt(-360), map[string]int{"2": 3, "3": 2, "5": 1})
+	check(big.NewInt(2047*2047), map[string]int{"23": 2, "89": 2})
+	check(big.NewInt(4194301), map[string]int{"4194301": 1})
+	check(big.NewInt(2053*2053), map[string]int{"2053": 2})
+	check(new(big.Int).Lsh(big.NewInt(1), 100), map[string]int{"2": 100})
+	check(mustBig("18446744073709551617"), map[string]int{"274177": 1, "67280421310721": 1})
+	p := bigRandPrime(100)
+	check(new(big.Int).Exp(p, big.NewInt(6), nil), map[string]int{p.String(): 6})
+
+	// Products of primes small enough for Pollard's rho and at most one big
+	// prime.
+	for i := 0; i < 20; i++ {
+		n := big.NewInt(1)
+		want := map[string]int{}
+		for j := rnd.Intn(5); j >= 0; j-- {
+			p := bigRandPrime([]int{2, 8, 12, 20, 24, 28}[rnd.Intn(6)])
+			k := 1 + rnd.Intn(3)
+			n.Mul(n, new(big.Int).Exp(p, big.NewInt(int64(k)), nil))
+			want[p.String()] += k
+		}
+		if rnd.Intn(2) == 0 {
+			p := bigRandPrime(200)
+			n.Mul(n, p)
+			want[p.String()]++
+		}
+		if rnd.Intn(2) == 0 {
+			n.Neg(n)
+		}
+		check(n, want)
+	}
+
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var d, m [1]Xmpz_srcptr
+	Xmpz_init(tls, &d)
+	Xmpz_init(tls, &m)
+
+	defer Xmpz_clear(tls, &d)
+	defer Xmpz_clear(tls, &m)
+
+	f := newFactorizer(tls, bg)
+
+	defer f.close()
+
+	// p-1 finds p if p-1 is smooth.
+	for {
+		p = big.NewInt(2)
+		for p.BitLen() < 60 {
+			p.Mul(p, big.NewInt(int64(f.primesTo(pm1B1)[rnd.Intn(1000)])))
+		}
+		if p.Add(p, big.NewInt(1)); p.ProbablyPrime(20) {
+			break
+		}
+	}
+	q := bigRandPrime(100)
+	Xmpz_set_big(tls, &m, new(big.Int).Mul(p, q))
+	if !f.pm1(&d, &m, pm1B1) || Xmpz_get_big(tls, nil, &d).Cmp(p) != 0 {
+		t.Fatalf("pm1 %v*%v: %v", p, q, mpzString(tls, &d))
+	}
+
+	// ECM finds factors too big for Pollard's rho. Whether a curve finds
+	// the factor depends on its random parameter, a batch of curves misses
+	// it with a probability of about 20%. Stage 1 alone would miss it in
+	// about 75% of the batches.
+	p = bigRandPrime(45)
+	Xmpz_set_big(tls, &m, new(big.Int).Mul(p, q))
+	for i := 0; !f.ecm(&d, &m, 2000, 25); i++ {
+		if i == 10 {
+			t.Fatalf("ecm %v*%v: not found", p, q)
+		}
+	}
+	if Xmpz_get_big(tls, nil, &d).Cmp(p) != 0 {
+		t.Fatalf("ecm %v*%v: %v", p, q, mpzString(tls, &d))
+	}
+
+	if _, err := Factor(bg, big.NewInt(0)); err == nil {
+		t.Fatal("factored zero")
+	}
+
+	n := new(big.Int).Mul(bigRandPrime(128), bigRandPrime(128))
+	canceled, cancel := context.WithCancel(bg)
+	cancel()
+	if _, err := Factor(canceled, n); err != context.Canceled {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(bg, 100*time.Millisecond)
+
+	defer cancel()
+
+	if _, err := Factor(ctx, n); err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+}
+
+func TestSqrtMod(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var a, p, r [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&a, &p, &r} {
+		Xmpz_init(tls, v)
+
+		defer Xmpz_clear(tls, v)
+	}
+
+	// Primes of all classes modulo 8 and primes p = 1 (mod 2^s) for a big
+	// s, taking the Cipolla path.
+	var primes []*big.Int
+	for _, v := range []int64{2, 3, 5, 7, 11, 13, 17, 41, 73, 97, 113, 257, 65537} {
+		primes = append(primes, big.NewInt(v))
+	}
+	for i := 0; i < 100; i++ {
+		primes = append(primes, bigRandPrime(2+rnd.Intn(200)))
+	}
+	for len(primes) < 120 {
+		s := uint(60 + rnd.Intn(60))
+		p := new(big.Int).Lsh(mustBig(bigRnd(40)), s)
+		if p.Add(p, big.NewInt(1)); p.ProbablyPrime(20) {
+			primes = append(primes, p)
+		}
+	}
+	for _, pb := range primes {
+		Xmpz_set_big(tls, &p, pb)
+		for i := 0; i < 20; i++ {
+			ab := mustBig(bigRnd(1 + rnd.Intn(300)))
+			if rnd.Intn(2) == 0 {
+				ab.Neg(ab)
+			}
+			Xmpz_set_big(tls, &a, ab)
+			Xmpz_set_ui(tls, &r, 42)
+			e := new(big.Int).Mod(ab, pb)
+			if pb.Bit(0) != 0 {
+				e = e.ModSqrt(e, pb)
+			}
+			g := Xmpz_sqrtmod(tls, &r, &a, &p)
+			switch {
+			case e == nil:
+				if g != 0 || Xmpz_cmp_ui(tls, &r, 42) != 0 {
+					t.Fatalf("sqrtmod(%v, %v): %v %v", ab, pb, g, mpzString(tls, &r))
+				}
+			default:
+				if f := new(big.Int).Sub(pb, e); f.Cmp(e) < 0 {
+					e = f
+				}
+				if g == 0 || Xmpz_get_big(tls, nil, &r).Cmp(e) != 0 {
+					t.Fatalf("sqrtmod(%v, %v): %v %v, expected %v", ab, pb, g, mpzString(tls, &r), e)
+				}
+			}
+		}
+	}
+
+	// Composite moduli.
+	for _, v := range []long{4, 9, 15, 49, 91} {
+		Xmpz_set_si(tls, &p, v)
+		Xmpz_set_ui(tls, &a, 2)
+		if g := Xmpz_sqrtmod(tls, &r, &a, &p); g != 0 && Xmpz_sqrtmod_pow_ui(tls, &r, &a, &p, 1) != 0 {
+			t.Fatalf("sqrtmod(2, %v): %v", v, mpzString(tls, &r))
+		}
+	}
+
+	isSqrt := func(r, a, n *big.Int) bool {
+		return r.Sign() >= 0 && r.Cmp(n) < 0 && new(big.Int).Mod(new(big.Int).Sub(new(big.Int).Mul(r, r), a), n).Sign() == 0
+	}
+	squares := func(n int64) map[int64]bool {
+		m := map[int64]bool{}
+		for x := int64(0); x < n; x++ {
+			m[x*x%n] = true
+		}
+		return m
+	}
+
+	// Prime powers, exhaustively for small ones.
+	for _, v := range []int64{2, 3, 5, 7, 13, 17} {
+		pb := big.NewInt(v)
+		Xmpz_set_big(tls, &p, pb)
+		for k := ulong(0); k < 5; k++ {
+			n := new(big.Int).Exp(pb, big.NewInt(int64(k)), nil)
+			if n.BitLen() > 12 {
+				break
+			}
+
+			sq := squares(n.Int64())
+			for x := int64(-3); x < n.Int64(); x++ {
+				ab := big.NewInt(x)
+				Xmpz_set_big(tls, &a, ab)
+				g := Xmpz_sqrtmod_pow_ui(tls, &r, &a, &p, k)
+				if e := sq[(x%n.Int64()+n.Int64())%n.Int64()]; e != (g != 0) || e && !isSqrt(Xmpz_get_big(tls, nil, &r), ab, n) {
+					t.Fatalf("sqrtmod_pow_ui(%v, %v, %v): %v %v, expected %v", ab, pb, k, g, mpzString(tls, &r), e)
+				}
+			}
+		}
+	}
+	for i := 0; i < 200; i++ {
+		pb := primes[rnd.Intn(len(primes))]
+		if pb.BitLen() > 100 {
+			continue
+		}
+
+		k := ulong(1 + rnd.Intn(10))
+		n := new(big.Int).Exp(pb, big.NewInt(int64(k)), nil)
+		x := mustBig(bigRnd(1 + rnd.Intn(n.BitLen())))
+		if rnd.Intn(4) == 0 {
+			x.Mul(x, new(big.Int).Exp(pb, big.NewInt(int64(rnd.Intn(int(k)+1))), nil))
+		}
+		ab := new(big.Int).Mul(x, x)
+		ab.Mod(ab, n)
+		Xmpz_set_big(tls, &p, pb)
+		Xmpz_set_big(tls, &a, ab)
+		if g := Xmpz_sqrtmod_pow_ui(tls, &r, &a, &p, k); g == 0 || !isSqrt(Xmpz_get_big(tls, nil, &r), ab, n) {
+			t.Fatalf("sqrtmod_pow_ui(%v, %v, %v): %v %v", ab, pb, k, g, mpzString(tls, &r))
+		}
+	}
+
+	// Factored moduli.
+	for i := 0; i < 200; i++ {
+		var f []PrimeFactor
+		n := big.NewInt(1)
+		seen := map[string]bool{}
+		for j := rnd.Intn(4); j >= 0; j-- {
+			pb := primes[rnd.Intn(len(primes))]
+			if seen[pb.String()] || pb.BitLen() > 100 {
+				continue
+			}
+
+			seen[pb.String()] = true
+			k := 1 + rnd.Intn(3)
+			f = append(f, PrimeFactor{pb, k})
+			n.Mul(n, new(big.Int).Exp(pb, big.NewInt(int64(k)), nil))
+		}
+		x := mustBig(bigRnd(1 + rnd.Intn(n.BitLen()+1)))
+		ab := new(big.Int).Mul(x, x)
+		Xmpz_set_big(tls, &a, ab)
+		if g := Xmpz_sqrtmod_factors(tls, &r, &a, f); g == 0 || !isSqrt(Xmpz_get_big(tls, nil, &r), ab, n) {
+			t.Fatalf("sqrtmod_factors(%v, %v): %v %v", ab, f, g, mpzString(tls, &r))
+		}
+
+		if n.BitLen() > 12 {
+			continue
+		}
+
+		sq := squares(n.Int64())
+		for x := int64(0); x < n.Int64(); x++ {
+			ab := big.NewInt(x)
+			Xmpz_set_big(tls, &a, ab)
+			g := Xmpz_sqrtmod_factors(tls, &r, &a, f)
+			if e := sq[x]; e != (g != 0) || e && !isSqrt(Xmpz_get_big(tls, nil, &r), ab, n) {
+				t.Fatalf("sqrtmod_factors(%v, %v): %v %v, expected %v", ab, f, g, mpzString(tls, &r), e)
+			}
+		}
+	}
+}
+
+func TestCRT(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var r, m [1]Xmpz_srcptr
+	Xmpz_init(tls, &r)
+	Xmpz_init(tls, &m)
+
+	defer Xmpz_clear(tls, &r)
+	defer Xmpz_clear(tls, &m)
+
+	system := func(rs, ms []*big.Int) []Congruence {
+		c := make([]Congruence, len(rs))
+		for i := range c {
+			c[i] = Congruence{FromBig(rs[i]), FromBig(ms[i])}
+		}
+		return c
+	}
+	free := func(c []Congruence) {
+		for _, v := range c {
+			Mpz_clear(v.R)
+			Mpz_clear(v.M)
+		}
+	}
+	solve := func(rs, ms []*big.Int) (*big.Int, *big.Int, bool) {
+		c := system(rs, ms)
+
+		defer free(c)
+
+		Xmpz_set_ui(tls, &r, 42)
+		Xmpz_set_ui(tls, &m, 42)
+		if Xmpz_crt(tls, &r, &m, c) == 0 {
+			if Xmpz_cmp_ui(tls, &r, 42) != 0 || Xmpz_cmp_ui(tls, &m, 42) != 0 {
+				t.Fatal("modified")
+			}
+
+			return nil, nil, false
+		}
+
+		return Xmpz_get_big(tls, nil, &r), Xmpz_get_big(tls, nil, &m), true
+	}
+
+	if x, l, ok := solve(nil, nil); !ok || x.Sign() != 0 || l.Cmp(big.NewInt(1)) != 0 {
+		t.Fatal(x, l, ok)
+	}
+
+	b := func(a ...int64) (r []*big.Int) {
+		for _, v := range a {
+			r = append(r, big.NewInt(v))
+		}
+		return r
+	}
+	for _, v := range []struct {
+		r, m []*big.Int
+		x, l int64 // l = 0: no solution.
+	}{
+		{b(2, 3, 2), b(3, 5, 7), 23, 105},
+		{b(-1), b(-7), 6, 7},
+		{b(1, 2), b(6, 4), 0, 0},
+		{b(1, 3), b(6, 4), 7, 12},
+		{b(5, 5, 5), b(10, 10, 10), 5, 10},
+		{b(0, 1), b(1, 1), 0, 1},
+		{b(3, 4, 5, 6), b(4, 6, 10, 15), 0, 0},
+		{b(7, 1, 1, 11), b(12, 10, 15, 16), 91, 240},
+		{b(11, 5, 1, 11), b(12, 10, 15, 16), 0, 0},
+	} {
+		x, l, ok := solve(v.r, v.m)
+		switch {
+		case v.l == 0:
+			if ok {
+				t.Fatalf("%v %v: %v %v", v.r, v.m, x, l)
+			}
+		case !ok || x.Int64() != v.x || l.Int64() != v.l:
+			t.Fatalf("%v %v: %v %v %v, expected %v %v", v.r, v.m, x, l, ok, v.x, v.l)
+		}
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("no panic")
+			}
+		}()
+
+		solve(b(1), b(0))
+	}()
+
+	// Random systems with a known solution, coprime moduli or not.
+	for _, n := range []int{2, 3, 10, 100, 2000} {
+		for _, coprime := range []bool{true, false} {
+			var rs, ms []*big.Int
+			x := mustBig(bigRnd(20 * n))
+			l := big.NewInt(1)
+			for i := 0; i < n; i++ {
+				var q *big.Int
+				switch {
+				case coprime:
+					q = bigRandPrime(2 + rnd.Intn(30))
+					if new(big.Int).GCD(nil, nil, q, l).Cmp(big.NewInt(1)) != 0 {
+						i--
+						continue
+					}
+				default:
+					q = mustBig(bigRnd(1 + rnd.Intn(30)))
+					if q.Sign() == 0 {
+						q.SetInt64(1)
+					}
+				}
+				ms = append(ms, q)
+				rs = append(rs, new(big.Int).Mod(x, q))
+				l.Mul(l, new(big.Int).Div(q, new(big.Int).GCD(nil, nil, l, q)))
+			}
+			g, gl, ok := solve(rs, ms)
+			if !ok || gl.Cmp(l) != 0 || g.Cmp(new(big.Int).Mod(x, l)) != 0 {
+				t.Fatalf("%v %v: %v %v %v", n, coprime, ok, g, gl)
+			}
+
+			// Change a residue modulo a modulus sharing a factor with
+			// the product of the others.
+			if coprime || n < 3 {
+				continue
+			}
+
+			for i, q := range ms {
+				o := big.NewInt(1)
+				for j, q := range ms {
+					if j != i {
+						o.Mul(o, q)
+					}
+				}
+				if g := new(big.Int).GCD(nil, nil, o, q); g.Cmp(big.NewInt(1)) != 0 {
+					rs[i].Add(rs[i], big.NewInt(1))
+					rs[i].Mod(rs[i], q)
+					if g, gl, ok := solve(rs, ms); ok {
+						t.Fatalf("%v: %v %v", n, g, gl)
+					}
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestProductTree(t *testing.T) {
+	defer SetTreeParallelism(SetTreeParallelism(1))
+
+	values := func(a []*big.Int) (r []*[1]Xmpz_srcptr) {
+		for _, v := range a {
+			r = append(r, FromBig(v))
+		}
+		return r
+	}
+	zeros := func(n int) []*[1]Xmpz_srcptr {
+		r := make([]*[1]Xmpz_srcptr, n)
+		for i := range r {
+			r[i] = new([1]Xmpz_srcptr)
+			Mpz_init(r[i])
+		}
+		return r
+	}
+	free := func(a []*[1]Xmpz_srcptr) {
+		for _, v := range a {
+			Mpz_clear(v)
+		}
+	}
+	for _, par := range []int{1, 4} {
+		SetTreeParallelism(par)
+		for _, n := range []int{1, 2, 3, 5, 8, 13, 100, 1000} {
+			var xs []*big.Int
+			prod := big.NewInt(1)
+			for i := 0; i < n; i++ {
+				x := mustBig(bigRnd(1 + rnd.Intn(200)))
+				if x.Sign() == 0 {
+					x.SetInt64(1)
+				}
+				if rnd.Intn(4) == 0 {
+					x.Neg(x)
+				}
+				xs = append(xs, x)
+				prod.Mul(prod, x)
+			}
+			x := values(xs)
+			tr := NewProductTree(x)
+			free(x)
+			if g := ToBig(tr.Root()); g.Cmp(prod) != 0 {
+				t.Fatalf("%v: root %v, expected %v", n, g, prod)
+			}
+
+			for i, l := range tr.Levels {
+				if i == 0 {
+					continue
+				}
+
+				for j := range l {
+					e := ToBig(&tr.Levels[i-1][2*j])
+					if 2*j+1 < len(tr.Levels[i-1]) {
+						e.Mul(e, ToBig(&tr.Levels[i-1][2*j+1]))
+					}
+					if g := ToBig(&l[j]); g.Cmp(e) != 0 {
+						t.Fatalf("%v: level %v node %v: %v, expected %v", n, i, j, g, e)
+					}
+				}
+			}
+
+			for i := 0; i < 5; i++ {
+				m := mustBig(bigRnd(1 + rnd.Intn(2*prod.BitLen())))
+				if i%2 == 0 {
+					m.Neg(m)
+				}
+				mv := FromBig(m)
+				r := zeros(n)
+				r2 := zeros(n)
+				for j := range r {
+					Mpz_set_ui(r2[j], 42)
+				}
+				tr.Remainders(r, mv)
+				tr.ScaledRemainders(r2, mv)
+				for j, x := range xs {
+					e := new(big.Int).Mod(m, new(big.Int).Abs(x))
+					if g := ToBig(r[j]); g.Cmp(e) != 0 {
+						t.Fatalf("%v: %v mod %v: %v, expected %v", n, m, x, g, e)
+					}
+
+					if g := ToBig(r2[j]); g.Cmp(e) != 0 {
+						t.Fatalf("%v: scaled %v mod %v: %v, expected %v", n, m, x, g, e)
+					}
+				}
+				free(r)
+				free(r2)
+				Mpz_clear(mv)
+			}
+			tr.Clear()
+		}
+
+		// Moduli with shared primes.
+		var primes, xs []*big.Int
+		for i := 0; i < 50; i++ {
+			primes = append(primes, bigRandPrime(64))
+		}
+		for i := 0; i < 100; i++ {
+			xs = append(xs, new(big.Int).Mul(primes[rnd.Intn(len(primes))], primes[rnd.Intn(len(primes))]))
+		}
+		x := values(xs)
+		BatchGCD(x, x)
+		for i, v := range xs {
+			o := big.NewInt(1)
+			for j, v := range xs {
+				if j != i {
+					o.Mul(o, v)
+				}
+			}
+			if g, e := ToBig(x[i]), new(big.Int).GCD(nil, nil, v, o); g.Cmp(e) != 0 {
+				t.Fatalf("gcd(%v, ...): %v, expected %v", v, g, e)
+			}
+		}
+		free(x)
+	}
+}
+
+func TestProductTreePanic(t *testing.T) {
+	defer SetTreeParallelism(SetTreeParallelism(1))
+
+	var x []*[1]Xmpz_srcptr
+	for i := 0; i < 100; i++ {
+		x = append(x, FromBig(mustBig(bigRnd(200))))
+	}
+
+	defer func() {
+		for _, v := range x {
+			Mpz_clear(v)
+		}
+	}()
+
+	allocFunc, reallocFunc, freeFunc := defaultContext.MemoryFunctions()
+
+	defer defaultContext.SetMemoryFunctions(allocFunc, reallocFunc, freeFunc)
+
+	var mu sync.Mutex
+	blocks := map[unsafe.Pointer]struct{}{}
+	defaultContext.SetMemoryFunctions(
+		func(tls *crt.TLS, size sizeT) unsafe.Pointer {
+			p := allocFunc(tls, size)
+			mu.Lock()
+			blocks[p] = struct{}{}
+			mu.Unlock()
+			return p
+		},
+		func(tls *crt.TLS, old unsafe.Pointer, oldSize, newSize sizeT) unsafe.Pointer {
+			p := reallocFunc(tls, old, oldSize, newSize)
+			mu.Lock()
+			delete(blocks, old)
+			blocks[p] = struct{}{}
+			mu.Unlock()
+			return p
+		},
+		func(tls *crt.TLS, p unsafe.Pointer, size sizeT) {
+			mu.Lock()
+			delete(blocks, p)
+			mu.Unlock()
+			freeFunc(tls, p, size)
+		},
+	)
+
+	defer SetMaxBits(SetMaxBits(0))
+
+	for _, par := range []int{1, 4} {
+		SetTreeParallelism(par)
+		SetMaxBits(1000)
+		if err := Try(func() { NewProductTree(x).Clear() }); err == nil {
+			t.Fatalf("%v: unexpected success", par)
+		}
+
+		if n := len(blocks); n != 0 {
+			t.Fatalf("%v: %v blocks leaked", par, n)
+		}
+
+		SetMaxBits(0)
+		tr := NewProductTree(x)
+		n := len(blocks)
+		// node panics at level, leaf at the level of the root.
+		for level := range tr.Levels {
+			func() {
+				defer func() {
+					if e := recover(); e != level {
+						t.Fatalf("%v %v: %v", par, level, e)
+					}
+				}()
+
+				tr.down(
+					func(tls *crt.TLS, v *[1]Xmpz_srcptr) { Xmpz_set(tls, v, &tr.Levels[len(tr.Levels)-1][0]) },
+					func(tls *crt.TLS, v, parent *[1]Xmpz_srcptr, l, i int) {
+						if l == level && i == len(tr.Levels[l])/2 {
+							panic(level)
+						}
+
+						Xmpz_set(tls, v, parent)
+					},
+					func(tls *crt.TLS, v *[1]Xmpz_srcptr, i int) {
+						if level == len(tr.Levels)-1 && i == len(x)/2 {
+							panic(level)
+						}
+					},
+				)
+			}()
+			if g := len(blocks); g != n {
+				t.Fatalf("%v %v: %v blocks leaked", par, level, g-n)
+			}
+		}
+		tr.Clear()
+	}
+}
+
+func TestInvertBatch(t *testing.T) {
+	tls := crt.NewTLS()
+
+	defer tls.Close()
+
+	var m, g [1]Xmpz_srcptr
+	Xmpz_init(tls, &m)
+	Xmpz_init(tls, &g)
+
+	defer Xmpz_clear(tls, &m)
+	defer Xmpz_clear(tls, &g)
+
+	values := func(n int) []*[1]Xmpz_srcptr {
+		r := make([]*[1]Xmpz_srcptr, n)
+		for i := range r {
+			r[i] = new([1]Xmpz_srcptr)
+			Xmpz_init(tls, r[i])
+		}
+		return r
+	}
+	free := func(a []*[1]Xmpz_srcptr) {
+		for _, v := range a {
+			Xmpz_clear(tls, v)
+		}
+	}
+
+	if g := Xmpz_invert_batch(tls, nil, nil, &m, nil); g != -1 {
+		t.Fatal(g)
+	}
+
+	for i := 0; i < 200; i++ {
+		mb := mustBig(bigRnd(2 + rnd.Intn(300)))
+		if mb.Cmp(big.NewInt(2)) < 0 {
+			continue
+		}
+
+		if i%2 == 0 {
+			mb = bigRandPrime(2 + rnd.Intn(300))
+		}
+		if i%3 == 0 {
+			mb.Neg(mb)
+		}
+		Xmpz_set_big(tls, &m, mb)
+		n := 1 + rnd.Intn(50)
+		ab := make([]*big.Int, n)
+		a := values(n)
+		bad := -1
+		for j := range ab {
+			for {
+				ab[j] = mustBig(bigRnd(1 + rnd.Intn(400)))
+				if rnd.Intn(2) == 0 {
+					ab[j].Neg(ab[j])
+				}
+				if new(big.Int).GCD(nil, nil, ab[j], mb).Cmp(big.NewInt(1)) == 0 {
+					break
+				}
+
+				// Allow some values sharing a factor with m.
+				if rnd.Intn(10) == 0 {
+					if bad < 0 {
+						bad = j
+					}
+					break
+				}
+			}
+			Xmpz_set_big(tls, a[j], ab[j])
+		}
+		r := a
+		if i%4 != 0 {
+			r = values(n)
+			for _, v := range r {
+				Xmpz_set_ui(tls, v, 42)
+			}
+		}
+		Xmpz_set_ui(tls, &g, 42)
+		k := Xmpz_invert_batch(tls, r, a, &m, &g)
+		if k != bad {
+			t.Fatalf("%v: got %v, expected %v", i, k, bad)
+		}
+
+		if bad >= 0 {
+			if e := new(big.Int).GCD(nil, nil, new(big.Int).Abs(ab[bad]), new(big.Int).Abs(mb)); Xmpz_get_big(tls, nil, &g).Cmp(e) != 0 {
+				t.Fatalf("%v: gcd %v, expected %v", i, mpzString(tls, &g), e)
+			}
+
+			for j, v := range r {
+				if &r[0] == &a[0] {
+					if Xmpz_get_big(tls, nil, v).Cmp(ab[j]) != 0 {
+						t.Fatalf("%v: modified", i)
+					}
+				} else if Xmpz_cmp_ui(tls, v, 42) != 0 {
+					t.Fatalf("%v: modified", i)
+				}
+			}
+		} else {
+			am := new(big.Int).Abs(mb)
+			for j, v := range r {
+				if e := new(big.Int).ModInverse(new(big.Int).Mod(ab[j], am), am); Xmpz_get_big(tls, nil, v).Cmp(e) != 0 {
+					t.Fatalf("%v: 1/%v mod %v: %v, expected %v", i, ab[j], mb, mpzString(tls, v), e)
+				}
+			}
+		}
+		if &r[0] != &a[0] {
+			free(r)
+		}
+		free(a)
+	}
+}
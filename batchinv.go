@@ -0,0 +1,74 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"github.com/cznic/ccgo/crt"
+)
+
+// Xmpz_invert_batch sets r[i] to the inverse of a[i] modulo m, in [0, |m|), for
+// all i and returns -1. It uses Montgomery's trick, a single Xmpz_invert and
+// 3(n-1) multiplications for n values, instead of n inversions.
+//
+// If some a[i] has no inverse, r is not modified and the result is the least
+// such i. In that case g, if not nil, is set to gcd(a[i], m), which for
+// methods like ECM is a factor of m unless a[i] is a multiple of m.
+//
+// len(r) must be len(a), r may be a.
+func Xmpz_invert_batch(tls *crt.TLS, r, a []*[1]Xmpz_srcptr, m, g *[1]Xmpz_srcptr) int {
+	if len(r) != len(a) {
+		panic("mpz_invert_batch: invalid number of results")
+	}
+
+	n := len(a)
+	if n == 0 {
+		return -1
+	}
+
+	// c[i] = a[0]*...*a[i] mod m.
+	c := make([][1]Xmpz_srcptr, n)
+	var inv, t [1]Xmpz_srcptr
+	Xmpz_init(tls, &inv)
+	Xmpz_init(tls, &t)
+
+	defer func() {
+		for i := range c {
+			Xmpz_clear(tls, &c[i])
+		}
+		Xmpz_clear(tls, &inv)
+		Xmpz_clear(tls, &t)
+	}()
+
+	Xmpz_init(tls, &c[0])
+	Xmpz_mod(tls, &c[0], a[0], m)
+	for i := 1; i < n; i++ {
+		Xmpz_init(tls, &c[i])
+		Xmpz_mul(tls, &c[i], &c[i-1], a[i])
+		Xmpz_mod(tls, &c[i], &c[i], m)
+	}
+	if Xmpz_invert(tls, &inv, &c[n-1], m) == 0 {
+		// The product is not invertible iff one of the values is not.
+		for i, v := range a {
+			if Xmpz_invert(tls, &t, v, m) == 0 {
+				if g != nil {
+					Xmpz_gcd(tls, g, v, m)
+				}
+				return i
+			}
+		}
+		panic("internal error")
+	}
+
+	// inv = 1/(a[0]*...*a[i]), 1/a[i] = inv*a[0]*...*a[i-1].
+	for i := n - 1; i > 0; i-- {
+		Xmpz_mul(tls, &t, &inv, a[i])
+		Xmpz_mod(tls, &t, &t, m)
+		Xmpz_mul(tls, r[i], &inv, &c[i-1])
+		Xmpz_mod(tls, r[i], r[i], m)
+		Xmpz_swap(tls, &inv, &t)
+	}
+	Xmpz_swap(tls, r[0], &inv)
+	return -1
+}
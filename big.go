@@ -0,0 +1,89 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"math/big"
+	"unsafe"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// A big.Word and a limb have the same size, the conversions below reinterpret
+// slices of one as slices of the other.
+var (
+	_ [unsafe.Sizeof(big.Word(0)) - unsafe.Sizeof(limb(0))]struct{}
+	_ [unsafe.Sizeof(limb(0)) - unsafe.Sizeof(big.Word(0))]struct{}
+)
+
+// bigLimbs returns the limbs of |x|, aliasing x.
+func bigLimbs(x *big.Int) []limb {
+	b := x.Bits()
+	if len(b) == 0 {
+		return nil
+	}
+
+	return unsafe.Slice((*limb)(unsafe.Pointer(&b[0])), len(b))
+}
+
+// Xmpz_set_big sets r to x.
+func Xmpz_set_big(tls *crt.TLS, r *[1]Xmpz_srcptr, x *big.Int) {
+	mpzSetLimbs(tls, r, bigLimbs(x))
+	if x.Sign() < 0 {
+		Xmpz_neg(tls, r, r)
+	}
+}
+
+// Xmpz_get_big sets z to x and returns z. If z is nil a new big.Int is
+// allocated.
+func Xmpz_get_big(tls *crt.TLS, z *big.Int, x *[1]Xmpz_srcptr) *big.Int {
+	if z == nil {
+		z = new(big.Int)
+	}
+	s := mpzLimbs(x)
+	b := z.Bits()[:0]
+	if cap(b) < len(s) || len(s) == 0 {
+		b = make([]big.Word, len(s))
+	}
+	b = b[:len(s)]
+	if len(s) != 0 {
+		copy(unsafe.Slice((*limb)(unsafe.Pointer(&b[0])), len(b)), s)
+	}
+	z.SetBits(b)
+	if x[0].X_mp_size < 0 {
+		z.Neg(z)
+	}
+	return z
+}
+
+// Xmpz_roinit_big sets r to a read only view of x, without copying, and
+// returns &r[0]. r must not be modified or cleared and x must not be modified
+// while r is in use. Like a value initialized by Xmpz_roinit_n, r may be used
+// as a source operand only.
+func Xmpz_roinit_big(tls *crt.TLS, r *[1]Xmpz_srcptr, x *big.Int) *Xmpz_srcptr {
+	s := bigLimbs(x)
+	n := mpSize(len(s))
+	if x.Sign() < 0 {
+		n = -n
+	}
+	p := new(limb)
+	if len(s) != 0 {
+		p = &s[0]
+	}
+	return Xmpz_roinit_n(tls, r, p, n)
+}
+
+// FromBig returns a new value set to x. The value must be cleared by
+// Mpz_clear or Xmpz_clear when no longer needed. It is allocated by the
+// default context.
+func FromBig(x *big.Int) *[1]Xmpz_srcptr {
+	r := new([1]Xmpz_srcptr)
+	Mpz_init(r)
+	Mpz_set_big(r, x)
+	return r
+}
+
+// ToBig returns x as a new big.Int.
+func ToBig(x *[1]Xmpz_srcptr) *big.Int { return Mpz_get_big(nil, x) }
@@ -0,0 +1,285 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"context"
+	"math/bits"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// The *_ctx functions below are variants of long running functions that
+// return ctx.Err() when ctx is done before the computation completes. In that
+// case the result operand is not modified. They return the *LimitError of an
+// operation exceeding the size limit as an error as well, see SetMaxBits.
+
+// cancelInterval is the number of cheap steps, like multiplications by a
+// limb, performed between checks for cancellation.
+const cancelInterval = 64
+
+// isDone reports whether done is closed. A nil done is never closed.
+func isDone(done <-chan struct{}) bool {
+	if done == nil {
+		return false
+	}
+
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Xmpz_powm_ctx is like Xmpz_powm. It checks ctx before every window of the
+// exponent.
+func Xmpz_powm_ctx(tls *crt.TLS, ctx context.Context, r, b, e, m *[1]Xmpz_srcptr) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer recoverLimit(&err)
+
+	if !mpzPowm(tls, r, b, e, m, ctx.Done()) {
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// Xmpz_pow_ui_ctx is like Xmpz_pow_ui. It checks ctx before every bit of the
+// exponent.
+func Xmpz_pow_ui_ctx(tls *crt.TLS, ctx context.Context, r, b *[1]Xmpz_srcptr, e ulong) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer recoverLimit(&err)
+
+	checkBits(tls, "mpz_pow_ui", powBits(uint64(mpzBitLen(b)), uint64(e)))
+	var t [1]Xmpz_srcptr
+	Xmpz_init_set_ui(tls, &t, 1)
+
+	defer Xmpz_clear(tls, &t)
+
+	for bit := ulong(1) << (limbBits - 1); bit != 0; bit >>= 1 {
+		if isDone(ctx.Done()) {
+			return ctx.Err()
+		}
+
+		Xmpz_mul(tls, &t, &t, &t)
+		if e&bit != 0 {
+			Xmpz_mul(tls, &t, &t, b)
+		}
+	}
+	Xmpz_swap(tls, r, &t)
+	return nil
+}
+
+// Xmpz_ui_pow_ui_ctx is like Xmpz_ui_pow_ui. It checks ctx before every bit of
+// the exponent.
+func Xmpz_ui_pow_ui_ctx(tls *crt.TLS, ctx context.Context, r *[1]Xmpz_srcptr, b, e ulong) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer recoverLimit(&err)
+
+	checkBits(tls, "mpz_ui_pow_ui", powBits(uint64(bits.Len64(uint64(b))), uint64(e)))
+	var t [1]Xmpz_srcptr
+	Xmpz_init_set_ui(tls, &t, b)
+
+	defer Xmpz_clear(tls, &t)
+
+	return Xmpz_pow_ui_ctx(tls, ctx, r, &t, e)
+}
+
+// Xmpz_mul_2exp_ctx is like Xmpz_mul_2exp. It is not long running, ctx is
+// checked only before the operation.
+func Xmpz_mul_2exp_ctx(tls *crt.TLS, ctx context.Context, r, u *[1]Xmpz_srcptr, n ulong) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer recoverLimit(&err)
+
+	Xmpz_mul_2exp(tls, r, u, n)
+	return nil
+}
+
+// Xmpz_setbit_ctx is like Xmpz_setbit. It is not long running, ctx is checked
+// only before the operation.
+func Xmpz_setbit_ctx(tls *crt.TLS, ctx context.Context, d *[1]Xmpz_srcptr, i ulong) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer recoverLimit(&err)
+
+	Xmpz_setbit(tls, d, i)
+	return nil
+}
+
+// Xmpz_fac_ui_ctx is like Xmpz_fac_ui. It checks ctx periodically.
+func Xmpz_fac_ui_ctx(tls *crt.TLS, ctx context.Context, r *[1]Xmpz_srcptr, n ulong) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer recoverLimit(&err)
+
+	checkBits(tls, "mpz_fac_ui", facBits(n))
+	var t [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+
+	defer Xmpz_clear(tls, &t)
+
+	if err := facCtx(tls, ctx, &t, n); err != nil {
+		return err
+	}
+
+	Xmpz_swap(tls, r, &t)
+	return nil
+}
+
+// facCtx sets r to n!, checking ctx periodically.
+func facCtx(tls *crt.TLS, ctx context.Context, r *[1]Xmpz_srcptr, n ulong) error {
+	if n == 0 {
+		n = 1
+	}
+	Xmpz_set_ui(tls, r, n)
+	for i := 0; n > 2; i++ {
+		if i%cancelInterval == 0 && isDone(ctx.Done()) {
+			return ctx.Err()
+		}
+
+		n--
+		Xmpz_mul_ui(tls, r, r, n)
+	}
+	return nil
+}
+
+// Xmpz_bin_uiui_ctx is like Xmpz_bin_uiui. It checks ctx periodically.
+func Xmpz_bin_uiui_ctx(tls *crt.TLS, ctx context.Context, r *[1]Xmpz_srcptr, n, k ulong) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer recoverLimit(&err)
+
+	var t, f [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+	Xmpz_init(tls, &f)
+
+	defer func() {
+		Xmpz_clear(tls, &t)
+		Xmpz_clear(tls, &f)
+	}()
+
+	if err := binCtx(tls, ctx, &t, &f, n, k); err != nil {
+		return err
+	}
+
+	Xmpz_swap(tls, r, &t)
+	return nil
+}
+
+// binCtx sets r to the binomial coefficient of n and k using f as a
+// temporary, checking ctx periodically.
+func binCtx(tls *crt.TLS, ctx context.Context, r, f *[1]Xmpz_srcptr, n, k ulong) error {
+	if k > n {
+		Xmpz_set_ui(tls, r, 0)
+		return nil
+	}
+
+	if k > n/2 {
+		k = n - k
+	}
+	if err := facCtx(tls, ctx, f, k); err != nil {
+		return err
+	}
+
+	Xmpz_set_ui(tls, r, 1)
+	for i := 0; k > 0; i++ {
+		if i%cancelInterval == 0 && isDone(ctx.Done()) {
+			return ctx.Err()
+		}
+
+		Xmpz_mul_ui(tls, r, r, n)
+		n--
+		k--
+	}
+	Xmpz_divexact(tls, r, r, f)
+	return nil
+}
+
+// Xmpz_probab_prime_p_ctx is like Xmpz_probab_prime_p. It checks ctx before
+// every Miller-Rabin round and every squaring within a round.
+func Xmpz_probab_prime_p_ctx(tls *crt.TLS, ctx context.Context, n *[1]Xmpz_srcptr, reps int32) (_ int32, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	defer recoverLimit(&err)
+
+	if r, ok := primeSmall(tls, n); ok {
+		return r, nil
+	}
+
+	var nm1, q, y [1]Xmpz_srcptr
+	Xmpz_init(tls, &nm1)
+	Xmpz_init(tls, &q)
+	Xmpz_init(tls, &y)
+
+	defer func() {
+		Xmpz_clear(tls, &nm1)
+		Xmpz_clear(tls, &q)
+		Xmpz_clear(tls, &y)
+	}()
+
+	// |n|-1 = 2^k*q, q odd. The sign of the modulus is ignored by powm.
+	Xmpz_abs(tls, &nm1, n)
+	Xmpz_sub_ui(tls, &nm1, &nm1, 1)
+	k := Xmpz_scan1(tls, &nm1, 0)
+	Xmpz_tdiv_q_2exp(tls, &q, &nm1, k)
+	done := ctx.Done()
+	for j := int32(0); j < reps; j++ {
+		// The bases are those of Xmpz_probab_prime_p, j^2+j+41.
+		Xmpz_set_ui(tls, &y, ulong(j)*ulong(j)+ulong(j)+41)
+		if Xmpz_cmp(tls, &y, &nm1) >= 0 {
+			break
+		}
+
+		if !mpzPowm(tls, &y, &y, &q, n, done) {
+			return 0, ctx.Err()
+		}
+
+		if Xmpz_cmp_ui(tls, &y, 1) == 0 || Xmpz_cmp(tls, &y, &nm1) == 0 {
+			continue
+		}
+
+		composite := true
+		for i := k; i > 1; i-- {
+			if isDone(done) {
+				return 0, ctx.Err()
+			}
+
+			Xmpz_powm_ui(tls, &y, &y, 2, n)
+			if Xmpz_cmp(tls, &y, &nm1) == 0 {
+				composite = false
+				break
+			}
+
+			if Xmpz_cmp_ui(tls, &y, 1) <= 0 {
+				break
+			}
+		}
+		if composite {
+			return 0, nil
+		}
+	}
+	return 1, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command factor prints the prime factors of integers.
+//
+// Usage:
+//
+//	factor [-timeout d] [n ...]
+//
+// The integers are taken from the arguments or, if there are none, from the
+// lines of the standard input. For every integer n a line
+//
+//	n: p1 p2 ...
+//
+// is written to the standard output, listing the prime factors in ascending
+// order, each repeated according to its multiplicity. If -timeout is given,
+// the factorization of every n is abandoned after the duration d and an error
+// is reported instead. The exit status is 1 if any integer could not be
+// factored.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/cznic/minigmp"
+)
+
+var timeout = flag.Duration("timeout", 0, "time limit per integer, zero for none")
+
+func factor(s string) error {
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return fmt.Errorf("%q is not a valid integer", s)
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+
+		defer cancel()
+	}
+
+	f, err := minigmp.Factor(ctx, n)
+	if err != nil {
+		return fmt.Errorf("%v: %v", n, err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v:", n)
+	for _, v := range f {
+		for i := 0; i < v.K; i++ {
+			fmt.Fprintf(&b, " %v", v.P)
+		}
+	}
+	fmt.Println(b.String())
+	return nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [-timeout d] [n ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	args := flag.Args()
+	rc := 0
+	report := func(err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "factor: %v\n", err)
+			rc = 1
+		}
+	}
+	if len(args) != 0 {
+		for _, v := range args {
+			report(factor(v))
+		}
+		os.Exit(rc)
+	}
+
+	s := bufio.NewScanner(os.Stdin)
+	for s.Scan() {
+		if v := strings.TrimSpace(s.Text()); v != "" {
+			report(factor(v))
+		}
+	}
+	report(s.Err())
+	os.Exit(rc)
+}
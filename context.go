@@ -0,0 +1,115 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+var (
+	// defaultContext is used by every TLS not created by NewContext.
+	defaultContext = &Context{contextState: &contextState{}}
+
+	// contexts maps the TLS of a Context to its state. It does not keep the
+	// Context reachable, a Context that becomes unreachable without being
+	// closed is closed by a cleanup.
+	contexts     sync.Map     // *crt.TLS: *contextState
+	liveContexts atomic.Int64 // Number of entries in contexts.
+)
+
+// contextState is the part of a Context the functions of this package look
+// up by the TLS passed to them.
+type contextState struct {
+	mem     memory
+	maxBits atomic.Uint64 // Zero if unlimited.
+}
+
+// Context carries the state otherwise shared by all users of the package: the
+// memory allocator, including the secure memory mode setting, and the TLS
+// passed to the functions of this package.
+//
+// Operations use the Context their TLS argument belongs to. Passing
+// Context.TLS() to the X* functions therefore makes them allocate using the
+// allocator of that Context. A TLS not obtained from a Context, for example
+// one created by crt.NewTLS, uses the default context, which is the state the
+// package level functions like SetSecureMemory operate on.
+//
+// A value must be reallocated and cleared using a TLS of the Context that
+// allocated it. The Context settings may be changed at any time, but a
+// Context, like its TLS, must not be used by multiple goroutines
+// concurrently. Create a Context per goroutine instead.
+//
+// The Context must be kept reachable while its TLS is in use. A Context that
+// becomes unreachable is closed, like by Close, and its TLS then selects the
+// default context.
+type Context struct {
+	*contextState
+	tls *crt.TLS
+}
+
+// NewContext returns a new Context using the default memory functions with
+// the secure memory mode off.
+func NewContext() *Context {
+	c := &Context{contextState: &contextState{}, tls: crt.NewTLS()}
+	contexts.Store(c.tls, c.contextState)
+	liveContexts.Add(1)
+	runtime.AddCleanup(c, closeContext, c.tls)
+	return c
+}
+
+// closeContext removes the TLS of a Context from contexts and closes it.
+func closeContext(tls *crt.TLS) {
+	if _, ok := contexts.LoadAndDelete(tls); ok {
+		liveContexts.Add(-1)
+		tls.Close()
+	}
+}
+
+// contextOf returns the state of the Context tls belongs to. It does not need
+// to look up tls while no Context exists.
+func contextOf(tls *crt.TLS) *contextState {
+	if tls != nil && liveContexts.Load() != 0 {
+		if c, ok := contexts.Load(tls); ok {
+			return c.(*contextState)
+		}
+	}
+
+	return defaultContext.contextState
+}
+
+// TLS returns the TLS of c, to be passed to the functions of this package.
+func (c *Context) TLS() *crt.TLS { return c.tls }
+
+// Close releases the resources of c. All values allocated in c must be cleared
+// before calling Close. c must not be used afterwards.
+func (c *Context) Close() error {
+	if c.tls != nil {
+		closeContext(c.tls)
+		c.tls = nil
+	}
+	return nil
+}
+
+// SetMemoryFunctions sets the memory functions of c, see
+// Xmp_set_memory_functions.
+func (c *Context) SetMemoryFunctions(allocFunc func(*crt.TLS, sizeT) unsafe.Pointer, reallocFunc func(*crt.TLS, unsafe.Pointer, sizeT, sizeT) unsafe.Pointer, freeFunc func(*crt.TLS, unsafe.Pointer, sizeT)) {
+	c.mem.setFuncs(allocFunc, reallocFunc, freeFunc)
+}
+
+// MemoryFunctions returns the memory functions of c, see
+// Xmp_get_memory_functions.
+func (c *Context) MemoryFunctions() (allocFunc func(*crt.TLS, sizeT) unsafe.Pointer, reallocFunc func(*crt.TLS, unsafe.Pointer, sizeT, sizeT) unsafe.Pointer, freeFunc func(*crt.TLS, unsafe.Pointer, sizeT)) {
+	f := c.mem.load()
+	return f.alloc, f.realloc, f.free
+}
+
+// SetSecureMemory turns the secure memory mode of c on or off and returns the
+// previous setting, see the SetSecureMemory function.
+func (c *Context) SetSecureMemory(on bool) (old bool) { return c.mem.setSecure(on) }
@@ -0,0 +1,87 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"github.com/cznic/ccgo/crt"
+)
+
+// Congruence is the congruence x = R (mod M) of a system solved by Xmpz_crt.
+type Congruence struct {
+	R, M *[1]Xmpz_srcptr
+}
+
+// Xmpz_crt solves the system of congruences c. It sets m to the least common
+// multiple of the moduli and r to the solution in [0, m) and returns 1. The
+// moduli need not be pairwise coprime. If the system has no solution, which is
+// possible only if they are not, r and m are not modified and the result is 0.
+// The moduli must not be zero, their signs are ignored. The solution of an
+// empty system is 0 modulo 1.
+//
+// The congruences are combined one by one, in time quadratic in the size of
+// m. A product tree would not be faster without subquadratic multiplication
+// and extended GCD.
+func Xmpz_crt(tls *crt.TLS, r, m *[1]Xmpz_srcptr, c []Congruence) int32 {
+	for _, v := range c {
+		if v.M[0].X_mp_size == 0 {
+			panic("mpz_crt: zero modulus")
+		}
+	}
+
+	var x, n, y, k [1]Xmpz_srcptr
+	Xmpz_init(tls, &x)
+	Xmpz_init_set_ui(tls, &n, 1)
+	Xmpz_init(tls, &y)
+	Xmpz_init(tls, &k)
+
+	defer func() {
+		Xmpz_clear(tls, &x)
+		Xmpz_clear(tls, &n)
+		Xmpz_clear(tls, &y)
+		Xmpz_clear(tls, &k)
+	}()
+
+	for _, v := range c {
+		Xmpz_abs(tls, &k, v.M)
+		Xmpz_mod(tls, &y, v.R, &k)
+		if !crtMerge(tls, &x, &n, &y, &k) {
+			return 0
+		}
+	}
+
+	Xmpz_swap(tls, r, &x)
+	Xmpz_swap(tls, m, &n)
+	return 1
+}
+
+// crtMerge sets x and m to the solution of x' = x (mod m), x' = y (mod n) and
+// its modulus, lcm(m, n), and reports whether there is one. x and y must be in
+// [0, m) and [0, n). y and n are destroyed.
+func crtMerge(tls *crt.TLS, x, m, y, n *[1]Xmpz_srcptr) bool {
+	var g, s [1]Xmpz_srcptr
+	Xmpz_init(tls, &g)
+	Xmpz_init(tls, &s)
+
+	defer func() {
+		Xmpz_clear(tls, &g)
+		Xmpz_clear(tls, &s)
+	}()
+
+	// sm+tn = g = gcd(m, n). The solution exists iff g divides y-x and then
+	// it is x+m((y-x)/g*s mod n/g).
+	Xmpz_gcdext(tls, &g, &s, nil, m, n)
+	Xmpz_sub(tls, y, y, x)
+	if Xmpz_divisible_p(tls, y, &g) == 0 {
+		return false
+	}
+
+	Xmpz_divexact(tls, y, y, &g)
+	Xmpz_divexact(tls, n, n, &g)
+	Xmpz_mul(tls, y, y, &s)
+	Xmpz_mod(tls, y, y, n)
+	Xmpz_addmul(tls, x, y, m)
+	Xmpz_mul(tls, m, m, n)
+	return true
+}
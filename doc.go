@@ -5,8 +5,117 @@
 // Package minigmp is a small implementation of a subset of GMP's mpn and mpz
 // interfaces.
 //
+// TLS
+//
+// Every exported X* function has a variant without the X prefix and the TLS
+// parameter, for example Mpz_add for Xmpz_add. The variants use a TLS from an
+// internal pool, belonging to the default context. The functions taking a TLS
+// are kept for compatibility with the C ABI of the code produced by ccgo and
+// for use with a Context.
+//
+// Concurrency
+//
+// The package is safe for concurrent use under the following rules.
+//
+// - Every goroutine must use its own TLS, see Context. The functions not
+// taking a TLS satisfy this rule automatically.
+//
+// - Distinct values may be used by different goroutines concurrently, all
+// Xmpz_* and Xmpn_* functions included.
+//
+// - A value may be read by multiple goroutines concurrently, for example as a
+// source operand, provided no goroutine modifies it at the same time.
+//
+// - Modulus and Context instances must not be used by multiple goroutines
+// concurrently.
+//
+// - The package level settings, like SetMulParallelism, SetSecureMemory or
+// Xmp_set_memory_functions, may be changed at any time. Operations in
+// progress may use either the old or the new setting.
+//
 // Changelog
 //
+// 2026-10-18:
+//
+// - Large multiplications can be split across goroutines, see
+// SetMulParallelism.
+//
+// - On amd64 the basic mpn add, subtract, shift and multiply-by-limb
+// primitives are implemented in assembly, using MULX and ADX when the CPU
+// supports them. Build with the purego tag to use the Go versions.
+//
+// - Limb multiplication and division use math/bits instead of emulating
+// double limb arithmetic.
+//
+// - Xmpz_powm and Xmpz_powm_ui use sliding window exponentiation with
+// Montgomery reduction for odd moduli and Barrett reduction for even ones.
+//
+// - Modulus precomputes the reduction constants of a modulus for repeated
+// modular multiplication, squaring, addition, subtraction and
+// exponentiation.
+//
+// - The side channel silent functions of GMP, Xmpn_sec_* and Xmpn_cnd_*,
+// whose instruction traces do not depend on the values of the operands.
+//
+// - An opt-in secure memory mode, in which all memory is wiped before it is
+// released, see SetSecureMemory.
+//
+// - The memory functions and the secure memory mode are per Context, the
+// package level ones are those of the default context, see NewContext.
+//
+// - Concurrent use of distinct values is free of data races and the
+// guarantees are documented, see Concurrency above.
+//
+// - Variants of the X* functions not taking a TLS, see TLS above.
+//
+// - A safe mpn layer operating on []Word slices, see Word.
+//
+// - Conversions from and to math/big copying the limbs directly, see
+// FromBig, ToBig and Xmpz_roinit_big.
+//
+// - A configurable maximum size of values, reported by a *LimitError, a
+// panic or an error returned by the *_ctx functions, see SetMaxBits.
+//
+// - Variants of long running functions taking a context.Context, like
+// Xmpz_powm_ctx, which return early when it is done.
+//
+// - Sizes of values are checked for overflow of the size fields, see
+// MaxWords. On amd64, the mpsize64 build tag widens the size fields to 64
+// bits, for values of more than 2^31 limbs.
+//
+// - The Jacobi, Legendre and Kronecker symbols, see Xmpz_kronecker.
+//
+// - The Baillie-PSW primality test, exact for values below 2^64, see
+// Xmpz_prime_p.
+//
+// - Xmpz_nextprime, Xmpz_prevprime and generators of random primes and of
+// safe primes, see Xmpz_rand_prime and Xmpz_rand_safe_prime.
+//
+// - Random number states with pluggable sources, Xmpz_urandomb,
+// Xmpz_urandomm and Xmpz_rrandomb, see RandState.
+//
+// - The Mersenne Twister and linear congruential generators of GMP,
+// producing the same bits as GMP for the same seed, see Xgmp_randinit_mt
+// and Xgmp_randinit_lc_2exp. Xgmp_randinit_default now uses the Mersenne
+// Twister.
+//
+// - Integer factorization by trial division, Pollard's rho and p-1 and
+// ECM, see Factor and the command factor in cmd/factor.
+//
+// - Modular square roots modulo primes, prime powers and factored
+// composites, see Xmpz_sqrtmod, Xmpz_sqrtmod_pow_ui and
+// Xmpz_sqrtmod_factors.
+//
+// - Solving systems of congruences with moduli not necessarily coprime,
+// see Xmpz_crt.
+//
+// - Product trees, remainder trees, scaled remainder trees and batch GCD,
+// optionally using multiple goroutines, see ProductTree, BatchGCD and
+// SetTreeParallelism.
+//
+// - Batch modular inversion by Montgomery's trick, see
+// Xmpz_invert_batch.
+//
 // 2017-07-18:
 //
 // - Support for Linux/386 is in.
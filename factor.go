@@ -0,0 +1,644 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/bits"
+	"sort"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+const (
+	rhoIterations = 1 << 16 // Iterations of Pollard's rho before trying p-1 and ECM.
+	rhoBatch      = 128     // Differences multiplied together per gcd in Pollard's rho.
+	pm1B1         = 100000  // Bound of Pollard's p-1.
+	pm1Batch      = 64      // Primes processed per gcd in Pollard's p-1.
+	ecmB2         = 100     // Bound of ECM stage 2 relative to the stage 1 bound.
+	ecmD          = 2310    // Giant step of ECM stage 2, 2*3*5*7*11.
+)
+
+// ecmLevels are the stage 1 bounds of ECM with the number of curves tried for
+// each. They are the bounds and counts GMP-ECM uses for factors of 15, 20, 25,
+// 30, 35 and 40 digits. Its stage 2 bounds are larger than ecmB2 times the
+// stage 1 bound, so a level finds a factor of that size with a lower
+// probability here. The last level repeats until a factor is found.
+var ecmLevels = []struct {
+	b1     ulong
+	curves int
+}{
+	{2000, 25},
+	{11000, 90},
+	{50000, 300},
+	{250000, 700},
+	{1000000, 1800},
+	{3000000, 5100},
+}
+
+// PrimeFactor is a prime P occurring K times in a factorization.
+type PrimeFactor struct {
+	P *big.Int
+	K int
+}
+
+// Factor returns the prime factorization of |n|, ordered by the primes. The
+// factorization of 1 is empty. n must not be zero.
+//
+// The factors are found by trial division, Pollard's rho in the variant of
+// Brent, Pollard's p-1 and Lenstra's elliptic curve method using Montgomery
+// curves and the standard stage 2, in that order. The random choices are made by the
+// default generator of Xgmp_randinit_default with the default seed, so the
+// computation is deterministic. The factors are prime in the sense of
+// Xmpz_prime_p with PrimeBPSW.
+//
+// The time needed grows exponentially with the size of the second largest
+// prime factor. Factor checks ctx periodically and returns ctx.Err() if ctx is
+// done before the factorization completes.
+func Factor(ctx context.Context, n *big.Int) ([]PrimeFactor, error) {
+	if n.Sign() == 0 {
+		return nil, errors.New("Factor: zero has no factorization")
+	}
+
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	f := newFactorizer(tls, ctx)
+
+	defer f.close()
+
+	if err := f.factor(n); err != nil {
+		return nil, err
+	}
+
+	return f.result(), nil
+}
+
+// factorizer holds the state of a factorization.
+type factorizer struct {
+	ctx    context.Context
+	done   <-chan struct{}
+	found  []PrimeFactor
+	primes []ulong // The primes up to sieved.
+	rand   RandState
+	sieved ulong
+	tls    *crt.TLS
+}
+
+func newFactorizer(tls *crt.TLS, ctx context.Context) *factorizer {
+	f := &factorizer{ctx: ctx, done: ctx.Done(), tls: tls}
+	Xgmp_randinit_default(tls, &f.rand)
+	return f
+}
+
+func (f *factorizer) close() { Xgmp_randclear(f.tls, &f.rand) }
+
+func (f *factorizer) add(p *big.Int, k int) { f.found = append(f.found, PrimeFactor{p, k}) }
+
+// result returns the factors found, ordered and with equal primes merged.
+func (f *factorizer) result() (r []PrimeFactor) {
+	sort.Slice(f.found, func(i, j int) bool { return f.found[i].P.Cmp(f.found[j].P) < 0 })
+	for _, v := range f.found {
+		if n := len(r); n != 0 && r[n-1].P.Cmp(v.P) == 0 {
+			r[n-1].K += v.K
+			continue
+		}
+
+		r = append(r, v)
+	}
+	return r
+}
+
+// factor adds the prime factors of |n| to f.found.
+func (f *factorizer) factor(n *big.Int) error {
+	tls := f.tls
+	var m, d [1]Xmpz_srcptr
+	Xmpz_init(tls, &m)
+	Xmpz_init(tls, &d)
+
+	defer func() {
+		Xmpz_clear(tls, &m)
+		Xmpz_clear(tls, &d)
+	}()
+
+	Xmpz_set_big(tls, &m, n)
+	Xmpz_abs(tls, &m, &m)
+	f.trial(&m)
+
+	// Every item of work is a factor of n yet to be factored, K is its
+	// multiplicity.
+	work := []PrimeFactor{{Xmpz_get_big(tls, nil, &m), 1}}
+	for len(work) != 0 {
+		if err := f.ctx.Err(); err != nil {
+			return err
+		}
+
+		w := work[len(work)-1]
+		work = work[:len(work)-1]
+		Xmpz_set_big(tls, &m, w.P)
+		if Xmpz_cmp_ui(tls, &m, 1) == 0 {
+			continue
+		}
+
+		if bpsw(tls, &m) != 0 {
+			f.add(w.P, w.K)
+			continue
+		}
+
+		if k := f.root(&d, &m); k != 0 {
+			work = append(work, PrimeFactor{Xmpz_get_big(tls, nil, &d), w.K * k})
+			continue
+		}
+
+		if err := f.split(&d, &m); err != nil {
+			return err
+		}
+
+		Xmpz_divexact(tls, &m, &m, &d)
+		work = append(work, PrimeFactor{Xmpz_get_big(tls, nil, &d), w.K}, PrimeFactor{Xmpz_get_big(tls, nil, &m), w.K})
+	}
+	return nil
+}
+
+// trial removes from m the prime factors below sievePrimesMax and adds them
+// to f.found. If the rest of m is less than sievePrimesMax^2, it is prime and
+// it is moved to f.found as well, leaving m set to one.
+func (f *factorizer) trial(m *[1]Xmpz_srcptr) {
+	tls := f.tls
+	if m[0].X_mp_size == 0 {
+		return
+	}
+
+	if k := Xmpz_scan1(tls, m, 0); k != 0 {
+		Xmpz_tdiv_q_2exp(tls, m, m, k)
+		f.add(big.NewInt(2), int(k))
+	}
+	for _, p := range sievePrimes {
+		if Xmpz_cmp_ui(tls, m, p*p) < 0 {
+			break
+		}
+
+		k := 0
+		for Xmpz_divisible_ui_p(tls, m, p) != 0 {
+			Xmpz_divexact_ui(tls, m, m, p)
+			k++
+		}
+		if k != 0 {
+			f.add(new(big.Int).SetUint64(uint64(p)), k)
+		}
+	}
+	if Xmpz_cmp_ui(tls, m, 1) > 0 && Xmpz_cmp_ui(tls, m, sievePrimesMax*sievePrimesMax) < 0 {
+		f.add(Xmpz_get_big(tls, nil, m), 1)
+		Xmpz_set_ui(tls, m, 1)
+	}
+}
+
+// root returns the greatest k > 1 such that m = r^k, setting r, or zero if m
+// is not a perfect power. m must not have prime factors below sievePrimesMax.
+func (f *factorizer) root(r, m *[1]Xmpz_srcptr) int {
+	tls := f.tls
+	var t [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+	Xmpz_set(tls, r, m)
+	k := 1
+	for _, p := range append([]ulong{2}, sievePrimes...) {
+		// The prime factors of r have at least 11 bits.
+		for Xmpz_sizeinbase(tls, r, 2) > sizeT(11*p) && Xmpz_root(tls, &t, r, p) != 0 {
+			Xmpz_swap(tls, r, &t)
+			k *= int(p)
+		}
+		if Xmpz_sizeinbase(tls, r, 2) <= sizeT(11*p) {
+			break
+		}
+	}
+	Xmpz_clear(tls, &t)
+	if k == 1 {
+		return 0
+	}
+
+	return k
+}
+
+// split sets d to a nontrivial factor of m. m must be odd, composite and not a
+// perfect power.
+func (f *factorizer) split(d, m *[1]Xmpz_srcptr) error {
+	if f.rho(d, m) {
+		return nil
+	}
+
+	if err := f.ctx.Err(); err != nil {
+		return err
+	}
+
+	if f.pm1(d, m, pm1B1) {
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		if err := f.ctx.Err(); err != nil {
+			return err
+		}
+
+		l := ecmLevels[min(i, len(ecmLevels)-1)]
+		if f.ecm(d, m, l.b1, l.curves) {
+			return nil
+		}
+	}
+}
+
+// primesTo returns the primes up to b.
+func (f *factorizer) primesTo(b ulong) []ulong {
+	if b > f.sieved {
+		composite := make([]bool, b+1)
+		f.primes = f.primes[:0]
+		for i := ulong(2); i <= b; i++ {
+			if composite[i] {
+				continue
+			}
+
+			f.primes = append(f.primes, i)
+			for j := uint64(i) * uint64(i); j <= uint64(b); j += uint64(i) {
+				composite[j] = true
+			}
+		}
+		f.sieved = b
+	}
+	return f.primes[:sort.Search(len(f.primes), func(i int) bool { return f.primes[i] > b })]
+}
+
+// primePower returns the greatest power of the prime p not exceeding b.
+func primePower(p, b ulong) ulong {
+	q := p
+	for q <= b/p {
+		q *= p
+	}
+	return q
+}
+
+// nontrivial reports whether 1 < d < m.
+func nontrivial(tls *crt.TLS, d, m *[1]Xmpz_srcptr) bool {
+	return Xmpz_cmp_ui(tls, d, 1) > 0 && Xmpz_cmp(tls, d, m) < 0
+}
+
+// rho sets d to a factor of m found by Pollard's rho method in the variant of
+// Brent, iterating x^2+c from a random x and c, and reports whether it found a
+// nontrivial one.
+func (f *factorizer) rho(d, m *[1]Xmpz_srcptr) bool {
+	tls := f.tls
+	var c, q, t, x, y, ys [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&c, &q, &t, &x, &y, &ys} {
+		Xmpz_init(tls, v)
+	}
+
+	defer func() {
+		for _, v := range []*[1]Xmpz_srcptr{&c, &q, &t, &x, &y, &ys} {
+			Xmpz_clear(tls, v)
+		}
+	}()
+
+	step := func(y *[1]Xmpz_srcptr) {
+		Xmpz_mul(tls, y, y, y)
+		Xmpz_add(tls, y, y, &c)
+		Xmpz_mod(tls, y, y, m)
+	}
+	Xmpz_urandomm(tls, &y, &f.rand, m)
+	Xmpz_sub_ui(tls, &c, m, 1)
+	Xmpz_urandomm(tls, &c, &f.rand, &c)
+	Xmpz_add_ui(tls, &c, &c, 1)
+	Xmpz_set_ui(tls, &q, 1)
+	Xmpz_set_ui(tls, d, 1)
+
+	// Brent's cycle detection compares x, the value at the last power of
+	// two, with the r values following it.
+	for r := 1; Xmpz_cmp_ui(tls, d, 1) == 0; r *= 2 {
+		if r > rhoIterations {
+			return false
+		}
+
+		Xmpz_set(tls, &x, &y)
+		for i := 0; i < r; i++ {
+			step(&y)
+		}
+		for k := 0; k < r && Xmpz_cmp_ui(tls, d, 1) == 0; k += rhoBatch {
+			if isDone(f.done) {
+				return false
+			}
+
+			Xmpz_set(tls, &ys, &y)
+			for i := 0; i < min(rhoBatch, r-k); i++ {
+				step(&y)
+				Xmpz_sub(tls, &t, &x, &y)
+				Xmpz_mul(tls, &q, &q, &t)
+				Xmpz_mod(tls, &q, &q, m)
+			}
+			Xmpz_gcd(tls, d, &q, m)
+		}
+	}
+	if Xmpz_cmp(tls, d, m) == 0 {
+		// The batch collected all the factors, repeat it one step at a
+		// time.
+		for {
+			step(&ys)
+			Xmpz_sub(tls, &t, &x, &ys)
+			if Xmpz_gcd(tls, d, &t, m); Xmpz_cmp_ui(tls, d, 1) != 0 {
+				break
+			}
+		}
+	}
+	return nontrivial(tls, d, m)
+}
+
+// pm1 sets d to a factor of m found by Pollard's p-1 method with the bound b1
+// and reports whether it found a nontrivial one.
+func (f *factorizer) pm1(d, m *[1]Xmpz_srcptr, b1 ulong) bool {
+	tls := f.tls
+	var a, t [1]Xmpz_srcptr
+	Xmpz_init_set_ui(tls, &a, 2)
+	Xmpz_init(tls, &t)
+
+	defer func() {
+		Xmpz_clear(tls, &a)
+		Xmpz_clear(tls, &t)
+	}()
+
+	primes := f.primesTo(b1)
+	for i, p := range primes {
+		Xmpz_powm_ui(tls, &a, &a, primePower(p, b1), m)
+		if (i+1)%pm1Batch != 0 && i != len(primes)-1 {
+			continue
+		}
+
+		if isDone(f.done) {
+			return false
+		}
+
+		Xmpz_sub_ui(tls, &t, &a, 1)
+		if Xmpz_gcd(tls, d, &t, m); Xmpz_cmp_ui(tls, d, 1) != 0 {
+			return nontrivial(tls, d, m)
+		}
+	}
+	return false
+}
+
+// ecmCurve is the Montgomery curve By^2 = x^3+Ax^2+x modulo m. Points are
+// represented by their X and Z projective coordinates. Except in init, the
+// arithmetic is that of z, the coordinates being the Montgomery forms of
+// some multiple of X and Z, which represent the same point.
+type ecmCurve struct {
+	a24              [1]Xmpz_srcptr // Montgomery form of (A+2)/4.
+	m                *[1]Xmpz_srcptr
+	sx, sz           [][1]Xmpz_srcptr // Baby steps of stage 2.
+	t1, t2, t3, u, v [1]Xmpz_srcptr
+	g                [1]Xmpz_srcptr // Product of stage 2.
+	px, pz, qx, qz   [1]Xmpz_srcptr // Points of stage 2.
+	rx, rz, tx, tz   [1]Xmpz_srcptr
+	tls              *crt.TLS
+	z                *Modulus
+}
+
+func newECMCurve(tls *crt.TLS, m *[1]Xmpz_srcptr) *ecmCurve {
+	c := &ecmCurve{m: m, tls: tls, z: NewModulus(tls, m)}
+	for j := 1; j < ecmD/2; j += 2 {
+		if ecmCoprime(j) {
+			c.sx = append(c.sx, [1]Xmpz_srcptr{})
+			c.sz = append(c.sz, [1]Xmpz_srcptr{})
+		}
+	}
+	for _, v := range c.values() {
+		Xmpz_init(tls, v)
+	}
+	return c
+}
+
+func (c *ecmCurve) close() {
+	for _, v := range c.values() {
+		Xmpz_clear(c.tls, v)
+	}
+}
+
+// values returns the values of c.
+func (c *ecmCurve) values() []*[1]Xmpz_srcptr {
+	r := []*[1]Xmpz_srcptr{&c.a24, &c.t1, &c.t2, &c.t3, &c.u, &c.v, &c.g, &c.px, &c.pz, &c.qx, &c.qz, &c.rx, &c.rz, &c.tx, &c.tz}
+	for i := range c.sx {
+		r = append(r, &c.sx[i], &c.sz[i])
+	}
+	return r
+}
+
+func (c *ecmCurve) mulMod(r, a, b *[1]Xmpz_srcptr) {
+	Xmpz_mul(c.tls, r, a, b)
+	Xmpz_mod(c.tls, r, r, c.m)
+}
+
+// init sets the curve and the point (x:z) by the parametrization of Suyama
+// from sigma. If that needs the inverse of a value not invertible modulo m, it
+// sets d to the gcd of the value and m and returns false.
+func (c *ecmCurve) init(d, x, z, sigma *[1]Xmpz_srcptr) bool {
+	tls := c.tls
+
+	// u = sigma^2-5, v = 4sigma, x = u^3, z = v^3.
+	Xmpz_mul(tls, &c.u, sigma, sigma)
+	Xmpz_sub_ui(tls, &c.u, &c.u, 5)
+	Xmpz_mod(tls, &c.u, &c.u, c.m)
+	Xmpz_mul_2exp(tls, &c.v, sigma, 2)
+	Xmpz_mod(tls, &c.v, &c.v, c.m)
+	c.mulMod(x, &c.u, &c.u)
+	c.mulMod(x, x, &c.u)
+	c.mulMod(z, &c.v, &c.v)
+	c.mulMod(z, z, &c.v)
+
+	// (A+2)/4 = (v-u)^3(3u+v)/(16u^3v).
+	Xmpz_sub(tls, &c.t1, &c.v, &c.u)
+	c.mulMod(&c.a24, &c.t1, &c.t1)
+	c.mulMod(&c.a24, &c.a24, &c.t1)
+	Xmpz_mul_ui(tls, &c.t1, &c.u, 3)
+	Xmpz_add(tls, &c.t1, &c.t1, &c.v)
+	c.mulMod(&c.a24, &c.a24, &c.t1)
+	c.mulMod(&c.t1, x, &c.v)
+	Xmpz_mul_2exp(tls, &c.t1, &c.t1, 4)
+	if Xmpz_invert(tls, &c.t2, &c.t1, c.m) == 0 {
+		Xmpz_gcd(tls, d, &c.t1, c.m)
+		return false
+	}
+
+	c.mulMod(&c.a24, &c.a24, &c.t2)
+	c.z.ToMontgomery(tls, &c.a24, &c.a24)
+	return true
+}
+
+// dbl sets (x2:z2) to 2(x:z).
+func (c *ecmCurve) dbl(x2, z2, x, z *[1]Xmpz_srcptr) {
+	// t1 = (x+z)^2, t2 = (x-z)^2, x2 = t1t2, t3 = t1-t2 = 4xz,
+	// z2 = t3(t2+t3(A+2)/4).
+	c.z.AddMod(c.tls, &c.t1, x, z)
+	c.z.SqrMod(c.tls, &c.t1, &c.t1)
+	c.z.SubMod(c.tls, &c.t2, x, z)
+	c.z.SqrMod(c.tls, &c.t2, &c.t2)
+	c.z.MulMod(c.tls, x2, &c.t1, &c.t2)
+	c.z.SubMod(c.tls, &c.t3, &c.t1, &c.t2)
+	c.z.MulMod(c.tls, &c.t1, &c.t3, &c.a24)
+	c.z.AddMod(c.tls, &c.t1, &c.t1, &c.t2)
+	c.z.MulMod(c.tls, z2, &c.t1, &c.t3)
+}
+
+// add sets (x3:z3) to (xp:zp)+(xq:zq), given their difference (xd:zd). The
+// result may alias p or q, but not the difference.
+func (c *ecmCurve) add(x3, z3, xp, zp, xq, zq, xd, zd *[1]Xmpz_srcptr) {
+	// u = (xp-zp)(xq+zq), v = (xp+zp)(xq-zq), x3 = zd(u+v)^2,
+	// z3 = xd(u-v)^2.
+	c.z.SubMod(c.tls, &c.t1, xp, zp)
+	c.z.AddMod(c.tls, &c.t2, xq, zq)
+	c.z.MulMod(c.tls, &c.u, &c.t1, &c.t2)
+	c.z.AddMod(c.tls, &c.t1, xp, zp)
+	c.z.SubMod(c.tls, &c.t2, xq, zq)
+	c.z.MulMod(c.tls, &c.v, &c.t1, &c.t2)
+	c.z.AddMod(c.tls, &c.t1, &c.u, &c.v)
+	c.z.SqrMod(c.tls, &c.t1, &c.t1)
+	c.z.SubMod(c.tls, &c.t2, &c.u, &c.v)
+	c.z.SqrMod(c.tls, &c.t2, &c.t2)
+	c.z.MulMod(c.tls, x3, &c.t1, zd)
+	c.z.MulMod(c.tls, z3, &c.t2, xd)
+}
+
+// mul sets (x:z) to k(x:z), k > 0, using the Montgomery ladder.
+func (c *ecmCurve) mul(x, z *[1]Xmpz_srcptr, k ulong, x0, z0, x1, z1 *[1]Xmpz_srcptr) {
+	// (x0:z0) = j(x:z) and (x1:z1) = (j+1)(x:z) for the leading bits j of k.
+	Xmpz_set(c.tls, x0, x)
+	Xmpz_set(c.tls, z0, z)
+	c.dbl(x1, z1, x, z)
+	for i := bits.Len64(uint64(k)) - 2; i >= 0; i-- {
+		if k>>uint(i)&1 != 0 {
+			c.add(x0, z0, x0, z0, x1, z1, x, z)
+			c.dbl(x1, z1, x1, z1)
+			continue
+		}
+
+		c.add(x1, z1, x0, z0, x1, z1, x, z)
+		c.dbl(x0, z0, x0, z0)
+	}
+	Xmpz_swap(c.tls, x, x0)
+	Xmpz_swap(c.tls, z, z0)
+}
+
+// stage2 sets d to the gcd of m and the product of X(kDQ)Z(jQ)-X(jQ)Z(kDQ),
+// where Q is (x:z), D is ecmD, j < D/2 is coprime to D and (k-1)D <= b2. The
+// product is zero modulo a prime p of m if the order of Q modulo p is a prime
+// in (D/2, b2], which is kD+j or kD-j for some j and k. stage2 reports whether
+// d is a nontrivial factor, or false if done is closed first. (x:z) is
+// destroyed.
+func (c *ecmCurve) stage2(d, x, z *[1]Xmpz_srcptr, b2 ulong, done <-chan struct{}) bool {
+	tls := c.tls
+
+	// Baby steps, (j+2)Q = jQ+2Q with the difference (j-2)Q. (px:pz) = jQ,
+	// (rx:rz) = (j-2)Q, which is -Q for j = 1, and (qx:qz) = 2Q.
+	c.dbl(&c.qx, &c.qz, x, z)
+	Xmpz_set(tls, &c.px, x)
+	Xmpz_set(tls, &c.pz, z)
+	Xmpz_set(tls, &c.rx, x)
+	Xmpz_set(tls, &c.rz, z)
+	for i, j := 0, 1; i < len(c.sx); j += 2 {
+		if ecmCoprime(j) {
+			Xmpz_set(tls, &c.sx[i], &c.px)
+			Xmpz_set(tls, &c.sz[i], &c.pz)
+			i++
+		}
+		c.add(&c.tx, &c.tz, &c.px, &c.pz, &c.qx, &c.qz, &c.rx, &c.rz)
+		Xmpz_swap(tls, &c.rx, &c.px)
+		Xmpz_swap(tls, &c.rz, &c.pz)
+		Xmpz_swap(tls, &c.px, &c.tx)
+		Xmpz_swap(tls, &c.pz, &c.tz)
+	}
+
+	// Giant steps, (k+1)G = kG+G with the difference (k-1)G, G = DQ.
+	// (x:z) = G, (px:pz) = kG and (rx:rz) = (k-1)G. The Montgomery form of
+	// the terms does not change the gcd of the product with m.
+	c.mul(x, z, ecmD, &c.px, &c.pz, &c.rx, &c.rz)
+	Xmpz_set_ui(tls, &c.g, 1)
+	c.accumulate(x, z)
+	c.dbl(&c.px, &c.pz, x, z)
+	Xmpz_set(tls, &c.rx, x)
+	Xmpz_set(tls, &c.rz, z)
+	for k := ulong(2); k <= b2/ecmD+1; k++ {
+		if k%cancelInterval == 0 && isDone(done) {
+			return false
+		}
+
+		c.accumulate(&c.px, &c.pz)
+		c.add(&c.tx, &c.tz, &c.px, &c.pz, x, z, &c.rx, &c.rz)
+		Xmpz_swap(tls, &c.rx, &c.px)
+		Xmpz_swap(tls, &c.rz, &c.pz)
+		Xmpz_swap(tls, &c.px, &c.tx)
+		Xmpz_swap(tls, &c.pz, &c.tz)
+	}
+	Xmpz_gcd(tls, d, &c.g, c.m)
+	return nontrivial(tls, d, c.m)
+}
+
+// accumulate multiplies the product of stage 2 by the terms of the giant step
+// (x:z).
+func (c *ecmCurve) accumulate(x, z *[1]Xmpz_srcptr) {
+	for i := range c.sx {
+		c.z.MulMod(c.tls, &c.u, x, &c.sz[i])
+		c.z.MulMod(c.tls, &c.v, &c.sx[i], z)
+		c.z.SubMod(c.tls, &c.u, &c.u, &c.v)
+		c.z.MulMod(c.tls, &c.g, &c.g, &c.u)
+	}
+}
+
+// ecmCoprime reports whether the odd j is coprime to ecmD.
+func ecmCoprime(j int) bool { return j%3 != 0 && j%5 != 0 && j%7 != 0 && j%11 != 0 }
+
+// ecm sets d to a factor of m found by Lenstra's elliptic curve method with
+// the stage 1 bound b1 and the stage 2 bound ecmB2*b1, trying up to curves
+// random curves, and reports whether it found a nontrivial one.
+func (f *factorizer) ecm(d, m *[1]Xmpz_srcptr, b1 ulong, curves int) bool {
+	tls := f.tls
+	var sigma, x, z, x0, z0, x1, z1 [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&sigma, &x, &z, &x0, &z0, &x1, &z1} {
+		Xmpz_init(tls, v)
+	}
+	c := newECMCurve(tls, m)
+
+	defer func() {
+		for _, v := range []*[1]Xmpz_srcptr{&sigma, &x, &z, &x0, &z0, &x1, &z1} {
+			Xmpz_clear(tls, v)
+		}
+		c.close()
+	}()
+
+	primes := f.primesTo(b1)
+	for i := 0; i < curves; i++ {
+		// sigma in [6, m).
+		Xmpz_sub_ui(tls, &sigma, m, 6)
+		Xmpz_urandomm(tls, &sigma, &f.rand, &sigma)
+		Xmpz_add_ui(tls, &sigma, &sigma, 6)
+		if !c.init(d, &x, &z, &sigma) {
+			if nontrivial(tls, d, m) {
+				return true
+			}
+
+			continue
+		}
+
+		for j, p := range primes {
+			if j%cancelInterval == 0 && isDone(f.done) {
+				return false
+			}
+
+			c.mul(&x, &z, primePower(p, b1), &x0, &z0, &x1, &z1)
+		}
+		if Xmpz_gcd(tls, d, &z, m); nontrivial(tls, d, m) {
+			return true
+		}
+
+		if c.stage2(d, &x, &z, ecmB2*b1, f.done) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,5 +3,6 @@
 // license that can be found in the LICENSE file.
 
 //go:generate go run generator.go
+//go:generate go run wrappers.go
 
 package minigmp
@@ -342,12 +342,52 @@ func unconvert(pth string) {
 	}
 }
 
-func lib() {
-	const tweak = `
+// tweaks are applied, in order, to the generated code before formatting it.
+var tweaks = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`
 	crt\.Xfprintf\(tls, \(\*crt\.XFILE\)\(Xstderr\), str\([0-9]+\), unsafe\.Pointer\(unsafe\.Pointer\(_msg\)\)\)
 	crt\.Xabort\(tls\)
-`
-	re := regexp.MustCompile(tweak)
+`), " panic(crt.GoString(_msg)) "},
+	// Xmpn_mul is provided by mul.go, which may split large products
+	// across goroutines and falls back to the C basecase otherwise.
+	{regexp.MustCompile(`func Xmpn_mul\(`), "func _mpn_mul_basecase("},
+	// The primitives below are provided by mpn_$GOARCH.s where available and
+	// by mpn_generic.go otherwise.
+	{regexp.MustCompile(`func Xmpn_(add_n|sub_n|mul_1|addmul_1|submul_1|lshift|rshift)\(`), "func _mpn_${1}_generic("},
+	// The C versions of the functions below emulate double limb arithmetic
+	// using half limbs. They are replaced by the math/bits based versions
+	// in mpn.go.
+	{regexp.MustCompile(`func Xmpn_invert_3by2\(`), "func _mpn_invert_3by2_generic("},
+	{regexp.MustCompile(`func _mpn_(div_qr_1_preinv|div_qr_2_preinv|div_qr_pi1)\(`), "func _mpn_${1}_generic("},
+	// Xmpz_powm is provided by powm.go using Montgomery or Barrett reduction
+	// and sliding window exponentiation.
+	{regexp.MustCompile(`func Xmpz_powm\(`), "func _mpz_powm_generic("},
+	// The memory functions are provided by memory.go. They use the allocator
+	// of the Context associated with the TLS.
+	{regexp.MustCompile(`func Xmp_(set|get)_memory_functions\(`), "func _mp_${1}_memory_functions_generic("},
+	{regexp.MustCompile(`_gmp_(allocate|reallocate|free)_func([^(_])`), "_gmp_${1}_func_generic${2}"},
+	// The functions below are provided by limit.go, which checks the size of
+	// the results against the limit of the Context.
+	{regexp.MustCompile(`func _mpz_realloc\(`), "func _mpz_realloc_generic("},
+	{regexp.MustCompile(`func Xmpz_(pow_ui|ui_pow_ui|mul_2exp|fac_ui|setbit|init2|limbs_finish)\(`), "func _mpz_${1}_generic("},
+	// The functions below are provided by limit.go as well. They release
+	// their temporaries when an operation exceeding the limit panics.
+	{regexp.MustCompile(`func Xmpz_(addmul|submul|addmul_ui|submul_ui|lcm|bin_uiui|gcd|gcdext|invert|root|rootrem)\(`), "func _mpz_${1}_generic("},
+	{regexp.MustCompile(`func _mpz_div_qr\(`), "func _mpz_div_qr_generic("},
+	// The size fields of Xmpz_srcptr, and the expressions of their type, use
+	// mpzField, which is widened to int64 on amd64 by the mpsize64 build tag.
+	{regexp.MustCompile(`func\(\) int32( \{\s+if \(\w+\[0\]\.X_mp_size\) >= int32\(0\) \{\s+return \(\w+\[0\]\.X_mp_size\))`), "func() mpzField${1}"},
+	{regexp.MustCompile(`(return \(-\(\w+\[0\]\.X_mp_size\)\)\s+\}\(\) [<>=!]=? )int32\(`), "${1}mpzField("},
+	{regexp.MustCompile(`(X_mp_(?:alloc|size)\s+)int32\b`), "${1}mpzField"},
+	{regexp.MustCompile(`(X_mp_(?:alloc|size)\)+ (?:==|!=|>=|<=|<|>|\^) )int32\(`), "${1}mpzField("},
+	{regexp.MustCompile(`int32(\(\w+\) [<>=!]=? \(\w+\[0\]\.X_mp_(?:alloc|size)\))`), "mpzField${1}"},
+	{regexp.MustCompile(`(X_mp_(?:alloc|size) = )int32\(`), "${1}mpzField("},
+}
+
+func lib() {
 	rp := findRepo(repo)
 	if rp == "" {
 		log.Fatalf("repository not found: %v", rp)
@@ -380,7 +420,11 @@ func lib() {
 	fmt.Fprintf(&b, prologue, strings.TrimSpace(tidyComments(header(filepath.Join(rp, "mini-gmp.c")))))
 	macros(&b, ast[0])
 	b.Write(src)
-	b2, err := format.Source(re.ReplaceAll(b.Bytes(), []byte(" panic(crt.GoString(_msg)) ")))
+	src = b.Bytes()
+	for _, v := range tweaks {
+		src = v.re.ReplaceAll(src, []byte(v.repl))
+	}
+	b2, err := format.Source(src)
 	if err != nil {
 		b2 = b.Bytes()
 	}
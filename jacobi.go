@@ -0,0 +1,140 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"github.com/cznic/ccgo/crt"
+)
+
+// As in GMP, Xmpz_jacobi and Xmpz_legendre compute the Kronecker symbol, which
+// coincides with the Jacobi symbol for odd b and with the Legendre symbol for
+// odd prime b.
+
+// mpzLow returns the least significant limb of |x|.
+func mpzLow(x *[1]Xmpz_srcptr) limb {
+	if x[0].X_mp_size == 0 {
+		return 0
+	}
+
+	return *x[0].X_mp_d
+}
+
+// Xmpz_jacobi returns the Jacobi symbol (a/b). b must be odd.
+func Xmpz_jacobi(tls *crt.TLS, a, b *[1]Xmpz_srcptr) int32 { return Xmpz_kronecker(tls, a, b) }
+
+// Xmpz_legendre returns the Legendre symbol (a/p). p must be an odd positive
+// prime.
+func Xmpz_legendre(tls *crt.TLS, a, p *[1]Xmpz_srcptr) int32 { return Xmpz_kronecker(tls, a, p) }
+
+// Xmpz_kronecker returns the Kronecker symbol (a/b).
+func Xmpz_kronecker(tls *crt.TLS, a, b *[1]Xmpz_srcptr) int32 {
+	if b[0].X_mp_size == 0 {
+		if Xmpz_cmpabs_ui(tls, a, 1) == 0 {
+			return 1
+		}
+
+		return 0
+	}
+
+	if (mpzLow(a)|mpzLow(b))&1 == 0 {
+		return 0
+	}
+
+	var x, y [1]Xmpz_srcptr
+	Xmpz_init(tls, &x)
+	Xmpz_init(tls, &y)
+
+	defer func() {
+		Xmpz_clear(tls, &x)
+		Xmpz_clear(tls, &y)
+	}()
+
+	// (a/b) = (a/-1)(a/|b|), (a/-1) = -1 for negative a.
+	k := int32(1)
+	if b[0].X_mp_size < 0 && a[0].X_mp_size < 0 {
+		k = -k
+	}
+
+	// |b| = 2^v*y, y odd. a is odd if v != 0 and (a/2) = -1 for a = 3, 5
+	// (mod 8).
+	Xmpz_abs(tls, &y, b)
+	v := Xmpz_scan1(tls, &y, 0)
+	Xmpz_tdiv_q_2exp(tls, &y, &y, v)
+	if m := mpzLow(a) & 7; v&1 != 0 && (m == 3 || m == 5) {
+		k = -k
+	}
+
+	// (a/y) depends only on a mod y for odd positive y.
+	Xmpz_fdiv_r(tls, &x, a, &y)
+	return k * jacobi(tls, &x, &y)
+}
+
+// jacobi returns the Jacobi symbol (x/y), 0 <= x, y odd and positive, using the
+// binary algorithm. x and y are destroyed.
+func jacobi(tls *crt.TLS, x, y *[1]Xmpz_srcptr) int32 {
+	k := int32(1)
+	for x[0].X_mp_size != 0 {
+		// (2/y) = -1 for y = 3, 5 (mod 8).
+		v := Xmpz_scan1(tls, x, 0)
+		Xmpz_tdiv_q_2exp(tls, x, x, v)
+		if m := mpzLow(y) & 7; v&1 != 0 && (m == 3 || m == 5) {
+			k = -k
+		}
+
+		// Both x and y are odd. By quadratic reciprocity (x/y) = -(y/x) if
+		// x = y = 3 (mod 4), otherwise (x/y) = (y/x).
+		if Xmpz_cmp(tls, x, y) < 0 {
+			Xmpz_swap(tls, x, y)
+			if mpzLow(x)&mpzLow(y)&3 == 3 {
+				k = -k
+			}
+		}
+
+		// (x/y) = ((x-y)/y), x-y is even.
+		Xmpz_sub(tls, x, x, y)
+	}
+	// y is now gcd(x, y).
+	if Xmpz_cmp_ui(tls, y, 1) != 0 {
+		return 0
+	}
+
+	return k
+}
+
+// Xmpz_kronecker_si returns the Kronecker symbol (a/b).
+func Xmpz_kronecker_si(tls *crt.TLS, a *[1]Xmpz_srcptr, b long) int32 {
+	var t [1]Xmpz_srcptr
+	Xmpz_init_set_si(tls, &t, b)
+	r := Xmpz_kronecker(tls, a, &t)
+	Xmpz_clear(tls, &t)
+	return r
+}
+
+// Xmpz_kronecker_ui returns the Kronecker symbol (a/b).
+func Xmpz_kronecker_ui(tls *crt.TLS, a *[1]Xmpz_srcptr, b ulong) int32 {
+	var t [1]Xmpz_srcptr
+	Xmpz_init_set_ui(tls, &t, b)
+	r := Xmpz_kronecker(tls, a, &t)
+	Xmpz_clear(tls, &t)
+	return r
+}
+
+// Xmpz_si_kronecker returns the Kronecker symbol (a/b).
+func Xmpz_si_kronecker(tls *crt.TLS, a long, b *[1]Xmpz_srcptr) int32 {
+	var t [1]Xmpz_srcptr
+	Xmpz_init_set_si(tls, &t, a)
+	r := Xmpz_kronecker(tls, &t, b)
+	Xmpz_clear(tls, &t)
+	return r
+}
+
+// Xmpz_ui_kronecker returns the Kronecker symbol (a/b).
+func Xmpz_ui_kronecker(tls *crt.TLS, a ulong, b *[1]Xmpz_srcptr) int32 {
+	var t [1]Xmpz_srcptr
+	Xmpz_init_set_ui(tls, &t, a)
+	r := Xmpz_kronecker(tls, &t, b)
+	Xmpz_clear(tls, &t)
+	return r
+}
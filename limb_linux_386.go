@@ -0,0 +1,25 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+// The C types used by the generated code on linux/386.
+type (
+	limb   = uint32 // mp_limb_t
+	mpSize = int32  // mp_size_t
+	ulong  = uint32 // unsigned long
+	long   = int32  // long
+	sizeT  = uint32 // size_t
+
+	// mpzField is the type of the _mp_alloc and _mp_size fields of
+	// Xmpz_srcptr. The mpsize64 build tag has no effect on linux/386.
+	mpzField = int32
+)
+
+const limbBits = 32 // GMP_LIMB_BITS
+
+// maxLimbs is the maximum size of a value in limbs. Sizes in bits of the sum
+// of two sizes in limbs, computed by mini-gmp in unsigned long, must not
+// overflow.
+const maxLimbs = 1<<26 - 1
@@ -0,0 +1,16 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+// The C types used by the generated code on linux/amd64.
+type (
+	limb   = uint64 // mp_limb_t
+	mpSize = int64  // mp_size_t
+	ulong  = uint64 // unsigned long
+	long   = int64  // long
+	sizeT  = uint64 // size_t
+)
+
+const limbBits = 64 // GMP_LIMB_BITS
@@ -0,0 +1,663 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// LimitError reports an operation whose result would exceed the maximum size
+// of a value, see SetMaxBits and MaxWords. Functions exceeding the limit panic with a
+// *LimitError, use Try to obtain it as an error. The *_ctx variants of the
+// functions, like Xmpz_pow_ui_ctx, return it as an error instead.
+type LimitError struct {
+	Op   string // The mini-gmp name of the operation, like "mpz_pow_ui".
+	Bits uint64 // Lower bound of the size of the result in bits.
+	Max  uint64 // The limit in bits.
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: size of the result, at least %d bits, exceeds the limit of %d bits", e.Op, e.Bits, e.Max)
+}
+
+// SetMaxBits sets the maximum size of a value of the default context, in
+// bits, and returns the previous value. Zero, the default, means no limit.
+// See also Context.SetMaxBits.
+//
+// The limit is checked before the memory of a value grows and, to fail early,
+// before computing powers, factorials and shifts. It is enforced with a
+// granularity of a few limbs. An operation exceeding the limit panics with a
+// *LimitError. The panic does not leak memory: the temporary values of the
+// interrupted operation are freed while unwinding, so recovering from it, for
+// example using Try, leaves only the memory of the operands allocated. The
+// destination is a valid value afterwards, usually unmodified, but its value
+// is unspecified. The *_ctx variants of the functions bounded by the limit,
+// Xmpz_pow_ui_ctx, Xmpz_ui_pow_ui_ctx, Xmpz_mul_2exp_ctx, Xmpz_fac_ui_ctx,
+// Xmpz_bin_uiui_ctx and Xmpz_setbit_ctx, return the *LimitError instead of
+// panicking.
+//
+// Independently of the limit, a value cannot have more than MaxWords limbs.
+// All sizes are checked against MaxWords before they are stored in the size
+// fields of a value, an operation exceeding it panics with a *LimitError as
+// well.
+func SetMaxBits(n uint64) (old uint64) { return defaultContext.SetMaxBits(n) }
+
+// SetMaxBits sets the maximum size of a value of c, in bits, and returns the
+// previous value. Zero means no limit. See the SetMaxBits function.
+func (c *Context) SetMaxBits(n uint64) (old uint64) { return c.maxBits.Swap(n) }
+
+// Try calls f and returns the *LimitError f panicked with, if any. Other
+// panics are propagated. The operation interrupted by the *LimitError has
+// released its temporary values. Values initialized by f itself are not
+// cleared by Try, f should clear them using defer.
+func Try(f func()) (err error) {
+	defer recoverLimit(&err)
+
+	f()
+	return nil
+}
+
+// recoverLimit sets *err to the *LimitError the caller panicked with, if any.
+// Other panics are propagated. It must be deferred by the caller.
+func recoverLimit(err *error) {
+	if e := recover(); e != nil {
+		le, ok := e.(*LimitError)
+		if !ok {
+			panic(e)
+		}
+
+		*err = le
+	}
+}
+
+// checkBits panics if a result of at least n bits exceeds the limit of the
+// Context of tls.
+func checkBits(tls *crt.TLS, op string, n uint64) {
+	if max := contextOf(tls).maxBits.Load(); max != 0 && n > max {
+		panic(&LimitError{Op: op, Bits: n, Max: max})
+	}
+}
+
+// mulBits returns a*b, saturated.
+func mulBits(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	if hi != 0 {
+		return math.MaxUint64
+	}
+
+	return lo
+}
+
+// powBits returns a lower bound of the bit size of x^e, where x has n bits.
+func powBits(n, e uint64) uint64 {
+	if n <= 1 || e == 0 {
+		return 1
+	}
+
+	return mulBits(n-1, e) + 1
+}
+
+// checkLimbs panics if allocating n limbs for a value exceeds the limit of the
+// Context of tls. Allocations anticipate carries and round the sizes of
+// products, two limbs more than needed for the limit are allowed.
+func checkLimbs(tls *crt.TLS, op string, n uint64) {
+	checkSize(op, n)
+	if n > 2 {
+		checkBits(tls, op, mulBits(n-2, limbBits))
+	}
+}
+
+// checkSize panics if a value of n limbs exceeds MaxWords.
+func checkSize(op string, n uint64) {
+	if n > maxLimbs {
+		panic(&LimitError{Op: op, Bits: mulBits(n, limbBits), Max: maxLimbs * limbBits})
+	}
+}
+
+func _mpz_realloc(tls *crt.TLS, r *[1]Xmpz_srcptr, size mpSize) *limb {
+	checkLimbs(tls, "mpz_realloc", uint64(size))
+	return _mpz_realloc_generic(tls, r, size)
+}
+
+// Xmpz_limbs_finish sets the size of x to xs, normalized, after its limbs were
+// written by Xmpz_limbs_write or Xmpz_limbs_modify.
+func Xmpz_limbs_finish(tls *crt.TLS, x *[1]Xmpz_srcptr, xs mpSize) {
+	n := uint64(xs)
+	if xs < 0 {
+		n = -n
+	}
+	checkSize("mpz_limbs_finish", n)
+	_mpz_limbs_finish_generic(tls, x, xs)
+}
+
+// Xmpz_init2 initializes r to zero with space for n bits.
+func Xmpz_init2(tls *crt.TLS, r *[1]Xmpz_srcptr, n ulong) {
+	checkLimbs(tls, "mpz_init2", (uint64(n)+limbBits-1)/limbBits)
+	_mpz_init2_generic(tls, r, n)
+}
+
+// Xmpz_pow_ui sets r to b^e.
+func Xmpz_pow_ui(tls *crt.TLS, r, b *[1]Xmpz_srcptr, e ulong) {
+	if err := Xmpz_pow_ui_ctx(tls, context.Background(), r, b, e); err != nil {
+		panic(err) // A *LimitError.
+	}
+}
+
+// Xmpz_ui_pow_ui sets r to b^e.
+func Xmpz_ui_pow_ui(tls *crt.TLS, r *[1]Xmpz_srcptr, b, e ulong) {
+	checkBits(tls, "mpz_ui_pow_ui", powBits(uint64(bits.Len64(uint64(b))), uint64(e)))
+	_mpz_ui_pow_ui_generic(tls, r, b, e)
+}
+
+// Xmpz_mul_2exp sets r to u*2^n.
+func Xmpz_mul_2exp(tls *crt.TLS, r, u *[1]Xmpz_srcptr, n ulong) {
+	if u[0].X_mp_size != 0 {
+		checkBits(tls, "mpz_mul_2exp", uint64(mpzBitLen(u))+uint64(n))
+	}
+	_mpz_mul_2exp_generic(tls, r, u, n)
+}
+
+// facBits returns a lower bound of the bit size of n!.
+func facBits(n ulong) uint64 {
+	// n! >= (n/e)^n
+	f := float64(n) * math.Log2(float64(n)/math.E)
+	switch {
+	case f < 1:
+		return 1
+	case f >= math.MaxUint64:
+		return math.MaxUint64
+	default:
+		return uint64(f)
+	}
+}
+
+// Xmpz_fac_ui sets r to n!.
+func Xmpz_fac_ui(tls *crt.TLS, r *[1]Xmpz_srcptr, n ulong) {
+	checkBits(tls, "mpz_fac_ui", facBits(n))
+	_mpz_fac_ui_generic(tls, r, n)
+}
+
+// Xmpz_setbit sets bit i of d.
+func Xmpz_setbit(tls *crt.TLS, d *[1]Xmpz_srcptr, i ulong) {
+	// Negative values have all the bits above their size set already.
+	if d[0].X_mp_size >= 0 && uint64(i) >= uint64(mpzBitLen(d)) {
+		checkBits(tls, "mpz_setbit", uint64(i)+1)
+	}
+	_mpz_setbit_generic(tls, d, i)
+}
+
+// Xmpz_bin_uiui sets r to the binomial coefficient of n and k.
+func Xmpz_bin_uiui(tls *crt.TLS, r *[1]Xmpz_srcptr, n, k ulong) {
+	if err := Xmpz_bin_uiui_ctx(tls, context.Background(), r, n, k); err != nil {
+		panic(err) // A *LimitError.
+	}
+}
+
+// Xmpz_addmul sets r to r+u*v.
+func Xmpz_addmul(tls *crt.TLS, r, u, v *[1]Xmpz_srcptr) {
+	var t [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+
+	defer Xmpz_clear(tls, &t)
+
+	Xmpz_mul(tls, &t, u, v)
+	Xmpz_add(tls, r, r, &t)
+}
+
+// Xmpz_submul sets r to r-u*v.
+func Xmpz_submul(tls *crt.TLS, r, u, v *[1]Xmpz_srcptr) {
+	var t [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+
+	defer Xmpz_clear(tls, &t)
+
+	Xmpz_mul(tls, &t, u, v)
+	Xmpz_sub(tls, r, r, &t)
+}
+
+// Xmpz_addmul_ui sets r to r+u*v.
+func Xmpz_addmul_ui(tls *crt.TLS, r, u *[1]Xmpz_srcptr, v ulong) {
+	var t [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+
+	defer Xmpz_clear(tls, &t)
+
+	Xmpz_mul_ui(tls, &t, u, v)
+	Xmpz_add(tls, r, r, &t)
+}
+
+// Xmpz_submul_ui sets r to r-u*v.
+func Xmpz_submul_ui(tls *crt.TLS, r, u *[1]Xmpz_srcptr, v ulong) {
+	var t [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+
+	defer Xmpz_clear(tls, &t)
+
+	Xmpz_mul_ui(tls, &t, u, v)
+	Xmpz_sub(tls, r, r, &t)
+}
+
+// Xmpz_lcm sets r to the least common multiple of u and v, which is
+// non-negative.
+func Xmpz_lcm(tls *crt.TLS, r, u, v *[1]Xmpz_srcptr) {
+	if u[0].X_mp_size == 0 || v[0].X_mp_size == 0 {
+		r[0].X_mp_size = 0
+		return
+	}
+
+	var g [1]Xmpz_srcptr
+	Xmpz_init(tls, &g)
+
+	defer Xmpz_clear(tls, &g)
+
+	Xmpz_gcd(tls, &g, u, v)
+	Xmpz_divexact(tls, &g, u, &g)
+	Xmpz_mul(tls, r, &g, v)
+	Xmpz_abs(tls, r, r)
+}
+
+// Xmpz_gcd sets g to the greatest common divisor of u and v, which is
+// non-negative.
+func Xmpz_gcd(tls *crt.TLS, g, u, v *[1]Xmpz_srcptr) {
+	if u[0].X_mp_size == 0 {
+		Xmpz_abs(tls, g, v)
+		return
+	}
+
+	if v[0].X_mp_size == 0 {
+		Xmpz_abs(tls, g, u)
+		return
+	}
+
+	var tu, tv [1]Xmpz_srcptr
+	for _, p := range []*[1]Xmpz_srcptr{&tu, &tv} {
+		Xmpz_init(tls, p)
+
+		defer Xmpz_clear(tls, p)
+	}
+
+	Xmpz_abs(tls, &tu, u)
+	uz := _mpz_make_odd(tls, &tu)
+	Xmpz_abs(tls, &tv, v)
+	vz := _mpz_make_odd(tls, &tv)
+	gz := min(uz, vz)
+	if tu[0].X_mp_size < tv[0].X_mp_size {
+		Xmpz_swap(tls, &tu, &tv)
+	}
+	Xmpz_tdiv_r(tls, &tu, &tu, &tv)
+	switch {
+	case tu[0].X_mp_size == 0:
+		Xmpz_swap(tls, g, &tv)
+	default:
+		for {
+			_mpz_make_odd(tls, &tu)
+			c := Xmpz_cmp(tls, &tu, &tv)
+			if c == 0 {
+				Xmpz_swap(tls, g, &tu)
+				break
+			}
+
+			if c < 0 {
+				Xmpz_swap(tls, &tu, &tv)
+			}
+			if tv[0].X_mp_size == 1 {
+				vl := *tv[0].X_mp_d
+				ul := Xmpz_tdiv_ui(tls, &tu, vl)
+				Xmpz_set_ui(tls, g, _mpn_gcd_11(tls, ul, vl))
+				break
+			}
+
+			Xmpz_sub(tls, &tu, &tu, &tv)
+		}
+	}
+	Xmpz_mul_2exp(tls, g, g, gz)
+}
+
+// Xmpz_gcdext sets g to the greatest common divisor of u and v, which is
+// non-negative, and s and t, if not nil, to cofactors such that g = s*u + t*v.
+func Xmpz_gcdext(tls *crt.TLS, g, s, t, u, v *[1]Xmpz_srcptr) {
+	if u[0].X_mp_size == 0 {
+		sign := Xmpz_sgn(tls, v)
+		Xmpz_abs(tls, g, v)
+		if s != nil {
+			Xmpz_set_ui(tls, s, 0)
+		}
+		if t != nil {
+			Xmpz_set_si(tls, t, long(sign))
+		}
+		return
+	}
+
+	if v[0].X_mp_size == 0 {
+		sign := Xmpz_sgn(tls, u)
+		Xmpz_abs(tls, g, u)
+		if s != nil {
+			Xmpz_set_si(tls, s, long(sign))
+		}
+		if t != nil {
+			Xmpz_set_ui(tls, t, 0)
+		}
+		return
+	}
+
+	var tu, tv, s0, s1, t0, t1 [1]Xmpz_srcptr
+	for _, p := range []*[1]Xmpz_srcptr{&tu, &tv, &s0, &s1, &t0, &t1} {
+		Xmpz_init(tls, p)
+
+		defer Xmpz_clear(tls, p)
+	}
+
+	Xmpz_abs(tls, &tu, u)
+	uz := _mpz_make_odd(tls, &tu)
+	Xmpz_abs(tls, &tv, v)
+	vz := _mpz_make_odd(tls, &tv)
+	gz := min(uz, vz)
+	uz -= gz
+	vz -= gz
+	if tu[0].X_mp_size < tv[0].X_mp_size {
+		Xmpz_swap(tls, &tu, &tv)
+		u, v = v, u
+		s, t = t, s
+		uz, vz = vz, uz
+	}
+
+	// Maintain u = t0*tu + t1*tv and v = s0*tu + s1*tv, up to the powers
+	// of two removed.
+	Xmpz_setbit(tls, &t0, uz)
+	Xmpz_tdiv_qr(tls, &t1, &tu, &tu, &tv)
+	Xmpz_mul_2exp(tls, &t1, &t1, uz)
+	Xmpz_setbit(tls, &s1, vz)
+	power := uz + vz
+	if tu[0].X_mp_size > 0 {
+		shift := _mpz_make_odd(tls, &tu)
+		Xmpz_mul_2exp(tls, &t0, &t0, shift)
+		Xmpz_mul_2exp(tls, &s0, &s0, shift)
+		power += shift
+		for {
+			c := Xmpz_cmp(tls, &tu, &tv)
+			if c == 0 {
+				break
+			}
+
+			switch {
+			case c < 0:
+				Xmpz_sub(tls, &tv, &tv, &tu)
+				Xmpz_add(tls, &t0, &t0, &t1)
+				Xmpz_add(tls, &s0, &s0, &s1)
+				shift = _mpz_make_odd(tls, &tv)
+				Xmpz_mul_2exp(tls, &t1, &t1, shift)
+				Xmpz_mul_2exp(tls, &s1, &s1, shift)
+			default:
+				Xmpz_sub(tls, &tu, &tu, &tv)
+				Xmpz_add(tls, &t1, &t0, &t1)
+				Xmpz_add(tls, &s1, &s0, &s1)
+				shift = _mpz_make_odd(tls, &tu)
+				Xmpz_mul_2exp(tls, &t0, &t0, shift)
+				Xmpz_mul_2exp(tls, &s0, &s0, shift)
+			}
+			power += shift
+		}
+	}
+
+	// tv is the odd part of the gcd and 2^power*tv = -s0*u + t0*v. The
+	// factors of two are eliminated one at a time, keeping the cofactors
+	// small using s1 = v/g and t1 = u/g.
+	Xmpz_mul_2exp(tls, &tv, &tv, gz)
+	Xmpz_neg(tls, &s0, &s0)
+	Xmpz_divexact(tls, &s1, v, &tv)
+	Xmpz_abs(tls, &s1, &s1)
+	Xmpz_divexact(tls, &t1, u, &tv)
+	Xmpz_abs(tls, &t1, &t1)
+	for ; power > 0; power-- {
+		if mpzLow(&s0)&1 != 0 || mpzLow(&t0)&1 != 0 {
+			Xmpz_sub(tls, &s0, &s0, &s1)
+			Xmpz_add(tls, &t0, &t0, &t1)
+		}
+		Xmpz_divexact_ui(tls, &s0, &s0, 2)
+		Xmpz_divexact_ui(tls, &t0, &t0, 2)
+	}
+
+	// Arrange for |s| < |u|/2g.
+	Xmpz_add(tls, &s1, &s0, &s1)
+	if Xmpz_cmpabs(tls, &s0, &s1) > 0 {
+		Xmpz_swap(tls, &s0, &s1)
+		Xmpz_sub(tls, &t0, &t0, &t1)
+	}
+	if u[0].X_mp_size < 0 {
+		Xmpz_neg(tls, &s0, &s0)
+	}
+	if v[0].X_mp_size < 0 {
+		Xmpz_neg(tls, &t0, &t0)
+	}
+	Xmpz_swap(tls, g, &tv)
+	if s != nil {
+		Xmpz_swap(tls, s, &s0)
+	}
+	if t != nil {
+		Xmpz_swap(tls, t, &t0)
+	}
+}
+
+// Xmpz_invert sets r to the inverse of u modulo m, in [0, |m|), and returns 1.
+// If there is no inverse, r is not modified and the result is 0.
+func Xmpz_invert(tls *crt.TLS, r, u, m *[1]Xmpz_srcptr) int32 {
+	if u[0].X_mp_size == 0 || Xmpz_cmpabs_ui(tls, m, 1) <= 0 {
+		return 0
+	}
+
+	var g, tr [1]Xmpz_srcptr
+	for _, p := range []*[1]Xmpz_srcptr{&g, &tr} {
+		Xmpz_init(tls, p)
+
+		defer Xmpz_clear(tls, p)
+	}
+
+	Xmpz_gcdext(tls, &g, &tr, nil, u, m)
+	if Xmpz_cmp_ui(tls, &g, 1) != 0 {
+		return 0
+	}
+
+	if tr[0].X_mp_size < 0 {
+		switch {
+		case m[0].X_mp_size >= 0:
+			Xmpz_add(tls, &tr, &tr, m)
+		default:
+			Xmpz_sub(tls, &tr, &tr, m)
+		}
+	}
+	Xmpz_swap(tls, r, &tr)
+	return 1
+}
+
+// Xmpz_root sets x, if not nil, to the z-th root of y, truncated, and returns
+// whether the root is exact.
+func Xmpz_root(tls *crt.TLS, x, y *[1]Xmpz_srcptr, z ulong) int32 {
+	var r [1]Xmpz_srcptr
+	Xmpz_init(tls, &r)
+
+	defer Xmpz_clear(tls, &r)
+
+	Xmpz_rootrem(tls, x, &r, y, z)
+	return bool2int(r[0].X_mp_size == 0)
+}
+
+// Xmpz_rootrem sets x, if not nil, to the z-th root of y, truncated, and r, if
+// not nil, to the remainder y-x^z.
+func Xmpz_rootrem(tls *crt.TLS, x, r, y *[1]Xmpz_srcptr, z ulong) {
+	neg := y[0].X_mp_size < 0
+	if neg && z&1 == 0 {
+		_gmp_die(tls, str(96)) // mpz_rootrem: Negative argument, with even root.
+	}
+
+	if z == 0 {
+		_gmp_die(tls, str(144)) // mpz_rootrem: Zeroth root.
+	}
+
+	if Xmpz_cmpabs_ui(tls, y, 1) <= 0 {
+		if x != nil {
+			Xmpz_set(tls, x, y)
+		}
+		if r != nil {
+			r[0].X_mp_size = 0
+		}
+		return
+	}
+
+	var t, u, v [1]Xmpz_srcptr
+	for _, p := range []*[1]Xmpz_srcptr{&t, &u, &v} {
+		Xmpz_init(tls, p)
+
+		defer Xmpz_clear(tls, p)
+	}
+
+	// Newton's iteration, starting above the root.
+	Xmpz_setbit(tls, &t, ulong(Xmpz_sizeinbase(tls, y, 2))/z+1)
+	switch {
+	case z == 2:
+		for {
+			Xmpz_swap(tls, &u, &t)
+			Xmpz_tdiv_q(tls, &t, y, &u)
+			Xmpz_add(tls, &t, &t, &u)
+			Xmpz_tdiv_q_2exp(tls, &t, &t, 1)
+			if Xmpz_cmpabs(tls, &t, &u) >= 0 {
+				break
+			}
+		}
+	default:
+		if neg {
+			Xmpz_neg(tls, &t, &t)
+		}
+		for {
+			Xmpz_swap(tls, &u, &t)
+			Xmpz_pow_ui(tls, &t, &u, z-1)
+			Xmpz_tdiv_q(tls, &t, y, &t)
+			Xmpz_mul_ui(tls, &v, &u, z-1)
+			Xmpz_add(tls, &t, &t, &v)
+			Xmpz_tdiv_q_ui(tls, &t, &t, z)
+			if Xmpz_cmpabs(tls, &t, &u) >= 0 {
+				break
+			}
+		}
+	}
+	if r != nil {
+		Xmpz_pow_ui(tls, &t, &u, z)
+		Xmpz_sub(tls, r, y, &t)
+	}
+	if x != nil {
+		Xmpz_swap(tls, x, &u)
+	}
+}
+
+// _mpz_div_qr sets q and r, if not nil, to the quotient and the remainder of
+// n/d, the quotient rounded according to mode, and returns 1 if the remainder
+// is not zero.
+func _mpz_div_qr(tls *crt.TLS, q, r, n, d *[1]Xmpz_srcptr, mode int32) int32 {
+	const (
+		floor = 0 // GMP_DIV_FLOOR
+		ceil  = 1 // GMP_DIV_CEIL
+	)
+
+	if d[0].X_mp_size == 0 {
+		_gmp_die(tls, str(170)) // mpz_div_qr: Divide by zero.
+	}
+
+	if n[0].X_mp_size == 0 {
+		if q != nil {
+			q[0].X_mp_size = 0
+		}
+		if r != nil {
+			r[0].X_mp_size = 0
+		}
+		return 0
+	}
+
+	nn, dn := mpzAbsSize(n), mpzAbsSize(d)
+	qneg := (n[0].X_mp_size < 0) != (d[0].X_mp_size < 0)
+	if nn < dn {
+		switch {
+		case mode == ceil && !qneg:
+			if r != nil {
+				Xmpz_sub(tls, r, n, d)
+			}
+			if q != nil {
+				Xmpz_set_ui(tls, q, 1)
+			}
+		case mode == floor && qneg:
+			if r != nil {
+				Xmpz_add(tls, r, n, d)
+			}
+			if q != nil {
+				Xmpz_set_si(tls, q, -1)
+			}
+		default:
+			if r != nil {
+				Xmpz_set(tls, r, n)
+			}
+			if q != nil {
+				q[0].X_mp_size = 0
+			}
+		}
+		return 1
+	}
+
+	var tq, tr [1]Xmpz_srcptr
+	for _, p := range []*[1]Xmpz_srcptr{&tq, &tr} {
+		Xmpz_init(tls, p)
+
+		defer Xmpz_clear(tls, p)
+	}
+
+	Xmpz_set(tls, &tr, n)
+	qn := nn - dn + 1
+	var qp *limb
+	if q != nil {
+		qp = _mpz_realloc(tls, &tq, qn)
+	}
+	_mpn_div_qr(tls, qp, tr[0].X_mp_d, nn, d[0].X_mp_d, dn)
+	if qp != nil {
+		if limbs(qp, qn)[qn-1] == 0 {
+			qn--
+		}
+		if qneg {
+			qn = -qn
+		}
+		tq[0].X_mp_size = mpzField(qn)
+	}
+	rn := _mpn_normalized_size(tls, tr[0].X_mp_d, dn)
+	tr[0].X_mp_size = mpzField(rn)
+	if n[0].X_mp_size < 0 {
+		tr[0].X_mp_size = -tr[0].X_mp_size
+	}
+	switch {
+	case rn == 0:
+		// Exact.
+	case mode == floor && qneg:
+		if q != nil {
+			Xmpz_sub_ui(tls, &tq, &tq, 1)
+		}
+		if r != nil {
+			Xmpz_add(tls, &tr, &tr, d)
+		}
+	case mode == ceil && !qneg:
+		if q != nil {
+			Xmpz_add_ui(tls, &tq, &tq, 1)
+		}
+		if r != nil {
+			Xmpz_sub(tls, &tr, &tr, d)
+		}
+	}
+	if q != nil {
+		Xmpz_swap(tls, &tq, q)
+	}
+	if r != nil {
+		Xmpz_swap(tls, &tr, r)
+	}
+	return bool2int(rn != 0)
+}
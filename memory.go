@@ -0,0 +1,246 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// memFuncs is an immutable set of memory functions.
+type memFuncs struct {
+	alloc   func(*crt.TLS, sizeT) unsafe.Pointer
+	realloc func(*crt.TLS, unsafe.Pointer, sizeT, sizeT) unsafe.Pointer
+	free    func(*crt.TLS, unsafe.Pointer, sizeT)
+	secure  bool
+}
+
+var defaultMemFuncs = &memFuncs{
+	alloc:   _gmp_default_alloc,
+	realloc: _gmp_default_realloc,
+	free:    _gmp_default_free,
+}
+
+// memory is the allocator of a Context.
+type memory struct {
+	funcs atomic.Value // *memFuncs
+	mu    sync.Mutex   // Serializes updates of funcs.
+
+	// sizes records the sizes of the blocks allocated in the secure mode.
+	// The C code passes zero as the size of the blocks it frees or
+	// reallocates.
+	sizes   map[unsafe.Pointer]sizeT // Guarded by sizesMu.
+	sizesMu sync.Mutex
+}
+
+func (m *memory) load() *memFuncs {
+	if f, _ := m.funcs.Load().(*memFuncs); f != nil {
+		return f
+	}
+
+	return defaultMemFuncs
+}
+
+func (m *memory) setFuncs(alloc func(*crt.TLS, sizeT) unsafe.Pointer, realloc func(*crt.TLS, unsafe.Pointer, sizeT, sizeT) unsafe.Pointer, free func(*crt.TLS, unsafe.Pointer, sizeT)) {
+	if alloc == nil {
+		alloc = _gmp_default_alloc
+	}
+	if realloc == nil {
+		realloc = _gmp_default_realloc
+	}
+	if free == nil {
+		free = _gmp_default_free
+	}
+
+	m.mu.Lock()
+	f := *m.load()
+	f.alloc = alloc
+	f.realloc = realloc
+	f.free = free
+	m.funcs.Store(&f)
+	m.mu.Unlock()
+}
+
+func (m *memory) setSecure(on bool) (old bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f := *m.load()
+	old = f.secure
+	f.secure = on
+	m.sizesMu.Lock()
+	switch {
+	case on && m.sizes == nil:
+		m.sizes = map[unsafe.Pointer]sizeT{}
+	case !on:
+		m.sizes = nil
+	}
+	m.sizesMu.Unlock()
+	m.funcs.Store(&f)
+	return old
+}
+
+// wipe zeroes n bytes at p. The stores must not be removed by the compiler
+// even though the memory is not read afterwards: wipe is never inlined into
+// its callers, which free the memory next, and runtime.KeepAlive makes the
+// zeroed buffer live after the stores.
+//
+//go:noinline
+func wipe(p unsafe.Pointer, n sizeT) {
+	if p == nil || n == 0 {
+		return
+	}
+
+	b := unsafe.Slice((*byte)(p), n)
+	for i := range b {
+		b[i] = 0
+	}
+	runtime.KeepAlive(b)
+}
+
+// wipeLimbs zeroes the Go heap buffers s, holding copies of operands or of
+// intermediate results, if the secure memory mode of the Context tls belongs
+// to is on.
+func wipeLimbs(tls *crt.TLS, s ...[]limb) {
+	if !contextOf(tls).mem.load().secure {
+		return
+	}
+
+	for _, v := range s {
+		if len(v) != 0 {
+			wipe(unsafe.Pointer(&v[0]), sizeT(len(v))*sizeT(unsafe.Sizeof(v[0])))
+		}
+	}
+}
+
+func (m *memory) allocate(tls *crt.TLS, size sizeT) unsafe.Pointer {
+	f := m.load()
+	p := f.alloc(tls, size)
+	if f.secure {
+		m.sizesMu.Lock()
+		if m.sizes != nil {
+			m.sizes[p] = size
+		}
+		m.sizesMu.Unlock()
+	}
+	return p
+}
+
+func (m *memory) reallocate(tls *crt.TLS, old unsafe.Pointer, oldSize, newSize sizeT) unsafe.Pointer {
+	f := m.load()
+	if !f.secure {
+		return f.realloc(tls, old, oldSize, newSize)
+	}
+
+	m.sizesMu.Lock()
+	size, ok := m.sizes[old]
+	m.sizesMu.Unlock()
+	if !ok {
+		// Not allocated in the secure mode, the size is not known.
+		p := f.realloc(tls, old, oldSize, newSize)
+		m.sizesMu.Lock()
+		if m.sizes != nil {
+			m.sizes[p] = newSize
+		}
+		m.sizesMu.Unlock()
+		return p
+	}
+
+	p := f.alloc(tls, newSize)
+	n := size
+	if newSize < n {
+		n = newSize
+	}
+	if n != 0 {
+		copy(unsafe.Slice((*byte)(p), n), unsafe.Slice((*byte)(old), n))
+	}
+	wipe(old, size)
+
+	m.sizesMu.Lock()
+	delete(m.sizes, old)
+	if m.sizes != nil {
+		m.sizes[p] = newSize
+	}
+	m.sizesMu.Unlock()
+
+	f.free(tls, old, size)
+	return p
+}
+
+func (m *memory) release(tls *crt.TLS, p unsafe.Pointer, size sizeT) {
+	f := m.load()
+	if f.secure {
+		m.sizesMu.Lock()
+		if n, ok := m.sizes[p]; ok {
+			size = n
+			delete(m.sizes, p)
+			wipe(p, size)
+		}
+		m.sizesMu.Unlock()
+	}
+	f.free(tls, p, size)
+}
+
+// The C code allocates memory using the functions below. They use the
+// allocator of the Context tls belongs to.
+
+func _gmp_allocate_func(tls *crt.TLS, size sizeT) unsafe.Pointer {
+	return contextOf(tls).mem.allocate(tls, size)
+}
+
+func _gmp_reallocate_func(tls *crt.TLS, old unsafe.Pointer, oldSize, newSize sizeT) unsafe.Pointer {
+	return contextOf(tls).mem.reallocate(tls, old, oldSize, newSize)
+}
+
+func _gmp_free_func(tls *crt.TLS, p unsafe.Pointer, size sizeT) {
+	contextOf(tls).mem.release(tls, p, size)
+}
+
+// Xmp_set_memory_functions sets the functions used to allocate, reallocate and
+// free memory by the Context tls belongs to. A tls not created by NewContext
+// selects the default context. Nil arguments select the respective default
+// function.
+func Xmp_set_memory_functions(tls *crt.TLS, allocFunc func(*crt.TLS, sizeT) unsafe.Pointer, reallocFunc func(*crt.TLS, unsafe.Pointer, sizeT, sizeT) unsafe.Pointer, freeFunc func(*crt.TLS, unsafe.Pointer, sizeT)) {
+	contextOf(tls).mem.setFuncs(allocFunc, reallocFunc, freeFunc)
+}
+
+// Xmp_get_memory_functions returns the memory functions of the Context tls
+// belongs to. Nil arguments are ignored.
+//
+// The functions returned are those passed to Xmp_set_memory_functions, the
+// secure memory mode does not apply to them. Memory handed over to the
+// caller, like the strings produced by Xmpz_get_str, must be wiped by the
+// caller before freeing it, if needed. All of the block must be wiped, not
+// only the string, Xmpz_get_str allocates Xmpz_sizeinbase+2 bytes.
+func Xmp_get_memory_functions(tls *crt.TLS, allocFunc *func(*crt.TLS, sizeT) unsafe.Pointer, reallocFunc *func(*crt.TLS, unsafe.Pointer, sizeT, sizeT) unsafe.Pointer, freeFunc *func(*crt.TLS, unsafe.Pointer, sizeT)) {
+	f := contextOf(tls).mem.load()
+	if allocFunc != nil {
+		*allocFunc = f.alloc
+	}
+	if reallocFunc != nil {
+		*reallocFunc = f.realloc
+	}
+	if freeFunc != nil {
+		*freeFunc = f.free
+	}
+}
+
+// SetSecureMemory turns the secure memory mode of the default context on or
+// off and returns the previous setting. It is off by default. See also
+// Context.SetSecureMemory.
+//
+// In the secure mode every block of memory allocated by this package is
+// zeroed before it is freed. Reallocation, including shrinking, is performed
+// by allocating a new block, copying the contents and wiping and freeing the
+// old block, so no copy of the data is left behind in released memory.
+//
+// Only the blocks allocated while the secure mode is on are wiped. The scratch
+// buffers parallel multiplication and Xmpz_powm allocate from the Go heap for
+// partial products and powers are wiped as well.
+func SetSecureMemory(on bool) (old bool) { return defaultContext.mem.setSecure(on) }
@@ -231,7 +231,7 @@ const (
 
 var Xstderr unsafe.Pointer
 
-func Xmp_set_memory_functions(tls *crt.TLS, _alloc_func func(*crt.TLS, uint32) unsafe.Pointer, _realloc_func func(*crt.TLS, unsafe.Pointer, uint32, uint32) unsafe.Pointer, _free_func func(*crt.TLS, unsafe.Pointer, uint32)) {
+func _mp_set_memory_functions_generic(tls *crt.TLS, _alloc_func func(*crt.TLS, uint32) unsafe.Pointer, _realloc_func func(*crt.TLS, unsafe.Pointer, uint32, uint32) unsafe.Pointer, _free_func func(*crt.TLS, unsafe.Pointer, uint32)) {
 	if _alloc_func == nil {
 		_alloc_func = _gmp_default_alloc
 	}
@@ -241,12 +241,12 @@ func Xmp_set_memory_functions(tls *crt.TLS, _alloc_func func(*crt.TLS, uint32) u
 	if _free_func == nil {
 		_free_func = _gmp_default_free
 	}
-	_gmp_allocate_func = _alloc_func
-	bug20530(_gmp_allocate_func)
-	_gmp_reallocate_func = _realloc_func
-	bug20530(_gmp_reallocate_func)
-	_gmp_free_func = _free_func
-	bug20530(_gmp_free_func)
+	_gmp_allocate_func_generic = _alloc_func
+	bug20530(_gmp_allocate_func_generic)
+	_gmp_reallocate_func_generic = _realloc_func
+	bug20530(_gmp_reallocate_func_generic)
+	_gmp_free_func_generic = _free_func
+	bug20530(_gmp_free_func_generic)
 }
 
 func _gmp_default_alloc(tls *crt.TLS, _size uint32) (r0 unsafe.Pointer) {
@@ -276,25 +276,25 @@ func _gmp_default_free(tls *crt.TLS, _p unsafe.Pointer, _size uint32) {
 	crt.Xfree(tls, _p)
 }
 
-var _gmp_allocate_func func(*crt.TLS, uint32) unsafe.Pointer
+var _gmp_allocate_func_generic func(*crt.TLS, uint32) unsafe.Pointer
 
 func init() {
-	_gmp_allocate_func = _gmp_default_alloc
+	_gmp_allocate_func_generic = _gmp_default_alloc
 }
 
-var _gmp_reallocate_func func(*crt.TLS, unsafe.Pointer, uint32, uint32) unsafe.Pointer
+var _gmp_reallocate_func_generic func(*crt.TLS, unsafe.Pointer, uint32, uint32) unsafe.Pointer
 
 func init() {
-	_gmp_reallocate_func = _gmp_default_realloc
+	_gmp_reallocate_func_generic = _gmp_default_realloc
 }
 
-var _gmp_free_func func(*crt.TLS, unsafe.Pointer, uint32)
+var _gmp_free_func_generic func(*crt.TLS, unsafe.Pointer, uint32)
 
 func init() {
-	_gmp_free_func = _gmp_default_free
+	_gmp_free_func_generic = _gmp_default_free
 }
 
-func Xmp_get_memory_functions(tls *crt.TLS, _alloc_func *func(*crt.TLS, uint32) unsafe.Pointer, _realloc_func *func(*crt.TLS, unsafe.Pointer, uint32, uint32) unsafe.Pointer, _free_func *func(*crt.TLS, unsafe.Pointer, uint32)) {
+func _mp_get_memory_functions_generic(tls *crt.TLS, _alloc_func *func(*crt.TLS, uint32) unsafe.Pointer, _realloc_func *func(*crt.TLS, unsafe.Pointer, uint32, uint32) unsafe.Pointer, _free_func *func(*crt.TLS, unsafe.Pointer, uint32)) {
 	if _alloc_func != nil {
 		*_alloc_func = _gmp_allocate_func
 	}
@@ -387,7 +387,7 @@ _0:
 	return _b
 }
 
-func Xmpn_add_n(tls *crt.TLS, _rp *uint32, _ap *uint32, _bp *uint32, _n int32) (r0 uint32) {
+func _mpn_add_n_generic(tls *crt.TLS, _rp *uint32, _ap *uint32, _bp *uint32, _n int32) (r0 uint32) {
 	var _i int32
 	var _cy, _1_a, _1_b, _1_r uint32
 	*func() *uint32 { _i = int32(0); return &_cy }() = 0
@@ -434,7 +434,7 @@ _0:
 	return _b
 }
 
-func Xmpn_sub_n(tls *crt.TLS, _rp *uint32, _ap *uint32, _bp *uint32, _n int32) (r0 uint32) {
+func _mpn_sub_n_generic(tls *crt.TLS, _rp *uint32, _ap *uint32, _bp *uint32, _n int32) (r0 uint32) {
 	var _i int32
 	var _cy, _1_a, _1_b uint32
 	*func() *uint32 { _i = int32(0); return &_cy }() = 0
@@ -464,7 +464,7 @@ func Xmpn_sub(tls *crt.TLS, _rp *uint32, _ap *uint32, _an int32, _bp *uint32, _b
 	return _cy
 }
 
-func Xmpn_mul_1(tls *crt.TLS, _rp *uint32, _up *uint32, _n int32, _vl uint32) (r0 uint32) {
+func _mpn_mul_1_generic(tls *crt.TLS, _rp *uint32, _up *uint32, _n int32, _vl uint32) (r0 uint32) {
 	var _ul, _cl, _hpl, _lpl, _2___x0, _2___x1, _2___x2, _2___x3, _2___ul, _2___vl, _2___uh, _2___vh, _2___u, _2___v uint32
 
 	_cl = 0
@@ -496,7 +496,7 @@ _0:
 	return _cl
 }
 
-func Xmpn_addmul_1(tls *crt.TLS, _rp *uint32, _up *uint32, _n int32, _vl uint32) (r0 uint32) {
+func _mpn_addmul_1_generic(tls *crt.TLS, _rp *uint32, _up *uint32, _n int32, _vl uint32) (r0 uint32) {
 	var _ul, _cl, _hpl, _lpl, _rl, _2___x0, _2___x1, _2___x2, _2___x3, _2___ul, _2___vl, _2___uh, _2___vh, _2___u, _2___v uint32
 
 	_cl = 0
@@ -531,7 +531,7 @@ _0:
 	return _cl
 }
 
-func Xmpn_submul_1(tls *crt.TLS, _rp *uint32, _up *uint32, _n int32, _vl uint32) (r0 uint32) {
+func _mpn_submul_1_generic(tls *crt.TLS, _rp *uint32, _up *uint32, _n int32, _vl uint32) (r0 uint32) {
 	var _ul, _cl, _hpl, _lpl, _rl, _2___x0, _2___x1, _2___x2, _2___x3, _2___ul, _2___vl, _2___uh, _2___vh, _2___u, _2___v uint32
 
 	_cl = 0
@@ -566,7 +566,7 @@ _0:
 	return _cl
 }
 
-func Xmpn_mul(tls *crt.TLS, _rp *uint32, _up *uint32, _un int32, _vp *uint32, _vn int32) (r0 uint32) {
+func _mpn_mul_basecase(tls *crt.TLS, _rp *uint32, _up *uint32, _un int32, _vp *uint32, _vn int32) (r0 uint32) {
 	*elem0(_rp, uintptr(_un)) = Xmpn_mul_1(tls, _rp, _up, _un, *_vp)
 _0:
 	if preInc1(&_vn, -1) >= int32(1) {
@@ -593,12 +593,12 @@ func Xmpn_perfect_square_p(tls *crt.TLS, _p *uint32, _n int32) (r0 int32) {
 	return Xmpz_root(tls, nil, (*[1]Xmpz_srcptr)(unsafe.Pointer(Xmpz_roinit_n(tls, &_t, _p, _n))), uint32(2))
 }
 
-func Xmpz_root(tls *crt.TLS, _x *[1]Xmpz_srcptr, _y *[1]Xmpz_srcptr, _z uint32) (r0 int32) {
+func _mpz_root_generic(tls *crt.TLS, _x *[1]Xmpz_srcptr, _y *[1]Xmpz_srcptr, _z uint32) (r0 int32) {
 	var _res int32
 	var _r [1]Xmpz_srcptr
 	Xmpz_init(tls, &_r)
 	Xmpz_rootrem(tls, _x, &_r, _y, _z)
-	_res = bool2int((_r[0].X_mp_size) == int32(0))
+	_res = bool2int((_r[0].X_mp_size) == mpzField(0))
 	Xmpz_clear(tls, &_r)
 	return _res
 }
@@ -606,8 +606,8 @@ func Xmpz_root(tls *crt.TLS, _x *[1]Xmpz_srcptr, _y *[1]Xmpz_srcptr, _z uint32)
 // C comment
 //  /* MPZ interface */
 func Xmpz_init(tls *crt.TLS, _r *[1]Xmpz_srcptr) {
-	_r[0].X_mp_alloc = int32(0)
-	_r[0].X_mp_size = int32(0)
+	_r[0].X_mp_alloc = mpzField(0)
+	_r[0].X_mp_size = mpzField(0)
 	_r[0].X_mp_d = &_mpz_initØ00dummy_limbØ001
 }
 
@@ -619,10 +619,10 @@ func init() {
 
 // C comment
 //  /* x=trunc(y^(1/z)), r=y-x^z */
-func Xmpz_rootrem(tls *crt.TLS, _x *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _y *[1]Xmpz_srcptr, _z uint32) {
+func _mpz_rootrem_generic(tls *crt.TLS, _x *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _y *[1]Xmpz_srcptr, _z uint32) {
 	var _sgn int32
 	var _t, _u, _3_v [1]Xmpz_srcptr
-	_sgn = bool2int((_y[0].X_mp_size) < int32(0))
+	_sgn = bool2int((_y[0].X_mp_size) < mpzField(0))
 	if ((^_z) & uint32(_sgn)) != 0 {
 		_gmp_die(tls, str(96))
 	}
@@ -636,7 +636,7 @@ func Xmpz_rootrem(tls *crt.TLS, _x *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _y *[1]X
 		Xmpz_set(tls, _x, _y)
 	}
 	if _r != nil {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 	}
 	return
 _2:
@@ -684,12 +684,12 @@ _7:
 }
 
 func Xmpz_cmpabs_ui(tls *crt.TLS, _u *[1]Xmpz_srcptr, _v uint32) (r0 int32) {
-	if func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	if func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
-	}() > int32(1) {
+	}() > mpzField(1) {
 		return int32(1)
 	}
 	return bool2int(Xmpz_get_ui(tls, _u) > _v) - bool2int(Xmpz_get_ui(tls, _u) < _v)
@@ -697,7 +697,7 @@ func Xmpz_cmpabs_ui(tls *crt.TLS, _u *[1]Xmpz_srcptr, _v uint32) (r0 int32) {
 
 func Xmpz_get_ui(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 uint32) {
 	return func() uint32 {
-		if (_u[0].X_mp_size) == int32(0) {
+		if (_u[0].X_mp_size) == mpzField(0) {
 			return 0
 		}
 		return (*(_u[0].X_mp_d))
@@ -708,8 +708,8 @@ func Xmpz_set(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x *[1]Xmpz_srcptr) {
 	var _1_n int32
 	var _1_rp *uint32
 	if &_r[0] != &_x[0] {
-		_1_n = func() int32 {
-			if (_x[0].X_mp_size) >= int32(0) {
+		_1_n = func() mpzField {
+			if (_x[0].X_mp_size) >= mpzField(0) {
 				return (_x[0].X_mp_size)
 			}
 			return (-(_x[0].X_mp_size))
@@ -725,7 +725,7 @@ func Xmpz_set(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x *[1]Xmpz_srcptr) {
 	}
 }
 
-func _mpz_realloc(tls *crt.TLS, _r *[1]Xmpz_srcptr, _size int32) (r0 *uint32) {
+func _mpz_realloc_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _size int32) (r0 *uint32) {
 	_size = func() int32 {
 		if _size > int32(1) {
 			return _size
@@ -739,13 +739,13 @@ func _mpz_realloc(tls *crt.TLS, _r *[1]Xmpz_srcptr, _size int32) (r0 *uint32) {
 	_r[0].X_mp_d = _gmp_xalloc_limbs(tls, _size)
 _3:
 	_r[0].X_mp_alloc = _size
-	if func() int32 {
-		if (_r[0].X_mp_size) >= int32(0) {
+	if func() mpzField {
+		if (_r[0].X_mp_size) >= mpzField(0) {
 			return (_r[0].X_mp_size)
 		}
 		return (-(_r[0].X_mp_size))
 	}() > _size {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 	}
 	return _r[0].X_mp_d
 }
@@ -758,11 +758,11 @@ func _gmp_xalloc_limbs(tls *crt.TLS, _size int32) (r0 *uint32) {
 	return (*uint32)(_gmp_allocate_func(tls, uint32(_size)*uint32(4)))
 }
 
-func Xmpz_setbit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint32) {
+func _mpz_setbit_generic(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint32) {
 	if Xmpz_tstbit(tls, _d, _bit_index) != 0 {
 		goto _0
 	}
-	if (_d[0].X_mp_size) >= int32(0) {
+	if (_d[0].X_mp_size) >= mpzField(0) {
 		_mpz_abs_add_bit(tls, _d, _bit_index)
 		goto _2
 	}
@@ -811,8 +811,8 @@ func _mpz_abs_add_bit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint32) {
 	var _dn, _limb_index, _1_i int32
 	var _bit, _2_cy uint32
 	var _dp *uint32
-	_dn = func() int32 {
-		if (_d[0].X_mp_size) >= int32(0) {
+	_dn = func() mpzField {
+		if (_d[0].X_mp_size) >= mpzField(0) {
 			return (_d[0].X_mp_size)
 		}
 		return (-(_d[0].X_mp_size))
@@ -854,7 +854,7 @@ _2:
 	}
 _9:
 	_d[0].X_mp_size = func() int32 {
-		if (_d[0].X_mp_size) < int32(0) {
+		if (_d[0].X_mp_size) < mpzField(0) {
 			return (-_dn)
 		}
 		return _dn
@@ -865,8 +865,8 @@ func _mpz_abs_sub_bit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint32) {
 	var _dn, _limb_index int32
 	var _bit, _1___cy uint32
 	var _dp *uint32
-	_dn = func() int32 {
-		if (_d[0].X_mp_size) >= int32(0) {
+	_dn = func() mpzField {
+		if (_d[0].X_mp_size) >= mpzField(0) {
 			return (_d[0].X_mp_size)
 		}
 		return (-(_d[0].X_mp_size))
@@ -879,7 +879,7 @@ func _mpz_abs_sub_bit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint32) {
 
 	_dn = _mpn_normalized_size(tls, _dp, _dn)
 	_d[0].X_mp_size = func() int32 {
-		if (_d[0].X_mp_size) < int32(0) {
+		if (_d[0].X_mp_size) < mpzField(0) {
 			return (-_dn)
 		}
 		return _dn
@@ -893,8 +893,8 @@ func Xmpz_sizeinbase(tls *crt.TLS, _u *[1]Xmpz_srcptr, _base int32) (r0 uint32)
 	var _up, _tp *uint32
 	var _bi Tgmp_div_inverse
 
-	_un = func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -1005,7 +1005,7 @@ _8:
 //
 //       m = floor( (B^3-1) / (B u1 + u0)) - B
 //  */
-func Xmpn_invert_3by2(tls *crt.TLS, _u1 uint32, _u0 uint32) (r0 uint32) {
+func _mpn_invert_3by2_generic(tls *crt.TLS, _u1 uint32, _u0 uint32) (r0 uint32) {
 	var _r, _p, _m, _ql, _ul, _uh, _qh, _5_th, _5_tl, _8___x0, _8___x1, _8___x2, _8___x3, _8___ul, _8___vl, _8___uh, _8___vh, _8___u, _8___v uint32
 
 	_ul = _u1 & uint32(65535)
@@ -1084,7 +1084,7 @@ _5:
 // C comment
 //  /* Not matching current public gmp interface, rather corresponding to
 //     the sbpi1_div_* functions. */
-func _mpn_div_qr_1_preinv(tls *crt.TLS, _qp *uint32, _np *uint32, _nn int32, _inv *Tgmp_div_inverse) (r0 uint32) {
+func _mpn_div_qr_1_preinv_generic(tls *crt.TLS, _qp *uint32, _np *uint32, _nn int32, _inv *Tgmp_div_inverse) (r0 uint32) {
 	var _d, _di, _r, _2_q, _3__qh, _3__ql, _3__r, _3__mask, _4___x0, _4___x1, _4___x2, _4___x3, _4___ul, _4___vl, _4___uh, _4___vh, _4___u, _4___v, _5___x uint32
 	var _tp *uint32
 	_tp = nil
@@ -1143,7 +1143,7 @@ _3:
 	return _r >> uint(int32(_inv.Xshift))
 }
 
-func Xmpn_lshift(tls *crt.TLS, _rp *uint32, _up *uint32, _n int32, _cnt uint32) (r0 uint32) {
+func _mpn_lshift_generic(tls *crt.TLS, _rp *uint32, _up *uint32, _n int32, _cnt uint32) (r0 uint32) {
 	var _high_limb, _low_limb, _tnc, _retval uint32
 
 	*(*uintptr)(unsafe.Pointer(&_up)) += 4 * uintptr(_n)
@@ -1183,7 +1183,7 @@ func Xmpz_tdiv_q(tls *crt.TLS, _q *[1]Xmpz_srcptr, _n *[1]Xmpz_srcptr, _d *[1]Xm
 
 // C comment
 //  /* Allows q or r to be zero. Returns 1 iff remainder is non-zero. */
-func _mpz_div_qr(tls *crt.TLS, _q *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _n *[1]Xmpz_srcptr, _d *[1]Xmpz_srcptr, _mode int32) (r0 int32) {
+func _mpz_div_qr_generic(tls *crt.TLS, _q *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _n *[1]Xmpz_srcptr, _d *[1]Xmpz_srcptr, _mode int32) (r0 int32) {
 	var _ns, _ds, _nn, _dn, _qs, _6_qn, _6_rn int32
 	var _6_np, _6_qp *uint32
 	var _6_tq, _6_tr [1]Xmpz_srcptr
@@ -1196,10 +1196,10 @@ func _mpz_div_qr(tls *crt.TLS, _q *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _n *[1]Xm
 		goto _1
 	}
 	if _q != nil {
-		_q[0].X_mp_size = int32(0)
+		_q[0].X_mp_size = mpzField(0)
 	}
 	if _r != nil {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 	}
 	return int32(0)
 
@@ -1246,7 +1246,7 @@ _15:
 		Xmpz_set(tls, _r, _n)
 	}
 	if _q != nil {
-		_q[0].X_mp_size = int32(0)
+		_q[0].X_mp_size = mpzField(0)
 	}
 _18:
 	return int32(1)
@@ -1313,14 +1313,14 @@ _37:
 
 func Xmpz_sub(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr) {
 	var _rn int32
-	if ((_a[0].X_mp_size) ^ (_b[0].X_mp_size)) >= int32(0) {
+	if ((_a[0].X_mp_size) ^ (_b[0].X_mp_size)) >= mpzField(0) {
 		_rn = _mpz_abs_sub(tls, _r, _a, _b)
 		goto _1
 	}
 	_rn = _mpz_abs_add(tls, _r, _a, _b)
 _1:
 	_r[0].X_mp_size = func() int32 {
-		if (_a[0].X_mp_size) >= int32(0) {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return _rn
 		}
 		return (-_rn)
@@ -1331,14 +1331,14 @@ func _mpz_abs_sub(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b *[1]X
 	var _an, _bn, _cmp int32
 	var _2___cy, _4___cy uint32
 	var _rp *uint32
-	_an = func() int32 {
-		if (_a[0].X_mp_size) >= int32(0) {
+	_an = func() mpzField {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return (_a[0].X_mp_size)
 		}
 		return (-(_a[0].X_mp_size))
 	}()
-	_bn = func() int32 {
-		if (_b[0].X_mp_size) >= int32(0) {
+	_bn = func() mpzField {
+		if (_b[0].X_mp_size) >= mpzField(0) {
 			return (_b[0].X_mp_size)
 		}
 		return (-(_b[0].X_mp_size))
@@ -1394,14 +1394,14 @@ func _mpz_abs_add(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b *[1]X
 	var _cy uint32
 	var _rp *uint32
 	var _2___mpz_srcptr_swap__tmp *Xmpz_srcptr
-	_an = func() int32 {
-		if (_a[0].X_mp_size) >= int32(0) {
+	_an = func() mpzField {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return (_a[0].X_mp_size)
 		}
 		return (-(_a[0].X_mp_size))
 	}()
-	_bn = func() int32 {
-		if (_b[0].X_mp_size) >= int32(0) {
+	_bn = func() mpzField {
+		if (_b[0].X_mp_size) >= mpzField(0) {
 			return (_b[0].X_mp_size)
 		}
 		return (-(_b[0].X_mp_size))
@@ -1429,29 +1429,29 @@ _4:
 
 func Xmpz_set_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x uint32) {
 	if _x > 0 {
-		_r[0].X_mp_size = int32(1)
+		_r[0].X_mp_size = mpzField(1)
 		*func() *uint32 {
-			if int32(1) > (_r[0].X_mp_alloc) {
+			if mpzField(1) > (_r[0].X_mp_alloc) {
 				return _mpz_realloc(tls, _r, int32(1))
 			}
 			return (_r[0].X_mp_d)
 		}() = _x
 		goto _3
 	}
-	_r[0].X_mp_size = int32(0)
+	_r[0].X_mp_size = mpzField(0)
 _3:
 }
 
 func Xmpz_add(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr) {
 	var _rn int32
-	if ((_a[0].X_mp_size) ^ (_b[0].X_mp_size)) >= int32(0) {
+	if ((_a[0].X_mp_size) ^ (_b[0].X_mp_size)) >= mpzField(0) {
 		_rn = _mpz_abs_add(tls, _r, _a, _b)
 		goto _1
 	}
 	_rn = _mpz_abs_sub(tls, _r, _a, _b)
 _1:
 	_r[0].X_mp_size = func() int32 {
-		if (_a[0].X_mp_size) >= int32(0) {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return _rn
 		}
 		return (-_rn)
@@ -1465,9 +1465,9 @@ func Xmpz_set_si(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x int32) {
 		Xmpz_set_ui(tls, _r, uint32(_x))
 		goto _1
 	}
-	_r[0].X_mp_size = int32(-1)
+	_r[0].X_mp_size = mpzField(-1)
 	*func() *uint32 {
-		if int32(1) > (_r[0].X_mp_alloc) {
+		if mpzField(1) > (_r[0].X_mp_alloc) {
 			return _mpz_realloc(tls, _r, int32(1))
 		}
 		return (_r[0].X_mp_d)
@@ -1483,12 +1483,12 @@ func Xmpz_init_set(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x *[1]Xmpz_srcptr) {
 // C comment
 //  /* The utility of this function is a bit limited, since many functions
 //     assigns the result variable using mpz_swap. */
-func Xmpz_init2(tls *crt.TLS, _r *[1]Xmpz_srcptr, _bits uint32) {
+func _mpz_init2_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _bits uint32) {
 	var _rn int32
 	_bits -= uint32(bool2int(_bits != 0))
 	_rn = int32(uint32(1) + (_bits / uint32(32)))
 	_r[0].X_mp_alloc = _rn
-	_r[0].X_mp_size = int32(0)
+	_r[0].X_mp_size = mpzField(0)
 	_r[0].X_mp_d = _gmp_xalloc_limbs(tls, _rn)
 }
 
@@ -1618,7 +1618,7 @@ _3:
 	_ = _2___cy
 }
 
-func _mpn_div_qr_2_preinv(tls *crt.TLS, _qp *uint32, _rp *uint32, _np *uint32, _nn int32, _inv *Tgmp_div_inverse) {
+func _mpn_div_qr_2_preinv_generic(tls *crt.TLS, _qp *uint32, _rp *uint32, _np *uint32, _nn int32, _inv *Tgmp_div_inverse) {
 	var _i int32
 	var _shift, _d1, _d0, _di, _r1, _r0, _2_n0, _2_q, _3__q0, _3__t1, _3__t0, _3__mask, _4___x0, _4___x1, _4___x2, _4___x3, _4___ul, _4___vl, _4___uh, _4___vh, _4___u, _4___v, _5___x, _6___x, _7___x0, _7___x1, _7___x2, _7___x3, _7___ul, _7___vl, _7___uh, _7___vh, _7___u, _7___v, _8___x, _9___x, _12___x uint32
 	var _tp *uint32
@@ -1716,7 +1716,7 @@ _12:
 	*_rp = _r0
 }
 
-func _mpn_div_qr_pi1(tls *crt.TLS, _qp *uint32, _np *uint32, _nn int32, _n1 uint32, _dp *uint32, _dn int32, _dinv uint32) {
+func _mpn_div_qr_pi1_generic(tls *crt.TLS, _qp *uint32, _np *uint32, _nn int32, _n1 uint32, _dp *uint32, _dn int32, _dinv uint32) {
 	var _i int32
 	var _d1, _d0, _cy, _cy1, _q, _1_n0, _4__q0, _4__t1, _4__t0, _4__mask, _5___x0, _5___x1, _5___x2, _5___x3, _5___ul, _5___vl, _5___uh, _5___vh, _5___u, _5___v, _6___x, _7___x, _8___x0, _8___x1, _8___x2, _8___x3, _8___ul, _8___vl, _8___uh, _8___vh, _8___u, _8___v, _9___x, _10___x, _13___x uint32
 
@@ -1814,7 +1814,7 @@ _3:
 	*elem0(_np, uintptr(_dn-int32(1))) = _n1
 }
 
-func Xmpn_rshift(tls *crt.TLS, _rp *uint32, _up *uint32, _n int32, _cnt uint32) (r0 uint32) {
+func _mpn_rshift_generic(tls *crt.TLS, _rp *uint32, _up *uint32, _n int32, _cnt uint32) (r0 uint32) {
 	var _high_limb, _low_limb, _tnc, _retval uint32
 
 	_tnc = uint32(32) - _cnt
@@ -1833,7 +1833,7 @@ _0:
 }
 
 func Xmpz_sub_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b uint32) {
-	if (_a[0].X_mp_size) < int32(0) {
+	if (_a[0].X_mp_size) < mpzField(0) {
 		_r[0].X_mp_size = -_mpz_abs_add_ui(tls, _r, _a, _b)
 		goto _1
 	}
@@ -1847,15 +1847,15 @@ func _mpz_abs_add_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b ui
 	var _an int32
 	var _cy uint32
 	var _rp *uint32
-	_an = func() int32 {
-		if (_a[0].X_mp_size) >= int32(0) {
+	_an = func() mpzField {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return (_a[0].X_mp_size)
 		}
 		return (-(_a[0].X_mp_size))
 	}()
 	if _an == int32(0) {
 		*func() *uint32 {
-			if int32(1) > (_r[0].X_mp_alloc) {
+			if mpzField(1) > (_r[0].X_mp_alloc) {
 				return _mpz_realloc(tls, _r, int32(1))
 			}
 			return (_r[0].X_mp_d)
@@ -1884,15 +1884,15 @@ func _mpz_abs_sub_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b ui
 	var _an int32
 	var _4___cy uint32
 	var _rp *uint32
-	_an = func() int32 {
-		if (_a[0].X_mp_size) >= int32(0) {
+	_an = func() mpzField {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return (_a[0].X_mp_size)
 		}
 		return (-(_a[0].X_mp_size))
 	}()
 	if _an == int32(0) {
 		*func() *uint32 {
-			if int32(1) > (_r[0].X_mp_alloc) {
+			if mpzField(1) > (_r[0].X_mp_alloc) {
 				return _mpz_realloc(tls, _r, int32(1))
 			}
 			return (_r[0].X_mp_d)
@@ -1917,7 +1917,7 @@ func _mpz_abs_sub_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b ui
 }
 
 func Xmpz_add_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b uint32) {
-	if (_a[0].X_mp_size) >= int32(0) {
+	if (_a[0].X_mp_size) >= mpzField(0) {
 		_r[0].X_mp_size = _mpz_abs_add_ui(tls, _r, _a, _b)
 		goto _1
 	}
@@ -1940,7 +1940,7 @@ func _mpz_div_q_2exp(tls *crt.TLS, _q *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _bit_
 	var _qp *uint32
 	_un = _u[0].X_mp_size
 	if _un == int32(0) {
-		_q[0].X_mp_size = int32(0)
+		_q[0].X_mp_size = mpzField(0)
 		return
 	}
 	_limb_cnt = int32(_bit_index / uint32(32))
@@ -1995,20 +1995,20 @@ func Xmpz_neg(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr) {
 }
 
 func Xmpz_cmpabs(tls *crt.TLS, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) (r0 int32) {
-	return _mpn_cmp4(tls, _u[0].X_mp_d, func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	return _mpn_cmp4(tls, _u[0].X_mp_d, func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
-	}(), _v[0].X_mp_d, func() int32 {
-		if (_v[0].X_mp_size) >= int32(0) {
+	}(), _v[0].X_mp_d, func() mpzField {
+		if (_v[0].X_mp_size) >= mpzField(0) {
 			return (_v[0].X_mp_size)
 		}
 		return (-(_v[0].X_mp_size))
 	}())
 }
 
-func Xmpz_pow_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e uint32) {
+func _mpz_pow_ui_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e uint32) {
 	var _bit uint32
 	var _tr [1]Xmpz_srcptr
 	Xmpz_init_set_ui(tls, &_tr, uint32(1))
@@ -2038,7 +2038,7 @@ func Xmpz_mul(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	_un = _u[0].X_mp_size
 	_vn = _v[0].X_mp_size
 	if (_un == int32(0)) || (_vn == int32(0)) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_sign = bool2int((_un ^ _vn) < int32(0))
@@ -2080,7 +2080,7 @@ func Xmpz_mul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint32
 	var _tp *uint32
 	_us = _u[0].X_mp_size
 	if (_us == int32(0)) || (_v == 0) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_un = func() int32 {
@@ -2119,10 +2119,10 @@ func _mpz_div_qr_ui(tls *crt.TLS, _q *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _n *[1
 		goto _0
 	}
 	if _q != nil {
-		_q[0].X_mp_size = int32(0)
+		_q[0].X_mp_size = mpzField(0)
 	}
 	if _r != nil {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 	}
 	return 0
 
@@ -2167,7 +2167,7 @@ _16:
 _15:
 	if _r != nil {
 		*func() *uint32 {
-			if int32(1) > (_r[0].X_mp_alloc) {
+			if mpzField(1) > (_r[0].X_mp_alloc) {
 				return _mpz_realloc(tls, _r, int32(1))
 			}
 			return (_r[0].X_mp_d)
@@ -2237,13 +2237,13 @@ _0:
 }
 
 func Xmpz_roinit_n(tls *crt.TLS, _x *[1]Xmpz_srcptr, _xp *uint32, _xs int32) (r0 *Xmpz_srcptr) {
-	_x[0].X_mp_alloc = int32(0)
+	_x[0].X_mp_alloc = mpzField(0)
 	_x[0].X_mp_d = _xp
 	Xmpz_limbs_finish(tls, _x, _xs)
 	return &_x[0]
 }
 
-func Xmpz_limbs_finish(tls *crt.TLS, _x *[1]Xmpz_srcptr, _xs int32) {
+func _mpz_limbs_finish_generic(tls *crt.TLS, _x *[1]Xmpz_srcptr, _xs int32) {
 	var _xn int32
 	_xn = _mpn_normalized_size(tls, _x[0].X_mp_d, func() int32 {
 		if _xs >= int32(0) {
@@ -2679,7 +2679,7 @@ _5:
 // C comment
 //  /* MPZ comparisons and the like. */
 func Xmpz_sgn(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 int32) {
-	return bool2int((_u[0].X_mp_size) > int32(0)) - bool2int((_u[0].X_mp_size) < int32(0))
+	return bool2int((_u[0].X_mp_size) > mpzField(0)) - bool2int((_u[0].X_mp_size) < mpzField(0))
 }
 
 func Xmpz_cmp_si(tls *crt.TLS, _u *[1]Xmpz_srcptr, _v int32) (r0 int32) {
@@ -2728,7 +2728,7 @@ func Xmpz_cmp(tls *crt.TLS, _a *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr) (r0 int32) {
 }
 
 func Xmpz_cmp_d(tls *crt.TLS, _x *[1]Xmpz_srcptr, _d float64) (r0 int32) {
-	if (_x[0].X_mp_size) >= int32(0) {
+	if (_x[0].X_mp_size) >= mpzField(0) {
 		goto _0
 	}
 	if _d >= float64(0) {
@@ -2799,8 +2799,8 @@ _2:
 
 func Xmpz_abs(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr) {
 	Xmpz_set(tls, _r, _u)
-	_r[0].X_mp_size = func() int32 {
-		if (_r[0].X_mp_size) >= int32(0) {
+	_r[0].X_mp_size = func() mpzField {
+		if (_r[0].X_mp_size) >= mpzField(0) {
 			return (_r[0].X_mp_size)
 		}
 		return (-(_r[0].X_mp_size))
@@ -2808,7 +2808,7 @@ func Xmpz_abs(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr) {
 }
 
 func Xmpz_ui_sub(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a uint32, _b *[1]Xmpz_srcptr) {
-	if (_b[0].X_mp_size) < int32(0) {
+	if (_b[0].X_mp_size) < mpzField(0) {
 		_r[0].X_mp_size = _mpz_abs_add_ui(tls, _r, _b, _a)
 		goto _1
 	}
@@ -2828,18 +2828,18 @@ func Xmpz_mul_si(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v int32)
 _1:
 }
 
-func Xmpz_mul_2exp(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _bits uint32) {
+func _mpz_mul_2exp_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _bits uint32) {
 	var _un, _rn, _limbs int32
 	var _shift, _2_cy uint32
 	var _rp *uint32
-	_un = func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
 	}()
 	if _un == int32(0) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_limbs = int32(_bits / uint32(32))
@@ -2861,14 +2861,14 @@ func Xmpz_mul_2exp(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _bits u
 _6:
 	Xmpn_zero(tls, _rp, _limbs)
 	_r[0].X_mp_size = func() int32 {
-		if (_u[0].X_mp_size) < int32(0) {
+		if (_u[0].X_mp_size) < mpzField(0) {
 			return (-_rn)
 		}
 		return _rn
 	}()
 }
 
-func Xmpz_addmul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint32) {
+func _mpz_addmul_ui_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint32) {
 	var _t [1]Xmpz_srcptr
 	Xmpz_init(tls, &_t)
 	Xmpz_mul_ui(tls, &_t, _u, _v)
@@ -2876,7 +2876,7 @@ func Xmpz_addmul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uin
 	Xmpz_clear(tls, &_t)
 }
 
-func Xmpz_addmul(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
+func _mpz_addmul_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _t [1]Xmpz_srcptr
 	Xmpz_init(tls, &_t)
 	Xmpz_mul(tls, &_t, _u, _v)
@@ -2884,7 +2884,7 @@ func Xmpz_addmul(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xm
 	Xmpz_clear(tls, &_t)
 }
 
-func Xmpz_submul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint32) {
+func _mpz_submul_ui_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint32) {
 	var _t [1]Xmpz_srcptr
 	Xmpz_init(tls, &_t)
 	Xmpz_mul_ui(tls, &_t, _u, _v)
@@ -2892,7 +2892,7 @@ func Xmpz_submul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uin
 	Xmpz_clear(tls, &_t)
 }
 
-func Xmpz_submul(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
+func _mpz_submul_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _t [1]Xmpz_srcptr
 	Xmpz_init(tls, &_t)
 	Xmpz_mul(tls, &_t, _u, _v)
@@ -2950,7 +2950,7 @@ func _mpz_div_r_2exp(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _bit_
 	var _rp *uint32
 	_us = _u[0].X_mp_size
 	if (_us == int32(0)) || (_bit_index == 0) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_rn = int32(((_bit_index + uint32(32)) - uint32(1)) / uint32(32))
@@ -3036,7 +3036,7 @@ func Xmpz_tdiv_r_2exp(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _cnt
 
 func Xmpz_mod(tls *crt.TLS, _r *[1]Xmpz_srcptr, _n *[1]Xmpz_srcptr, _d *[1]Xmpz_srcptr) {
 	_mpz_div_qr(tls, nil, _r, _n, _d, func() int32 {
-		if (_d[0].X_mp_size) >= int32(0) {
+		if (_d[0].X_mp_size) >= mpzField(0) {
 			return int32(0)
 		}
 		return int32(1)
@@ -3136,8 +3136,8 @@ func Xmpz_gcd_ui(tls *crt.TLS, _g *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint32
 	}
 	goto _2
 _0:
-	_un = func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -3224,15 +3224,15 @@ _18:
 	return _u << uint(int32(_shift))
 }
 
-func Xmpz_gcd(tls *crt.TLS, _g *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
+func _mpz_gcd_generic(tls *crt.TLS, _g *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _4_c int32
 	var _uz, _vz, _gz, _6_vl, _6_ul uint32
 	var _tu, _tv [1]Xmpz_srcptr
-	if (_u[0].X_mp_size) == int32(0) {
+	if (_u[0].X_mp_size) == mpzField(0) {
 		Xmpz_abs(tls, _g, _v)
 		return
 	}
-	if (_v[0].X_mp_size) == int32(0) {
+	if (_v[0].X_mp_size) == mpzField(0) {
 		Xmpz_abs(tls, _g, _u)
 		return
 	}
@@ -3252,7 +3252,7 @@ func Xmpz_gcd(tls *crt.TLS, _g *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 		Xmpz_swap(tls, &_tu, &_tv)
 	}
 	Xmpz_tdiv_r(tls, &_tu, &_tu, &_tv)
-	if (_tu[0].X_mp_size) == int32(0) {
+	if (_tu[0].X_mp_size) == mpzField(0) {
 		Xmpz_swap(tls, _g, &_tv)
 		goto _9
 	}
@@ -3266,7 +3266,7 @@ _5:
 	if _4_c < int32(0) {
 		Xmpz_swap(tls, &_tu, &_tv)
 	}
-	if (_tv[0].X_mp_size) == int32(1) {
+	if (_tv[0].X_mp_size) == mpzField(1) {
 		_6_vl = *(_tv[0].X_mp_d)
 		_6_ul = Xmpz_tdiv_ui(tls, &_tu, _6_vl)
 		Xmpz_set_ui(tls, _g, _mpn_gcd_11(tls, _6_ul, _6_vl))
@@ -3288,12 +3288,12 @@ func _mpz_make_odd(tls *crt.TLS, _r *[1]Xmpz_srcptr) (r0 uint32) {
 	return _shift
 }
 
-func Xmpz_gcdext(tls *crt.TLS, _g *[1]Xmpz_srcptr, _s *[1]Xmpz_srcptr, _t *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
+func _mpz_gcdext_generic(tls *crt.TLS, _g *[1]Xmpz_srcptr, _s *[1]Xmpz_srcptr, _t *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _1_sign, _2_sign, _8_c int32
 	var _uz, _vz, _gz, _power, _6___mp_bitcnt_t_swap__tmp, _7_shift uint32
 	var _tu, _tv, _s0, _s1, _t0, _t1 [1]Xmpz_srcptr
 	var _4___mpz_srcptr_swap__tmp, _5___mpz_ptr_swap__tmp *Xmpz_srcptr
-	if (_u[0].X_mp_size) != int32(0) {
+	if (_u[0].X_mp_size) != mpzField(0) {
 		goto _0
 	}
 	_1_sign = Xmpz_sgn(tls, _v)
@@ -3306,7 +3306,7 @@ func Xmpz_gcdext(tls *crt.TLS, _g *[1]Xmpz_srcptr, _s *[1]Xmpz_srcptr, _t *[1]Xm
 	}
 	return
 _0:
-	if (_v[0].X_mp_size) != int32(0) {
+	if (_v[0].X_mp_size) != mpzField(0) {
 		goto _3
 	}
 	_2_sign = Xmpz_sgn(tls, _u)
@@ -3356,7 +3356,7 @@ _8:
 	Xmpz_mul_2exp(tls, &_t1, &_t1, _uz)
 	Xmpz_setbit(tls, &_s1, _vz)
 	_power = _uz + _vz
-	if (_tu[0].X_mp_size) <= int32(0) {
+	if (_tu[0].X_mp_size) <= mpzField(0) {
 		goto _12
 	}
 	_7_shift = _mpz_make_odd(tls, &_tu)
@@ -3398,7 +3398,7 @@ _19:
 	if postInc2(&_power, uint32(4294967295)) <= 0 {
 		goto _20
 	}
-	if (bool2int((_s0[0].X_mp_size) != int32(0))&int32(*(_s0[0].X_mp_d))) != 0 || (bool2int((_t0[0].X_mp_size) != int32(0))&int32(*(_t0[0].X_mp_d))) != 0 {
+	if (bool2int((_s0[0].X_mp_size) != mpzField(0))&int32(*(_s0[0].X_mp_d))) != 0 || (bool2int((_t0[0].X_mp_size) != mpzField(0))&int32(*(_t0[0].X_mp_d))) != 0 {
 		Xmpz_sub(tls, &_s0, &_s0, &_s1)
 		Xmpz_add(tls, &_t0, &_t0, &_t1)
 	}
@@ -3411,10 +3411,10 @@ _20:
 		Xmpz_swap(tls, &_s0, &_s1)
 		Xmpz_sub(tls, &_t0, &_t0, &_t1)
 	}
-	if (_u[0].X_mp_size) < int32(0) {
+	if (_u[0].X_mp_size) < mpzField(0) {
 		Xmpz_neg(tls, &_s0, &_s0)
 	}
-	if (_v[0].X_mp_size) < int32(0) {
+	if (_v[0].X_mp_size) < mpzField(0) {
 		Xmpz_neg(tls, &_t0, &_t0)
 	}
 	Xmpz_swap(tls, _g, &_tv)
@@ -3434,8 +3434,8 @@ _20:
 }
 
 func Xmpz_lcm_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint32) {
-	if (_v == 0) || ((_u[0].X_mp_size) == int32(0)) {
-		_r[0].X_mp_size = int32(0)
+	if (_v == 0) || ((_u[0].X_mp_size) == mpzField(0)) {
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_v /= Xmpz_gcd_ui(tls, nil, _u, _v)
@@ -3443,10 +3443,10 @@ func Xmpz_lcm_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint32
 	Xmpz_abs(tls, _r, _r)
 }
 
-func Xmpz_lcm(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
+func _mpz_lcm_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _g [1]Xmpz_srcptr
-	if ((_u[0].X_mp_size) == int32(0)) || ((_v[0].X_mp_size) == int32(0)) {
-		_r[0].X_mp_size = int32(0)
+	if ((_u[0].X_mp_size) == mpzField(0)) || ((_v[0].X_mp_size) == mpzField(0)) {
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	Xmpz_init(tls, &_g)
@@ -3457,10 +3457,10 @@ func Xmpz_lcm(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	Xmpz_abs(tls, _r, _r)
 }
 
-func Xmpz_invert(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _m *[1]Xmpz_srcptr) (r0 int32) {
+func _mpz_invert_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _m *[1]Xmpz_srcptr) (r0 int32) {
 	var _invertible int32
 	var _g, _tr [1]Xmpz_srcptr
-	if ((_u[0].X_mp_size) == int32(0)) || (Xmpz_cmpabs_ui(tls, _m, uint32(1)) <= int32(0)) {
+	if ((_u[0].X_mp_size) == mpzField(0)) || (Xmpz_cmpabs_ui(tls, _m, uint32(1)) <= int32(0)) {
 		return int32(0)
 	}
 	Xmpz_init(tls, &_g)
@@ -3470,10 +3470,10 @@ func Xmpz_invert(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _m *[1]Xm
 	if _invertible == 0 {
 		goto _2
 	}
-	if (_tr[0].X_mp_size) >= int32(0) {
+	if (_tr[0].X_mp_size) >= mpzField(0) {
 		goto _3
 	}
-	if (_m[0].X_mp_size) >= int32(0) {
+	if (_m[0].X_mp_size) >= mpzField(0) {
 		Xmpz_add(tls, &_tr, &_tr, _m)
 		goto _5
 	}
@@ -3498,32 +3498,32 @@ func Xmpz_sqrt(tls *crt.TLS, _s *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr) {
 }
 
 func Xmpz_perfect_square_p(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 int32) {
-	if (_u[0].X_mp_size) <= int32(0) {
-		return bool2int((_u[0].X_mp_size) == int32(0))
+	if (_u[0].X_mp_size) <= mpzField(0) {
+		return bool2int((_u[0].X_mp_size) == mpzField(0))
 	}
 	return Xmpz_root(tls, nil, _u, uint32(2))
 }
 
-func Xmpz_ui_pow_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _blimb uint32, _e uint32) {
+func _mpz_ui_pow_ui_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _blimb uint32, _e uint32) {
 	var _b [1]Xmpz_srcptr
 	Xmpz_pow_ui(tls, _r, (*[1]Xmpz_srcptr)(unsafe.Pointer(Xmpz_roinit_n(tls, &_b, &_blimb, int32(1)))), _e)
 }
 
-func Xmpz_powm(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e *[1]Xmpz_srcptr, _m *[1]Xmpz_srcptr) {
+func _mpz_powm_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e *[1]Xmpz_srcptr, _m *[1]Xmpz_srcptr) {
 	var _en, _mn, _5_bn int32
 	var _shift, _3___cy, _8___cy, _9_w, _9_bit uint32
 	var _mp, _tp, _7_bp *uint32
 	var _minv Tgmp_div_inverse
 	var _tr, _base [1]Xmpz_srcptr
 	_tp = nil
-	_en = func() int32 {
-		if (_e[0].X_mp_size) >= int32(0) {
+	_en = func() mpzField {
+		if (_e[0].X_mp_size) >= mpzField(0) {
 			return (_e[0].X_mp_size)
 		}
 		return (-(_e[0].X_mp_size))
 	}()
-	_mn = func() int32 {
-		if (_m[0].X_mp_size) >= int32(0) {
+	_mn = func() mpzField {
+		if (_m[0].X_mp_size) >= mpzField(0) {
 			return (_m[0].X_mp_size)
 		}
 		return (-(_m[0].X_mp_size))
@@ -3548,7 +3548,7 @@ func Xmpz_powm(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e *[1]Xmpz
 	_mp = _tp
 _6:
 	Xmpz_init(tls, &_base)
-	if (_e[0].X_mp_size) >= int32(0) {
+	if (_e[0].X_mp_size) >= mpzField(0) {
 		goto _8
 	}
 	if Xmpz_invert(tls, &_base, _b, _m) == 0 {
@@ -3562,7 +3562,7 @@ _8:
 		_mpn_div_qr_preinv(tls, nil, _base[0].X_mp_d, _base[0].X_mp_size, _mp, _mn, &_minv)
 		_5_bn = _mn
 	}
-	if (_b[0].X_mp_size) >= int32(0) {
+	if (_b[0].X_mp_size) >= mpzField(0) {
 		goto _12
 	}
 	_7_bp = func() *uint32 {
@@ -3619,7 +3619,7 @@ func Xmpz_powm_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _elimb u
 	Xmpz_powm(tls, _r, _b, (*[1]Xmpz_srcptr)(unsafe.Pointer(Xmpz_roinit_n(tls, &_e, &_elimb, int32(1)))), _m)
 }
 
-func Xmpz_fac_ui(tls *crt.TLS, _x *[1]Xmpz_srcptr, _n uint32) {
+func _mpz_fac_ui_generic(tls *crt.TLS, _x *[1]Xmpz_srcptr, _n uint32) {
 	Xmpz_set_ui(tls, _x, _n+uint32(bool2int(_n == 0)))
 _0:
 	if _n > uint32(2) {
@@ -3628,7 +3628,7 @@ _0:
 	}
 }
 
-func Xmpz_bin_uiui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _n uint32, _k uint32) {
+func _mpz_bin_uiui_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _n uint32, _k uint32) {
 	var _t [1]Xmpz_srcptr
 	Xmpz_set_ui(tls, _r, uint32(bool2int(_k <= _n)))
 	if _k > (_n >> 1) {
@@ -3657,7 +3657,7 @@ func Xmpz_probab_prime_p(tls *crt.TLS, _n *[1]Xmpz_srcptr, _reps int32) (r0 int3
 	var _is_prime, _j int32
 	var _k uint32
 	var _nm1, _q, _y [1]Xmpz_srcptr
-	if (bool2int((_n[0].X_mp_size) != int32(0)) & int32(*(_n[0].X_mp_d))) == 0 {
+	if (bool2int((_n[0].X_mp_size) != mpzField(0)) & int32(*(_n[0].X_mp_d))) == 0 {
 		return func() int32 {
 			if Xmpz_cmpabs_ui(tls, _n, uint32(2)) == int32(0) {
 				return int32(2)
@@ -3763,7 +3763,7 @@ func Xmpz_clrbit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint32) {
 	if Xmpz_tstbit(tls, _d, _bit_index) == 0 {
 		goto _0
 	}
-	if (_d[0].X_mp_size) >= int32(0) {
+	if (_d[0].X_mp_size) >= mpzField(0) {
 		_mpz_abs_sub_bit(tls, _d, _bit_index)
 		goto _2
 	}
@@ -3773,7 +3773,7 @@ _0:
 }
 
 func Xmpz_combit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint32) {
-	if (Xmpz_tstbit(tls, _d, _bit_index) ^ bool2int((_d[0].X_mp_size) < int32(0))) != 0 {
+	if (Xmpz_tstbit(tls, _d, _bit_index) ^ bool2int((_d[0].X_mp_size) < mpzField(0))) != 0 {
 		_mpz_abs_sub_bit(tls, _d, _bit_index)
 		goto _1
 	}
@@ -3791,14 +3791,14 @@ func Xmpz_and(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	var _ux, _vx, _rx, _uc, _vc, _rc, _ul, _vl, _rl uint32
 	var _up, _vp, _rp *uint32
 	var _2___mpz_srcptr_swap__tmp *Xmpz_srcptr
-	_un = func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
 	}()
-	_vn = func() int32 {
-		if (_v[0].X_mp_size) >= int32(0) {
+	_vn = func() mpzField {
+		if (_v[0].X_mp_size) >= mpzField(0) {
 			return (_v[0].X_mp_size)
 		}
 		return (-(_v[0].X_mp_size))
@@ -3814,11 +3814,11 @@ func Xmpz_and(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	_vn = _3___mp_size_t_swap__tmp
 _4:
 	if _vn == int32(0) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
-	_uc = uint32(bool2int((_u[0].X_mp_size) < int32(0)))
-	_vc = uint32(bool2int((_v[0].X_mp_size) < int32(0)))
+	_uc = uint32(bool2int((_u[0].X_mp_size) < mpzField(0)))
+	_vc = uint32(bool2int((_v[0].X_mp_size) < mpzField(0)))
 	_rc = _uc & _vc
 	_ux = -_uc
 	_vx = -_vc
@@ -3881,14 +3881,14 @@ func Xmpz_ior(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	var _ux, _vx, _rx, _uc, _vc, _rc, _ul, _vl, _rl uint32
 	var _up, _vp, _rp *uint32
 	var _2___mpz_srcptr_swap__tmp *Xmpz_srcptr
-	_un = func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
 	}()
-	_vn = func() int32 {
-		if (_v[0].X_mp_size) >= int32(0) {
+	_vn = func() mpzField {
+		if (_v[0].X_mp_size) >= mpzField(0) {
 			return (_v[0].X_mp_size)
 		}
 		return (-(_v[0].X_mp_size))
@@ -3907,8 +3907,8 @@ _4:
 		Xmpz_set(tls, _r, _u)
 		return
 	}
-	_uc = uint32(bool2int((_u[0].X_mp_size) < int32(0)))
-	_vc = uint32(bool2int((_v[0].X_mp_size) < int32(0)))
+	_uc = uint32(bool2int((_u[0].X_mp_size) < mpzField(0)))
+	_vc = uint32(bool2int((_v[0].X_mp_size) < mpzField(0)))
 	_rc = _uc | _vc
 	_ux = -_uc
 	_vx = -_vc
@@ -3971,14 +3971,14 @@ func Xmpz_xor(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	var _ux, _vx, _rx, _uc, _vc, _rc, _ul, _vl, _rl uint32
 	var _up, _vp, _rp *uint32
 	var _2___mpz_srcptr_swap__tmp *Xmpz_srcptr
-	_un = func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
 	}()
-	_vn = func() int32 {
-		if (_v[0].X_mp_size) >= int32(0) {
+	_vn = func() mpzField {
+		if (_v[0].X_mp_size) >= mpzField(0) {
 			return (_v[0].X_mp_size)
 		}
 		return (-(_v[0].X_mp_size))
@@ -3997,8 +3997,8 @@ _4:
 		Xmpz_set(tls, _r, _u)
 		return
 	}
-	_uc = uint32(bool2int((_u[0].X_mp_size) < int32(0)))
-	_vc = uint32(bool2int((_v[0].X_mp_size) < int32(0)))
+	_uc = uint32(bool2int((_u[0].X_mp_size) < mpzField(0)))
+	_vc = uint32(bool2int((_v[0].X_mp_size) < mpzField(0)))
 	_rc = _uc ^ _vc
 	_ux = -_uc
 	_vx = -_vc
@@ -4161,7 +4161,7 @@ func Xmpz_fits_ulong_p(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 int32) {
 }
 
 func Xmpz_get_si(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 int32) {
-	if (_u[0].X_mp_size) < int32(0) {
+	if (_u[0].X_mp_size) < mpzField(0) {
 		return int32(-1) - int32(((*(_u[0].X_mp_d))-uint32(1))&uint32(2147483647))
 	}
 	return int32(Xmpz_get_ui(tls, _u) & uint32(2147483647))
@@ -4171,8 +4171,8 @@ func Xmpz_get_d(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 float64) {
 	var _un int32
 	var _x, _B float64
 	_B = 4.294967296e+09
-	_un = func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -4186,15 +4186,15 @@ _3:
 		_x = (_B * _x) + float64(*elem0(_u[0].X_mp_d, uintptr(preInc1(&_un, -1))))
 		goto _3
 	}
-	if (_u[0].X_mp_size) < int32(0) {
+	if (_u[0].X_mp_size) < mpzField(0) {
 		_x = -_x
 	}
 	return _x
 }
 
 func Xmpz_size(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 uint32) {
-	return uint32(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	return uint32(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -4202,8 +4202,8 @@ func Xmpz_size(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 uint32) {
 }
 
 func Xmpz_getlimbn(tls *crt.TLS, _u *[1]Xmpz_srcptr, _n int32) (r0 uint32) {
-	if (_n >= int32(0)) && (_n < func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	if (_n >= int32(0)) && (_n < func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -4242,7 +4242,7 @@ func Xmpz_set_d(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x float64) {
 	var _B, _Bi float64
 	var _rp *uint32
 	if (_x != _x) || (_x == (_x * 0.5)) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_sign = bool2int(_x < float64(0))
@@ -4250,7 +4250,7 @@ func Xmpz_set_d(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x float64) {
 		_x = -_x
 	}
 	if _x < float64(1) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_B = 4.294967296e+09
@@ -4325,8 +4325,8 @@ _1:
 	if _sp == nil {
 		_sp = (*int8)(_gmp_allocate_func(tls, uint32(1)+_sn))
 	}
-	_un = func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -4337,7 +4337,7 @@ _1:
 		return _sp
 	}
 	_i = 0
-	if (_u[0].X_mp_size) < int32(0) {
+	if (_u[0].X_mp_size) < mpzField(0) {
 		*elem4(_sp, uintptr(postInc2(&_i, uint32(1)))) = int8(45)
 	}
 	_bits = _mpn_base_power_of_two_p(tls, uint32(_base))
@@ -4400,7 +4400,7 @@ _3:
 _10:
 _2:
 	if (*_sp) == 0 {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return int32(-1)
 	}
 	_dp = (*uint8)(_gmp_allocate_func(tls, crt.Xstrlen(tls, _sp)))
@@ -4428,7 +4428,7 @@ _12:
 _26:
 	if _6_digit >= uint32(_base) {
 		_gmp_free_func(tls, unsafe.Pointer(_dp), 0)
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return int32(-1)
 	}
 	*elem3(_dp, uintptr(postInc2(&_dn, uint32(1)))) = uint8(_6_digit)
@@ -4438,7 +4438,7 @@ _13:
 _15:
 	if _dn == 0 {
 		_gmp_free_func(tls, unsafe.Pointer(_dp), 0)
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return int32(-1)
 	}
 	_bits = _mpn_base_power_of_two_p(tls, uint32(_base))
@@ -4709,8 +4709,8 @@ type Tmpn_base_info struct {
 } // t7 struct{exp uint32,bb uint32}
 
 type Xmpz_srcptr struct {
-	X_mp_alloc int32
-	X_mp_size  int32
+	X_mp_alloc mpzField
+	X_mp_size  mpzField
 	X_mp_d     *uint32
 }                       // t8 struct{_mp_alloc int32,_mp_size int32,_mp_d *uint32}
 func str(n int) *int8   { return (*int8)(unsafe.Pointer(&strTab[n])) }
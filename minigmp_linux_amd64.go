@@ -240,7 +240,7 @@ const (
 
 var Xstderr unsafe.Pointer
 
-func Xmp_set_memory_functions(tls *crt.TLS, _alloc_func func(*crt.TLS, uint64) unsafe.Pointer, _realloc_func func(*crt.TLS, unsafe.Pointer, uint64, uint64) unsafe.Pointer, _free_func func(*crt.TLS, unsafe.Pointer, uint64)) {
+func _mp_set_memory_functions_generic(tls *crt.TLS, _alloc_func func(*crt.TLS, uint64) unsafe.Pointer, _realloc_func func(*crt.TLS, unsafe.Pointer, uint64, uint64) unsafe.Pointer, _free_func func(*crt.TLS, unsafe.Pointer, uint64)) {
 	if _alloc_func == nil {
 		_alloc_func = _gmp_default_alloc
 	}
@@ -250,12 +250,12 @@ func Xmp_set_memory_functions(tls *crt.TLS, _alloc_func func(*crt.TLS, uint64) u
 	if _free_func == nil {
 		_free_func = _gmp_default_free
 	}
-	_gmp_allocate_func = _alloc_func
-	bug20530(_gmp_allocate_func)
-	_gmp_reallocate_func = _realloc_func
-	bug20530(_gmp_reallocate_func)
-	_gmp_free_func = _free_func
-	bug20530(_gmp_free_func)
+	_gmp_allocate_func_generic = _alloc_func
+	bug20530(_gmp_allocate_func_generic)
+	_gmp_reallocate_func_generic = _realloc_func
+	bug20530(_gmp_reallocate_func_generic)
+	_gmp_free_func_generic = _free_func
+	bug20530(_gmp_free_func_generic)
 }
 
 func _gmp_default_alloc(tls *crt.TLS, _size uint64) (r0 unsafe.Pointer) {
@@ -285,25 +285,25 @@ func _gmp_default_free(tls *crt.TLS, _p unsafe.Pointer, _size uint64) {
 	crt.Xfree(tls, _p)
 }
 
-var _gmp_allocate_func func(*crt.TLS, uint64) unsafe.Pointer
+var _gmp_allocate_func_generic func(*crt.TLS, uint64) unsafe.Pointer
 
 func init() {
-	_gmp_allocate_func = _gmp_default_alloc
+	_gmp_allocate_func_generic = _gmp_default_alloc
 }
 
-var _gmp_reallocate_func func(*crt.TLS, unsafe.Pointer, uint64, uint64) unsafe.Pointer
+var _gmp_reallocate_func_generic func(*crt.TLS, unsafe.Pointer, uint64, uint64) unsafe.Pointer
 
 func init() {
-	_gmp_reallocate_func = _gmp_default_realloc
+	_gmp_reallocate_func_generic = _gmp_default_realloc
 }
 
-var _gmp_free_func func(*crt.TLS, unsafe.Pointer, uint64)
+var _gmp_free_func_generic func(*crt.TLS, unsafe.Pointer, uint64)
 
 func init() {
-	_gmp_free_func = _gmp_default_free
+	_gmp_free_func_generic = _gmp_default_free
 }
 
-func Xmp_get_memory_functions(tls *crt.TLS, _alloc_func *func(*crt.TLS, uint64) unsafe.Pointer, _realloc_func *func(*crt.TLS, unsafe.Pointer, uint64, uint64) unsafe.Pointer, _free_func *func(*crt.TLS, unsafe.Pointer, uint64)) {
+func _mp_get_memory_functions_generic(tls *crt.TLS, _alloc_func *func(*crt.TLS, uint64) unsafe.Pointer, _realloc_func *func(*crt.TLS, unsafe.Pointer, uint64, uint64) unsafe.Pointer, _free_func *func(*crt.TLS, unsafe.Pointer, uint64)) {
 	if _alloc_func != nil {
 		*_alloc_func = _gmp_allocate_func
 	}
@@ -396,7 +396,7 @@ _0:
 	return _b
 }
 
-func Xmpn_add_n(tls *crt.TLS, _rp *uint64, _ap *uint64, _bp *uint64, _n int64) (r0 uint64) {
+func _mpn_add_n_generic(tls *crt.TLS, _rp *uint64, _ap *uint64, _bp *uint64, _n int64) (r0 uint64) {
 	var _i int64
 	var _cy, _1_a, _1_b, _1_r uint64
 	*func() *uint64 { _i = 0; return &_cy }() = 0
@@ -443,7 +443,7 @@ _0:
 	return _b
 }
 
-func Xmpn_sub_n(tls *crt.TLS, _rp *uint64, _ap *uint64, _bp *uint64, _n int64) (r0 uint64) {
+func _mpn_sub_n_generic(tls *crt.TLS, _rp *uint64, _ap *uint64, _bp *uint64, _n int64) (r0 uint64) {
 	var _i int64
 	var _cy, _1_a, _1_b uint64
 	*func() *uint64 { _i = 0; return &_cy }() = 0
@@ -473,7 +473,7 @@ func Xmpn_sub(tls *crt.TLS, _rp *uint64, _ap *uint64, _an int64, _bp *uint64, _b
 	return _cy
 }
 
-func Xmpn_mul_1(tls *crt.TLS, _rp *uint64, _up *uint64, _n int64, _vl uint64) (r0 uint64) {
+func _mpn_mul_1_generic(tls *crt.TLS, _rp *uint64, _up *uint64, _n int64, _vl uint64) (r0 uint64) {
 	var _2___ul, _2___vl, _2___uh, _2___vh uint32
 	var _ul, _cl, _hpl, _lpl, _2___x0, _2___x1, _2___x2, _2___x3, _2___u, _2___v uint64
 
@@ -506,7 +506,7 @@ _0:
 	return _cl
 }
 
-func Xmpn_addmul_1(tls *crt.TLS, _rp *uint64, _up *uint64, _n int64, _vl uint64) (r0 uint64) {
+func _mpn_addmul_1_generic(tls *crt.TLS, _rp *uint64, _up *uint64, _n int64, _vl uint64) (r0 uint64) {
 	var _2___ul, _2___vl, _2___uh, _2___vh uint32
 	var _ul, _cl, _hpl, _lpl, _rl, _2___x0, _2___x1, _2___x2, _2___x3, _2___u, _2___v uint64
 
@@ -542,7 +542,7 @@ _0:
 	return _cl
 }
 
-func Xmpn_submul_1(tls *crt.TLS, _rp *uint64, _up *uint64, _n int64, _vl uint64) (r0 uint64) {
+func _mpn_submul_1_generic(tls *crt.TLS, _rp *uint64, _up *uint64, _n int64, _vl uint64) (r0 uint64) {
 	var _2___ul, _2___vl, _2___uh, _2___vh uint32
 	var _ul, _cl, _hpl, _lpl, _rl, _2___x0, _2___x1, _2___x2, _2___x3, _2___u, _2___v uint64
 
@@ -578,7 +578,7 @@ _0:
 	return _cl
 }
 
-func Xmpn_mul(tls *crt.TLS, _rp *uint64, _up *uint64, _un int64, _vp *uint64, _vn int64) (r0 uint64) {
+func _mpn_mul_basecase(tls *crt.TLS, _rp *uint64, _up *uint64, _un int64, _vp *uint64, _vn int64) (r0 uint64) {
 	*elem0(_rp, uintptr(_un)) = Xmpn_mul_1(tls, _rp, _up, _un, *_vp)
 _0:
 	if preInc1(&_vn, -1) >= int64(1) {
@@ -605,12 +605,12 @@ func Xmpn_perfect_square_p(tls *crt.TLS, _p *uint64, _n int64) (r0 int32) {
 	return Xmpz_root(tls, nil, (*[1]Xmpz_srcptr)(unsafe.Pointer(Xmpz_roinit_n(tls, &_t, _p, _n))), uint64(2))
 }
 
-func Xmpz_root(tls *crt.TLS, _x *[1]Xmpz_srcptr, _y *[1]Xmpz_srcptr, _z uint64) (r0 int32) {
+func _mpz_root_generic(tls *crt.TLS, _x *[1]Xmpz_srcptr, _y *[1]Xmpz_srcptr, _z uint64) (r0 int32) {
 	var _res int32
 	var _r [1]Xmpz_srcptr
 	Xmpz_init(tls, &_r)
 	Xmpz_rootrem(tls, _x, &_r, _y, _z)
-	_res = bool2int((_r[0].X_mp_size) == int32(0))
+	_res = bool2int((_r[0].X_mp_size) == mpzField(0))
 	Xmpz_clear(tls, &_r)
 	return _res
 }
@@ -618,8 +618,8 @@ func Xmpz_root(tls *crt.TLS, _x *[1]Xmpz_srcptr, _y *[1]Xmpz_srcptr, _z uint64)
 // C comment
 //  /* MPZ interface */
 func Xmpz_init(tls *crt.TLS, _r *[1]Xmpz_srcptr) {
-	_r[0].X_mp_alloc = int32(0)
-	_r[0].X_mp_size = int32(0)
+	_r[0].X_mp_alloc = mpzField(0)
+	_r[0].X_mp_size = mpzField(0)
 	_r[0].X_mp_d = &_mpz_initØ00dummy_limbØ001
 }
 
@@ -631,10 +631,10 @@ func init() {
 
 // C comment
 //  /* x=trunc(y^(1/z)), r=y-x^z */
-func Xmpz_rootrem(tls *crt.TLS, _x *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _y *[1]Xmpz_srcptr, _z uint64) {
+func _mpz_rootrem_generic(tls *crt.TLS, _x *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _y *[1]Xmpz_srcptr, _z uint64) {
 	var _sgn int32
 	var _t, _u, _3_v [1]Xmpz_srcptr
-	_sgn = bool2int((_y[0].X_mp_size) < int32(0))
+	_sgn = bool2int((_y[0].X_mp_size) < mpzField(0))
 	if ((^_z) & uint64(_sgn)) != 0 {
 		_gmp_die(tls, str(96))
 	}
@@ -648,7 +648,7 @@ func Xmpz_rootrem(tls *crt.TLS, _x *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _y *[1]X
 		Xmpz_set(tls, _x, _y)
 	}
 	if _r != nil {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 	}
 	return
 _2:
@@ -696,12 +696,12 @@ _7:
 }
 
 func Xmpz_cmpabs_ui(tls *crt.TLS, _u *[1]Xmpz_srcptr, _v uint64) (r0 int32) {
-	if func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	if func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
-	}() > int32(1) {
+	}() > mpzField(1) {
 		return int32(1)
 	}
 	return bool2int(Xmpz_get_ui(tls, _u) > _v) - bool2int(Xmpz_get_ui(tls, _u) < _v)
@@ -709,7 +709,7 @@ func Xmpz_cmpabs_ui(tls *crt.TLS, _u *[1]Xmpz_srcptr, _v uint64) (r0 int32) {
 
 func Xmpz_get_ui(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 uint64) {
 	return func() uint64 {
-		if (_u[0].X_mp_size) == int32(0) {
+		if (_u[0].X_mp_size) == mpzField(0) {
 			return 0
 		}
 		return (*(_u[0].X_mp_d))
@@ -720,8 +720,8 @@ func Xmpz_set(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x *[1]Xmpz_srcptr) {
 	var _1_n int64
 	var _1_rp *uint64
 	if &_r[0] != &_x[0] {
-		_1_n = int64(func() int32 {
-			if (_x[0].X_mp_size) >= int32(0) {
+		_1_n = int64(func() mpzField {
+			if (_x[0].X_mp_size) >= mpzField(0) {
 				return (_x[0].X_mp_size)
 			}
 			return (-(_x[0].X_mp_size))
@@ -737,7 +737,7 @@ func Xmpz_set(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x *[1]Xmpz_srcptr) {
 	}
 }
 
-func _mpz_realloc(tls *crt.TLS, _r *[1]Xmpz_srcptr, _size int64) (r0 *uint64) {
+func _mpz_realloc_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _size int64) (r0 *uint64) {
 	_size = func() int64 {
 		if _size > int64(1) {
 			return _size
@@ -750,14 +750,14 @@ func _mpz_realloc(tls *crt.TLS, _r *[1]Xmpz_srcptr, _size int64) (r0 *uint64) {
 	}
 	_r[0].X_mp_d = _gmp_xalloc_limbs(tls, _size)
 _3:
-	_r[0].X_mp_alloc = int32(_size)
-	if int64(func() int32 {
-		if (_r[0].X_mp_size) >= int32(0) {
+	_r[0].X_mp_alloc = mpzField(_size)
+	if int64(func() mpzField {
+		if (_r[0].X_mp_size) >= mpzField(0) {
 			return (_r[0].X_mp_size)
 		}
 		return (-(_r[0].X_mp_size))
 	}()) > _size {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 	}
 	return _r[0].X_mp_d
 }
@@ -770,11 +770,11 @@ func _gmp_xalloc_limbs(tls *crt.TLS, _size int64) (r0 *uint64) {
 	return (*uint64)(_gmp_allocate_func(tls, uint64(_size)*uint64(8)))
 }
 
-func Xmpz_setbit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint64) {
+func _mpz_setbit_generic(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint64) {
 	if Xmpz_tstbit(tls, _d, _bit_index) != 0 {
 		goto _0
 	}
-	if (_d[0].X_mp_size) >= int32(0) {
+	if (_d[0].X_mp_size) >= mpzField(0) {
 		_mpz_abs_add_bit(tls, _d, _bit_index)
 		goto _2
 	}
@@ -825,8 +825,8 @@ func _mpz_abs_add_bit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint64) {
 	var _dn, _limb_index, _1_i int64
 	var _bit, _2_cy uint64
 	var _dp *uint64
-	_dn = int64(func() int32 {
-		if (_d[0].X_mp_size) >= int32(0) {
+	_dn = int64(func() mpzField {
+		if (_d[0].X_mp_size) >= mpzField(0) {
 			return (_d[0].X_mp_size)
 		}
 		return (-(_d[0].X_mp_size))
@@ -867,8 +867,8 @@ _2:
 		*elem0(_dp, uintptr(postInc1(&_dn, 1))) = _2_cy
 	}
 _9:
-	_d[0].X_mp_size = int32(func() int64 {
-		if (_d[0].X_mp_size) < int32(0) {
+	_d[0].X_mp_size = mpzField(func() int64 {
+		if (_d[0].X_mp_size) < mpzField(0) {
 			return (-_dn)
 		}
 		return _dn
@@ -879,8 +879,8 @@ func _mpz_abs_sub_bit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint64) {
 	var _dn, _limb_index int64
 	var _bit, _1___cy uint64
 	var _dp *uint64
-	_dn = int64(func() int32 {
-		if (_d[0].X_mp_size) >= int32(0) {
+	_dn = int64(func() mpzField {
+		if (_d[0].X_mp_size) >= mpzField(0) {
 			return (_d[0].X_mp_size)
 		}
 		return (-(_d[0].X_mp_size))
@@ -892,8 +892,8 @@ func _mpz_abs_sub_bit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint64) {
 	_1___cy = Xmpn_sub_1(tls, elem0(_dp, uintptr(_limb_index)), elem0(_dp, uintptr(_limb_index)), _dn-_limb_index, _bit)
 
 	_dn = _mpn_normalized_size(tls, _dp, _dn)
-	_d[0].X_mp_size = int32(func() int64 {
-		if (_d[0].X_mp_size) < int32(0) {
+	_d[0].X_mp_size = mpzField(func() int64 {
+		if (_d[0].X_mp_size) < mpzField(0) {
 			return (-_dn)
 		}
 		return _dn
@@ -907,8 +907,8 @@ func Xmpz_sizeinbase(tls *crt.TLS, _u *[1]Xmpz_srcptr, _base int32) (r0 uint64)
 	var _up, _tp *uint64
 	var _bi Tgmp_div_inverse
 
-	_un = int64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = int64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -1021,7 +1021,7 @@ _8:
 //
 //       m = floor( (B^3-1) / (B u1 + u0)) - B
 //  */
-func Xmpn_invert_3by2(tls *crt.TLS, _u1 uint64, _u0 uint64) (r0 uint64) {
+func _mpn_invert_3by2_generic(tls *crt.TLS, _u1 uint64, _u0 uint64) (r0 uint64) {
 	var _ul, _uh, _qh, _8___ul, _8___vl, _8___uh, _8___vh uint32
 	var _r, _p, _m, _ql, _5_th, _5_tl, _8___x0, _8___x1, _8___x2, _8___x3, _8___u, _8___v uint64
 
@@ -1101,7 +1101,7 @@ _5:
 // C comment
 //  /* Not matching current public gmp interface, rather corresponding to
 //     the sbpi1_div_* functions. */
-func _mpn_div_qr_1_preinv(tls *crt.TLS, _qp *uint64, _np *uint64, _nn int64, _inv *Tgmp_div_inverse) (r0 uint64) {
+func _mpn_div_qr_1_preinv_generic(tls *crt.TLS, _qp *uint64, _np *uint64, _nn int64, _inv *Tgmp_div_inverse) (r0 uint64) {
 	var _4___ul, _4___vl, _4___uh, _4___vh uint32
 	var _d, _di, _r, _2_q, _3__qh, _3__ql, _3__r, _3__mask, _4___x0, _4___x1, _4___x2, _4___x3, _4___u, _4___v, _5___x uint64
 	var _tp *uint64
@@ -1161,7 +1161,7 @@ _3:
 	return _r >> uint(int32(_inv.Xshift))
 }
 
-func Xmpn_lshift(tls *crt.TLS, _rp *uint64, _up *uint64, _n int64, _cnt uint32) (r0 uint64) {
+func _mpn_lshift_generic(tls *crt.TLS, _rp *uint64, _up *uint64, _n int64, _cnt uint32) (r0 uint64) {
 	var _tnc uint32
 	var _high_limb, _low_limb, _retval uint64
 
@@ -1187,10 +1187,10 @@ func Xmpz_swap(tls *crt.TLS, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _3___mp_ptr_swap__tmp *uint64
 	_1___mp_size_t_swap__tmp = int64(_u[0].X_mp_size)
 	_u[0].X_mp_size = _v[0].X_mp_size
-	_v[0].X_mp_size = int32(_1___mp_size_t_swap__tmp)
+	_v[0].X_mp_size = mpzField(_1___mp_size_t_swap__tmp)
 	_2___mp_size_t_swap__tmp = int64(_u[0].X_mp_alloc)
 	_u[0].X_mp_alloc = _v[0].X_mp_alloc
-	_v[0].X_mp_alloc = int32(_2___mp_size_t_swap__tmp)
+	_v[0].X_mp_alloc = mpzField(_2___mp_size_t_swap__tmp)
 	_3___mp_ptr_swap__tmp = _u[0].X_mp_d
 	_u[0].X_mp_d = _v[0].X_mp_d
 	_v[0].X_mp_d = _3___mp_ptr_swap__tmp
@@ -1202,7 +1202,7 @@ func Xmpz_tdiv_q(tls *crt.TLS, _q *[1]Xmpz_srcptr, _n *[1]Xmpz_srcptr, _d *[1]Xm
 
 // C comment
 //  /* Allows q or r to be zero. Returns 1 iff remainder is non-zero. */
-func _mpz_div_qr(tls *crt.TLS, _q *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _n *[1]Xmpz_srcptr, _d *[1]Xmpz_srcptr, _mode int32) (r0 int32) {
+func _mpz_div_qr_generic(tls *crt.TLS, _q *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _n *[1]Xmpz_srcptr, _d *[1]Xmpz_srcptr, _mode int32) (r0 int32) {
 	var _ns, _ds, _nn, _dn, _qs, _6_qn, _6_rn int64
 	var _6_np, _6_qp *uint64
 	var _6_tq, _6_tr [1]Xmpz_srcptr
@@ -1215,10 +1215,10 @@ func _mpz_div_qr(tls *crt.TLS, _q *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _n *[1]Xm
 		goto _1
 	}
 	if _q != nil {
-		_q[0].X_mp_size = int32(0)
+		_q[0].X_mp_size = mpzField(0)
 	}
 	if _r != nil {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 	}
 	return int32(0)
 
@@ -1265,7 +1265,7 @@ _15:
 		Xmpz_set(tls, _r, _n)
 	}
 	if _q != nil {
-		_q[0].X_mp_size = int32(0)
+		_q[0].X_mp_size = mpzField(0)
 	}
 _18:
 	return int32(1)
@@ -1284,7 +1284,7 @@ _23:
 	_mpn_div_qr(tls, _6_qp, _6_np, _nn, _d[0].X_mp_d, _dn)
 	if _6_qp != nil {
 		_6_qn -= int64(bool2int((*elem0(_6_qp, uintptr(_6_qn-int64(1)))) == 0))
-		_6_tq[0].X_mp_size = int32(func() int64 {
+		_6_tq[0].X_mp_size = mpzField(func() int64 {
 			if _qs < 0 {
 				return (-_6_qn)
 			}
@@ -1292,7 +1292,7 @@ _23:
 		}())
 	}
 	_6_rn = _mpn_normalized_size(tls, _6_np, _dn)
-	_6_tr[0].X_mp_size = int32(func() int64 {
+	_6_tr[0].X_mp_size = mpzField(func() int64 {
 		if _ns < 0 {
 			return (-_6_rn)
 		}
@@ -1332,14 +1332,14 @@ _37:
 
 func Xmpz_sub(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr) {
 	var _rn int64
-	if ((_a[0].X_mp_size) ^ (_b[0].X_mp_size)) >= int32(0) {
+	if ((_a[0].X_mp_size) ^ (_b[0].X_mp_size)) >= mpzField(0) {
 		_rn = _mpz_abs_sub(tls, _r, _a, _b)
 		goto _1
 	}
 	_rn = _mpz_abs_add(tls, _r, _a, _b)
 _1:
-	_r[0].X_mp_size = int32(func() int64 {
-		if (_a[0].X_mp_size) >= int32(0) {
+	_r[0].X_mp_size = mpzField(func() int64 {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return _rn
 		}
 		return (-_rn)
@@ -1351,14 +1351,14 @@ func _mpz_abs_sub(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b *[1]X
 	var _an, _bn int64
 	var _2___cy, _4___cy uint64
 	var _rp *uint64
-	_an = int64(func() int32 {
-		if (_a[0].X_mp_size) >= int32(0) {
+	_an = int64(func() mpzField {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return (_a[0].X_mp_size)
 		}
 		return (-(_a[0].X_mp_size))
 	}())
-	_bn = int64(func() int32 {
-		if (_b[0].X_mp_size) >= int32(0) {
+	_bn = int64(func() mpzField {
+		if (_b[0].X_mp_size) >= mpzField(0) {
 			return (_b[0].X_mp_size)
 		}
 		return (-(_b[0].X_mp_size))
@@ -1414,14 +1414,14 @@ func _mpz_abs_add(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b *[1]X
 	var _cy uint64
 	var _rp *uint64
 	var _2___mpz_srcptr_swap__tmp *Xmpz_srcptr
-	_an = int64(func() int32 {
-		if (_a[0].X_mp_size) >= int32(0) {
+	_an = int64(func() mpzField {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return (_a[0].X_mp_size)
 		}
 		return (-(_a[0].X_mp_size))
 	}())
-	_bn = int64(func() int32 {
-		if (_b[0].X_mp_size) >= int32(0) {
+	_bn = int64(func() mpzField {
+		if (_b[0].X_mp_size) >= mpzField(0) {
 			return (_b[0].X_mp_size)
 		}
 		return (-(_b[0].X_mp_size))
@@ -1449,29 +1449,29 @@ _4:
 
 func Xmpz_set_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x uint64) {
 	if _x > 0 {
-		_r[0].X_mp_size = int32(1)
+		_r[0].X_mp_size = mpzField(1)
 		*func() *uint64 {
-			if int32(1) > (_r[0].X_mp_alloc) {
+			if mpzField(1) > (_r[0].X_mp_alloc) {
 				return _mpz_realloc(tls, _r, int64(1))
 			}
 			return (_r[0].X_mp_d)
 		}() = _x
 		goto _3
 	}
-	_r[0].X_mp_size = int32(0)
+	_r[0].X_mp_size = mpzField(0)
 _3:
 }
 
 func Xmpz_add(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr) {
 	var _rn int64
-	if ((_a[0].X_mp_size) ^ (_b[0].X_mp_size)) >= int32(0) {
+	if ((_a[0].X_mp_size) ^ (_b[0].X_mp_size)) >= mpzField(0) {
 		_rn = _mpz_abs_add(tls, _r, _a, _b)
 		goto _1
 	}
 	_rn = _mpz_abs_sub(tls, _r, _a, _b)
 _1:
-	_r[0].X_mp_size = int32(func() int64 {
-		if (_a[0].X_mp_size) >= int32(0) {
+	_r[0].X_mp_size = mpzField(func() int64 {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return _rn
 		}
 		return (-_rn)
@@ -1485,9 +1485,9 @@ func Xmpz_set_si(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x int64) {
 		Xmpz_set_ui(tls, _r, uint64(_x))
 		goto _1
 	}
-	_r[0].X_mp_size = int32(-1)
+	_r[0].X_mp_size = mpzField(-1)
 	*func() *uint64 {
-		if int32(1) > (_r[0].X_mp_alloc) {
+		if mpzField(1) > (_r[0].X_mp_alloc) {
 			return _mpz_realloc(tls, _r, int64(1))
 		}
 		return (_r[0].X_mp_d)
@@ -1503,12 +1503,12 @@ func Xmpz_init_set(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x *[1]Xmpz_srcptr) {
 // C comment
 //  /* The utility of this function is a bit limited, since many functions
 //     assigns the result variable using mpz_swap. */
-func Xmpz_init2(tls *crt.TLS, _r *[1]Xmpz_srcptr, _bits uint64) {
+func _mpz_init2_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _bits uint64) {
 	var _rn int64
 	_bits -= uint64(bool2int(_bits != 0))
 	_rn = int64(uint64(1) + (_bits / uint64(64)))
-	_r[0].X_mp_alloc = int32(_rn)
-	_r[0].X_mp_size = int32(0)
+	_r[0].X_mp_alloc = mpzField(_rn)
+	_r[0].X_mp_size = mpzField(0)
 	_r[0].X_mp_d = _gmp_xalloc_limbs(tls, _rn)
 }
 
@@ -1641,7 +1641,7 @@ _3:
 	_ = _2___cy
 }
 
-func _mpn_div_qr_2_preinv(tls *crt.TLS, _qp *uint64, _rp *uint64, _np *uint64, _nn int64, _inv *Tgmp_div_inverse) {
+func _mpn_div_qr_2_preinv_generic(tls *crt.TLS, _qp *uint64, _rp *uint64, _np *uint64, _nn int64, _inv *Tgmp_div_inverse) {
 	var _i int64
 	var _shift, _4___ul, _4___vl, _4___uh, _4___vh, _7___ul, _7___vl, _7___uh, _7___vh uint32
 	var _d1, _d0, _di, _r1, _r0, _2_n0, _2_q, _3__q0, _3__t1, _3__t0, _3__mask, _4___x0, _4___x1, _4___x2, _4___x3, _4___u, _4___v, _5___x, _6___x, _7___x0, _7___x1, _7___x2, _7___x3, _7___u, _7___v, _8___x, _9___x, _12___x uint64
@@ -1740,7 +1740,7 @@ _12:
 	*_rp = _r0
 }
 
-func _mpn_div_qr_pi1(tls *crt.TLS, _qp *uint64, _np *uint64, _nn int64, _n1 uint64, _dp *uint64, _dn int64, _dinv uint64) {
+func _mpn_div_qr_pi1_generic(tls *crt.TLS, _qp *uint64, _np *uint64, _nn int64, _n1 uint64, _dp *uint64, _dn int64, _dinv uint64) {
 	var _i int64
 	var _5___ul, _5___vl, _5___uh, _5___vh, _8___ul, _8___vl, _8___uh, _8___vh uint32
 	var _d1, _d0, _cy, _cy1, _q, _1_n0, _4__q0, _4__t1, _4__t0, _4__mask, _5___x0, _5___x1, _5___x2, _5___x3, _5___u, _5___v, _6___x, _7___x, _8___x0, _8___x1, _8___x2, _8___x3, _8___u, _8___v, _9___x, _10___x, _13___x uint64
@@ -1839,7 +1839,7 @@ _3:
 	*elem0(_np, uintptr(_dn-int64(1))) = _n1
 }
 
-func Xmpn_rshift(tls *crt.TLS, _rp *uint64, _up *uint64, _n int64, _cnt uint32) (r0 uint64) {
+func _mpn_rshift_generic(tls *crt.TLS, _rp *uint64, _up *uint64, _n int64, _cnt uint32) (r0 uint64) {
 	var _tnc uint32
 	var _high_limb, _low_limb, _retval uint64
 
@@ -1859,11 +1859,11 @@ _0:
 }
 
 func Xmpz_sub_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b uint64) {
-	if (_a[0].X_mp_size) < int32(0) {
-		_r[0].X_mp_size = int32(-_mpz_abs_add_ui(tls, _r, _a, _b))
+	if (_a[0].X_mp_size) < mpzField(0) {
+		_r[0].X_mp_size = mpzField(-_mpz_abs_add_ui(tls, _r, _a, _b))
 		goto _1
 	}
-	_r[0].X_mp_size = int32(_mpz_abs_sub_ui(tls, _r, _a, _b))
+	_r[0].X_mp_size = mpzField(_mpz_abs_sub_ui(tls, _r, _a, _b))
 _1:
 }
 
@@ -1873,15 +1873,15 @@ func _mpz_abs_add_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b ui
 	var _an int64
 	var _cy uint64
 	var _rp *uint64
-	_an = int64(func() int32 {
-		if (_a[0].X_mp_size) >= int32(0) {
+	_an = int64(func() mpzField {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return (_a[0].X_mp_size)
 		}
 		return (-(_a[0].X_mp_size))
 	}())
 	if _an == 0 {
 		*func() *uint64 {
-			if int32(1) > (_r[0].X_mp_alloc) {
+			if mpzField(1) > (_r[0].X_mp_alloc) {
 				return _mpz_realloc(tls, _r, int64(1))
 			}
 			return (_r[0].X_mp_d)
@@ -1910,15 +1910,15 @@ func _mpz_abs_sub_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b ui
 	var _an int64
 	var _4___cy uint64
 	var _rp *uint64
-	_an = int64(func() int32 {
-		if (_a[0].X_mp_size) >= int32(0) {
+	_an = int64(func() mpzField {
+		if (_a[0].X_mp_size) >= mpzField(0) {
 			return (_a[0].X_mp_size)
 		}
 		return (-(_a[0].X_mp_size))
 	}())
 	if _an == 0 {
 		*func() *uint64 {
-			if int32(1) > (_r[0].X_mp_alloc) {
+			if mpzField(1) > (_r[0].X_mp_alloc) {
 				return _mpz_realloc(tls, _r, int64(1))
 			}
 			return (_r[0].X_mp_d)
@@ -1943,11 +1943,11 @@ func _mpz_abs_sub_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b ui
 }
 
 func Xmpz_add_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a *[1]Xmpz_srcptr, _b uint64) {
-	if (_a[0].X_mp_size) >= int32(0) {
-		_r[0].X_mp_size = int32(_mpz_abs_add_ui(tls, _r, _a, _b))
+	if (_a[0].X_mp_size) >= mpzField(0) {
+		_r[0].X_mp_size = mpzField(_mpz_abs_add_ui(tls, _r, _a, _b))
 		goto _1
 	}
-	_r[0].X_mp_size = int32(-_mpz_abs_sub_ui(tls, _r, _a, _b))
+	_r[0].X_mp_size = mpzField(-_mpz_abs_sub_ui(tls, _r, _a, _b))
 _1:
 }
 
@@ -1967,7 +1967,7 @@ func _mpz_div_q_2exp(tls *crt.TLS, _q *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _bit_
 	var _qp *uint64
 	_un = int64(_u[0].X_mp_size)
 	if _un == 0 {
-		_q[0].X_mp_size = int32(0)
+		_q[0].X_mp_size = mpzField(0)
 		return
 	}
 	_limb_cnt = int64(_bit_index / uint64(64))
@@ -2007,7 +2007,7 @@ _8:
 	Xmpn_copyi(tls, _qp, elem0(_u[0].X_mp_d, uintptr(_limb_cnt)), _qn)
 _14:
 _10:
-	_q[0].X_mp_size = int32(_qn)
+	_q[0].X_mp_size = mpzField(_qn)
 	if _adjust != 0 {
 		Xmpz_add_ui(tls, _q, _q, uint64(1))
 	}
@@ -2022,20 +2022,20 @@ func Xmpz_neg(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr) {
 }
 
 func Xmpz_cmpabs(tls *crt.TLS, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) (r0 int32) {
-	return _mpn_cmp4(tls, _u[0].X_mp_d, int64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	return _mpn_cmp4(tls, _u[0].X_mp_d, int64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
-	}()), _v[0].X_mp_d, int64(func() int32 {
-		if (_v[0].X_mp_size) >= int32(0) {
+	}()), _v[0].X_mp_d, int64(func() mpzField {
+		if (_v[0].X_mp_size) >= mpzField(0) {
 			return (_v[0].X_mp_size)
 		}
 		return (-(_v[0].X_mp_size))
 	}()))
 }
 
-func Xmpz_pow_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e uint64) {
+func _mpz_pow_ui_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e uint64) {
 	var _bit uint64
 	var _tr [1]Xmpz_srcptr
 	Xmpz_init_set_ui(tls, &_tr, uint64(1))
@@ -2066,7 +2066,7 @@ func Xmpz_mul(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	_un = int64(_u[0].X_mp_size)
 	_vn = int64(_v[0].X_mp_size)
 	if (_un == 0) || (_vn == 0) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_sign = bool2int((_un ^ _vn) < 0)
@@ -2092,7 +2092,7 @@ func Xmpz_mul(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 _7:
 	_rn = _un + _vn
 	_rn -= int64(bool2int((*elem0(_tp, uintptr(_rn-int64(1)))) == 0))
-	_t[0].X_mp_size = int32(func() int64 {
+	_t[0].X_mp_size = mpzField(func() int64 {
 		if _sign != 0 {
 			return (-_rn)
 		}
@@ -2108,7 +2108,7 @@ func Xmpz_mul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint64
 	var _tp *uint64
 	_us = int64(_u[0].X_mp_size)
 	if (_us == 0) || (_v == 0) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_un = func() int64 {
@@ -2126,7 +2126,7 @@ func Xmpz_mul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint64
 	_cy = Xmpn_mul_1(tls, _tp, _u[0].X_mp_d, _un, _v)
 	*elem0(_tp, uintptr(_un)) = _cy
 	_un += int64(bool2int(_cy > 0))
-	_r[0].X_mp_size = int32(func() int64 {
+	_r[0].X_mp_size = mpzField(func() int64 {
 		if _us < 0 {
 			return (-_un)
 		}
@@ -2147,10 +2147,10 @@ func _mpz_div_qr_ui(tls *crt.TLS, _q *[1]Xmpz_srcptr, _r *[1]Xmpz_srcptr, _n *[1
 		goto _0
 	}
 	if _q != nil {
-		_q[0].X_mp_size = int32(0)
+		_q[0].X_mp_size = mpzField(0)
 	}
 	if _r != nil {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 	}
 	return 0
 
@@ -2195,17 +2195,17 @@ _16:
 _15:
 	if _r != nil {
 		*func() *uint64 {
-			if int32(1) > (_r[0].X_mp_alloc) {
+			if mpzField(1) > (_r[0].X_mp_alloc) {
 				return _mpz_realloc(tls, _r, int64(1))
 			}
 			return (_r[0].X_mp_d)
 		}() = _rl
-		_r[0].X_mp_size = int32(_rs)
+		_r[0].X_mp_size = mpzField(_rs)
 	}
 	if _q != nil {
 		_qn -= int64(bool2int((*elem0(_qp, uintptr(_qn-int64(1)))) == 0))
 
-		_q[0].X_mp_size = int32(func() int64 {
+		_q[0].X_mp_size = mpzField(func() int64 {
 			if _ns < 0 {
 				return (-_qn)
 			}
@@ -2266,13 +2266,13 @@ _0:
 }
 
 func Xmpz_roinit_n(tls *crt.TLS, _x *[1]Xmpz_srcptr, _xp *uint64, _xs int64) (r0 *Xmpz_srcptr) {
-	_x[0].X_mp_alloc = int32(0)
+	_x[0].X_mp_alloc = mpzField(0)
 	_x[0].X_mp_d = _xp
 	Xmpz_limbs_finish(tls, _x, _xs)
 	return &_x[0]
 }
 
-func Xmpz_limbs_finish(tls *crt.TLS, _x *[1]Xmpz_srcptr, _xs int64) {
+func _mpz_limbs_finish_generic(tls *crt.TLS, _x *[1]Xmpz_srcptr, _xs int64) {
 	var _xn int64
 	_xn = _mpn_normalized_size(tls, _x[0].X_mp_d, func() int64 {
 		if _xs >= 0 {
@@ -2280,7 +2280,7 @@ func Xmpz_limbs_finish(tls *crt.TLS, _x *[1]Xmpz_srcptr, _xs int64) {
 		}
 		return (-_xs)
 	}())
-	_x[0].X_mp_size = int32(func() int64 {
+	_x[0].X_mp_size = mpzField(func() int64 {
 		if _xs < 0 {
 			return (-_xn)
 		}
@@ -2720,7 +2720,7 @@ _5:
 // C comment
 //  /* MPZ comparisons and the like. */
 func Xmpz_sgn(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 int32) {
-	return bool2int((_u[0].X_mp_size) > int32(0)) - bool2int((_u[0].X_mp_size) < int32(0))
+	return bool2int((_u[0].X_mp_size) > mpzField(0)) - bool2int((_u[0].X_mp_size) < mpzField(0))
 }
 
 func Xmpz_cmp_si(tls *crt.TLS, _u *[1]Xmpz_srcptr, _v int64) (r0 int32) {
@@ -2769,7 +2769,7 @@ func Xmpz_cmp(tls *crt.TLS, _a *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr) (r0 int32) {
 }
 
 func Xmpz_cmp_d(tls *crt.TLS, _x *[1]Xmpz_srcptr, _d float64) (r0 int32) {
-	if (_x[0].X_mp_size) >= int32(0) {
+	if (_x[0].X_mp_size) >= mpzField(0) {
 		goto _0
 	}
 	if _d >= float64(0) {
@@ -2840,8 +2840,8 @@ _2:
 
 func Xmpz_abs(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr) {
 	Xmpz_set(tls, _r, _u)
-	_r[0].X_mp_size = func() int32 {
-		if (_r[0].X_mp_size) >= int32(0) {
+	_r[0].X_mp_size = func() mpzField {
+		if (_r[0].X_mp_size) >= mpzField(0) {
 			return (_r[0].X_mp_size)
 		}
 		return (-(_r[0].X_mp_size))
@@ -2849,11 +2849,11 @@ func Xmpz_abs(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr) {
 }
 
 func Xmpz_ui_sub(tls *crt.TLS, _r *[1]Xmpz_srcptr, _a uint64, _b *[1]Xmpz_srcptr) {
-	if (_b[0].X_mp_size) < int32(0) {
-		_r[0].X_mp_size = int32(_mpz_abs_add_ui(tls, _r, _b, _a))
+	if (_b[0].X_mp_size) < mpzField(0) {
+		_r[0].X_mp_size = mpzField(_mpz_abs_add_ui(tls, _r, _b, _a))
 		goto _1
 	}
-	_r[0].X_mp_size = int32(-_mpz_abs_sub_ui(tls, _r, _b, _a))
+	_r[0].X_mp_size = mpzField(-_mpz_abs_sub_ui(tls, _r, _b, _a))
 _1:
 }
 
@@ -2869,19 +2869,19 @@ func Xmpz_mul_si(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v int64)
 _1:
 }
 
-func Xmpz_mul_2exp(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _bits uint64) {
+func _mpz_mul_2exp_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _bits uint64) {
 	var _un, _rn, _limbs int64
 	var _shift uint32
 	var _2_cy uint64
 	var _rp *uint64
-	_un = int64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = int64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
 	}())
 	if _un == 0 {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_limbs = int64(_bits / uint64(64))
@@ -2902,15 +2902,15 @@ func Xmpz_mul_2exp(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _bits u
 	Xmpn_copyd(tls, elem0(_rp, uintptr(_limbs)), _u[0].X_mp_d, _un)
 _6:
 	Xmpn_zero(tls, _rp, _limbs)
-	_r[0].X_mp_size = int32(func() int64 {
-		if (_u[0].X_mp_size) < int32(0) {
+	_r[0].X_mp_size = mpzField(func() int64 {
+		if (_u[0].X_mp_size) < mpzField(0) {
 			return (-_rn)
 		}
 		return _rn
 	}())
 }
 
-func Xmpz_addmul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint64) {
+func _mpz_addmul_ui_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint64) {
 	var _t [1]Xmpz_srcptr
 	Xmpz_init(tls, &_t)
 	Xmpz_mul_ui(tls, &_t, _u, _v)
@@ -2918,7 +2918,7 @@ func Xmpz_addmul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uin
 	Xmpz_clear(tls, &_t)
 }
 
-func Xmpz_addmul(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
+func _mpz_addmul_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _t [1]Xmpz_srcptr
 	Xmpz_init(tls, &_t)
 	Xmpz_mul(tls, &_t, _u, _v)
@@ -2926,7 +2926,7 @@ func Xmpz_addmul(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xm
 	Xmpz_clear(tls, &_t)
 }
 
-func Xmpz_submul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint64) {
+func _mpz_submul_ui_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint64) {
 	var _t [1]Xmpz_srcptr
 	Xmpz_init(tls, &_t)
 	Xmpz_mul_ui(tls, &_t, _u, _v)
@@ -2934,7 +2934,7 @@ func Xmpz_submul_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uin
 	Xmpz_clear(tls, &_t)
 }
 
-func Xmpz_submul(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
+func _mpz_submul_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _t [1]Xmpz_srcptr
 	Xmpz_init(tls, &_t)
 	Xmpz_mul(tls, &_t, _u, _v)
@@ -2992,7 +2992,7 @@ func _mpz_div_r_2exp(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _bit_
 	var _rp *uint64
 	_us = int64(_u[0].X_mp_size)
 	if (_us == 0) || (_bit_index == 0) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_rn = int64(((_bit_index + uint64(64)) - uint64(1)) / uint64(64))
@@ -3059,7 +3059,7 @@ _6:
 	}
 _17:
 	_rn = _mpn_normalized_size(tls, _rp, _rn)
-	_r[0].X_mp_size = int32(func() int64 {
+	_r[0].X_mp_size = mpzField(func() int64 {
 		if _us < 0 {
 			return (-_rn)
 		}
@@ -3078,7 +3078,7 @@ func Xmpz_tdiv_r_2exp(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _cnt
 
 func Xmpz_mod(tls *crt.TLS, _r *[1]Xmpz_srcptr, _n *[1]Xmpz_srcptr, _d *[1]Xmpz_srcptr) {
 	_mpz_div_qr(tls, nil, _r, _n, _d, func() int32 {
-		if (_d[0].X_mp_size) >= int32(0) {
+		if (_d[0].X_mp_size) >= mpzField(0) {
 			return int32(0)
 		}
 		return int32(1)
@@ -3178,8 +3178,8 @@ func Xmpz_gcd_ui(tls *crt.TLS, _g *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint64
 	}
 	goto _2
 _0:
-	_un = int64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = int64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -3267,15 +3267,15 @@ _18:
 	return _u << uint(int32(_shift))
 }
 
-func Xmpz_gcd(tls *crt.TLS, _g *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
+func _mpz_gcd_generic(tls *crt.TLS, _g *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _4_c int32
 	var _uz, _vz, _gz, _6_vl, _6_ul uint64
 	var _tu, _tv [1]Xmpz_srcptr
-	if (_u[0].X_mp_size) == int32(0) {
+	if (_u[0].X_mp_size) == mpzField(0) {
 		Xmpz_abs(tls, _g, _v)
 		return
 	}
-	if (_v[0].X_mp_size) == int32(0) {
+	if (_v[0].X_mp_size) == mpzField(0) {
 		Xmpz_abs(tls, _g, _u)
 		return
 	}
@@ -3295,7 +3295,7 @@ func Xmpz_gcd(tls *crt.TLS, _g *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 		Xmpz_swap(tls, &_tu, &_tv)
 	}
 	Xmpz_tdiv_r(tls, &_tu, &_tu, &_tv)
-	if (_tu[0].X_mp_size) == int32(0) {
+	if (_tu[0].X_mp_size) == mpzField(0) {
 		Xmpz_swap(tls, _g, &_tv)
 		goto _9
 	}
@@ -3309,7 +3309,7 @@ _5:
 	if _4_c < int32(0) {
 		Xmpz_swap(tls, &_tu, &_tv)
 	}
-	if (_tv[0].X_mp_size) == int32(1) {
+	if (_tv[0].X_mp_size) == mpzField(1) {
 		_6_vl = *(_tv[0].X_mp_d)
 		_6_ul = Xmpz_tdiv_ui(tls, &_tu, _6_vl)
 		Xmpz_set_ui(tls, _g, _mpn_gcd_11(tls, _6_ul, _6_vl))
@@ -3331,13 +3331,13 @@ func _mpz_make_odd(tls *crt.TLS, _r *[1]Xmpz_srcptr) (r0 uint64) {
 	return _shift
 }
 
-func Xmpz_gcdext(tls *crt.TLS, _g *[1]Xmpz_srcptr, _s *[1]Xmpz_srcptr, _t *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
+func _mpz_gcdext_generic(tls *crt.TLS, _g *[1]Xmpz_srcptr, _s *[1]Xmpz_srcptr, _t *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _8_c int32
 	var _1_sign, _2_sign int64
 	var _uz, _vz, _gz, _power, _6___mp_bitcnt_t_swap__tmp, _7_shift uint64
 	var _tu, _tv, _s0, _s1, _t0, _t1 [1]Xmpz_srcptr
 	var _4___mpz_srcptr_swap__tmp, _5___mpz_ptr_swap__tmp *Xmpz_srcptr
-	if (_u[0].X_mp_size) != int32(0) {
+	if (_u[0].X_mp_size) != mpzField(0) {
 		goto _0
 	}
 	_1_sign = int64(Xmpz_sgn(tls, _v))
@@ -3350,7 +3350,7 @@ func Xmpz_gcdext(tls *crt.TLS, _g *[1]Xmpz_srcptr, _s *[1]Xmpz_srcptr, _t *[1]Xm
 	}
 	return
 _0:
-	if (_v[0].X_mp_size) != int32(0) {
+	if (_v[0].X_mp_size) != mpzField(0) {
 		goto _3
 	}
 	_2_sign = int64(Xmpz_sgn(tls, _u))
@@ -3400,7 +3400,7 @@ _8:
 	Xmpz_mul_2exp(tls, &_t1, &_t1, _uz)
 	Xmpz_setbit(tls, &_s1, _vz)
 	_power = _uz + _vz
-	if (_tu[0].X_mp_size) <= int32(0) {
+	if (_tu[0].X_mp_size) <= mpzField(0) {
 		goto _12
 	}
 	_7_shift = _mpz_make_odd(tls, &_tu)
@@ -3442,7 +3442,7 @@ _19:
 	if postInc2(&_power, uint64(18446744073709551615)) <= 0 {
 		goto _20
 	}
-	if (bool2int((_s0[0].X_mp_size) != int32(0))&int32(*(_s0[0].X_mp_d))) != 0 || (bool2int((_t0[0].X_mp_size) != int32(0))&int32(*(_t0[0].X_mp_d))) != 0 {
+	if (bool2int((_s0[0].X_mp_size) != mpzField(0))&int32(*(_s0[0].X_mp_d))) != 0 || (bool2int((_t0[0].X_mp_size) != mpzField(0))&int32(*(_t0[0].X_mp_d))) != 0 {
 		Xmpz_sub(tls, &_s0, &_s0, &_s1)
 		Xmpz_add(tls, &_t0, &_t0, &_t1)
 	}
@@ -3455,10 +3455,10 @@ _20:
 		Xmpz_swap(tls, &_s0, &_s1)
 		Xmpz_sub(tls, &_t0, &_t0, &_t1)
 	}
-	if (_u[0].X_mp_size) < int32(0) {
+	if (_u[0].X_mp_size) < mpzField(0) {
 		Xmpz_neg(tls, &_s0, &_s0)
 	}
-	if (_v[0].X_mp_size) < int32(0) {
+	if (_v[0].X_mp_size) < mpzField(0) {
 		Xmpz_neg(tls, &_t0, &_t0)
 	}
 	Xmpz_swap(tls, _g, &_tv)
@@ -3478,8 +3478,8 @@ _20:
 }
 
 func Xmpz_lcm_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint64) {
-	if (_v == 0) || ((_u[0].X_mp_size) == int32(0)) {
-		_r[0].X_mp_size = int32(0)
+	if (_v == 0) || ((_u[0].X_mp_size) == mpzField(0)) {
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_v /= Xmpz_gcd_ui(tls, nil, _u, _v)
@@ -3487,10 +3487,10 @@ func Xmpz_lcm_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v uint64
 	Xmpz_abs(tls, _r, _r)
 }
 
-func Xmpz_lcm(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
+func _mpz_lcm_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_srcptr) {
 	var _g [1]Xmpz_srcptr
-	if ((_u[0].X_mp_size) == int32(0)) || ((_v[0].X_mp_size) == int32(0)) {
-		_r[0].X_mp_size = int32(0)
+	if ((_u[0].X_mp_size) == mpzField(0)) || ((_v[0].X_mp_size) == mpzField(0)) {
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	Xmpz_init(tls, &_g)
@@ -3501,10 +3501,10 @@ func Xmpz_lcm(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	Xmpz_abs(tls, _r, _r)
 }
 
-func Xmpz_invert(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _m *[1]Xmpz_srcptr) (r0 int32) {
+func _mpz_invert_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _m *[1]Xmpz_srcptr) (r0 int32) {
 	var _invertible int32
 	var _g, _tr [1]Xmpz_srcptr
-	if ((_u[0].X_mp_size) == int32(0)) || (Xmpz_cmpabs_ui(tls, _m, uint64(1)) <= int32(0)) {
+	if ((_u[0].X_mp_size) == mpzField(0)) || (Xmpz_cmpabs_ui(tls, _m, uint64(1)) <= int32(0)) {
 		return int32(0)
 	}
 	Xmpz_init(tls, &_g)
@@ -3514,10 +3514,10 @@ func Xmpz_invert(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _m *[1]Xm
 	if _invertible == 0 {
 		goto _2
 	}
-	if (_tr[0].X_mp_size) >= int32(0) {
+	if (_tr[0].X_mp_size) >= mpzField(0) {
 		goto _3
 	}
-	if (_m[0].X_mp_size) >= int32(0) {
+	if (_m[0].X_mp_size) >= mpzField(0) {
 		Xmpz_add(tls, &_tr, &_tr, _m)
 		goto _5
 	}
@@ -3542,18 +3542,18 @@ func Xmpz_sqrt(tls *crt.TLS, _s *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr) {
 }
 
 func Xmpz_perfect_square_p(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 int32) {
-	if (_u[0].X_mp_size) <= int32(0) {
-		return bool2int((_u[0].X_mp_size) == int32(0))
+	if (_u[0].X_mp_size) <= mpzField(0) {
+		return bool2int((_u[0].X_mp_size) == mpzField(0))
 	}
 	return Xmpz_root(tls, nil, _u, uint64(2))
 }
 
-func Xmpz_ui_pow_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _blimb uint64, _e uint64) {
+func _mpz_ui_pow_ui_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _blimb uint64, _e uint64) {
 	var _b [1]Xmpz_srcptr
 	Xmpz_pow_ui(tls, _r, (*[1]Xmpz_srcptr)(unsafe.Pointer(Xmpz_roinit_n(tls, &_b, &_blimb, int64(1)))), _e)
 }
 
-func Xmpz_powm(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e *[1]Xmpz_srcptr, _m *[1]Xmpz_srcptr) {
+func _mpz_powm_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e *[1]Xmpz_srcptr, _m *[1]Xmpz_srcptr) {
 	var _en, _mn, _5_bn int64
 	var _shift uint32
 	var _3___cy, _8___cy, _9_w, _9_bit uint64
@@ -3561,14 +3561,14 @@ func Xmpz_powm(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e *[1]Xmpz
 	var _minv Tgmp_div_inverse
 	var _tr, _base [1]Xmpz_srcptr
 	_tp = nil
-	_en = int64(func() int32 {
-		if (_e[0].X_mp_size) >= int32(0) {
+	_en = int64(func() mpzField {
+		if (_e[0].X_mp_size) >= mpzField(0) {
 			return (_e[0].X_mp_size)
 		}
 		return (-(_e[0].X_mp_size))
 	}())
-	_mn = int64(func() int32 {
-		if (_m[0].X_mp_size) >= int32(0) {
+	_mn = int64(func() mpzField {
+		if (_m[0].X_mp_size) >= mpzField(0) {
 			return (_m[0].X_mp_size)
 		}
 		return (-(_m[0].X_mp_size))
@@ -3593,7 +3593,7 @@ func Xmpz_powm(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _e *[1]Xmpz
 	_mp = _tp
 _6:
 	Xmpz_init(tls, &_base)
-	if (_e[0].X_mp_size) >= int32(0) {
+	if (_e[0].X_mp_size) >= mpzField(0) {
 		goto _8
 	}
 	if Xmpz_invert(tls, &_base, _b, _m) == 0 {
@@ -3607,7 +3607,7 @@ _8:
 		_mpn_div_qr_preinv(tls, nil, _base[0].X_mp_d, int64(_base[0].X_mp_size), _mp, _mn, &_minv)
 		_5_bn = _mn
 	}
-	if (_b[0].X_mp_size) >= int32(0) {
+	if (_b[0].X_mp_size) >= mpzField(0) {
 		goto _12
 	}
 	_7_bp = func() *uint64 {
@@ -3620,7 +3620,7 @@ _8:
 
 	_5_bn = _mn
 _12:
-	_base[0].X_mp_size = int32(_mpn_normalized_size(tls, _base[0].X_mp_d, _5_bn))
+	_base[0].X_mp_size = mpzField(_mpn_normalized_size(tls, _base[0].X_mp_d, _5_bn))
 _10:
 	Xmpz_init_set_ui(tls, &_tr, uint64(1))
 _16:
@@ -3636,7 +3636,7 @@ _18:
 	}
 	if int64(_tr[0].X_mp_size) > _mn {
 		_mpn_div_qr_preinv(tls, nil, _tr[0].X_mp_d, int64(_tr[0].X_mp_size), _mp, _mn, &_minv)
-		_tr[0].X_mp_size = int32(_mpn_normalized_size(tls, _tr[0].X_mp_d, _mn))
+		_tr[0].X_mp_size = mpzField(_mpn_normalized_size(tls, _tr[0].X_mp_d, _mn))
 	}
 	_9_bit >>= 1
 	if _9_bit > 0 {
@@ -3647,7 +3647,7 @@ _17:
 	if int64(_tr[0].X_mp_size) >= _mn {
 		_minv.Xshift = _shift
 		_mpn_div_qr_preinv(tls, nil, _tr[0].X_mp_d, int64(_tr[0].X_mp_size), _mp, _mn, &_minv)
-		_tr[0].X_mp_size = int32(_mpn_normalized_size(tls, _tr[0].X_mp_d, _mn))
+		_tr[0].X_mp_size = mpzField(_mpn_normalized_size(tls, _tr[0].X_mp_d, _mn))
 	}
 	if _tp != nil {
 		_gmp_free_func(tls, unsafe.Pointer(_tp), 0)
@@ -3664,7 +3664,7 @@ func Xmpz_powm_ui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _b *[1]Xmpz_srcptr, _elimb u
 	Xmpz_powm(tls, _r, _b, (*[1]Xmpz_srcptr)(unsafe.Pointer(Xmpz_roinit_n(tls, &_e, &_elimb, int64(1)))), _m)
 }
 
-func Xmpz_fac_ui(tls *crt.TLS, _x *[1]Xmpz_srcptr, _n uint64) {
+func _mpz_fac_ui_generic(tls *crt.TLS, _x *[1]Xmpz_srcptr, _n uint64) {
 	Xmpz_set_ui(tls, _x, _n+uint64(bool2int(_n == 0)))
 _0:
 	if _n > uint64(2) {
@@ -3673,7 +3673,7 @@ _0:
 	}
 }
 
-func Xmpz_bin_uiui(tls *crt.TLS, _r *[1]Xmpz_srcptr, _n uint64, _k uint64) {
+func _mpz_bin_uiui_generic(tls *crt.TLS, _r *[1]Xmpz_srcptr, _n uint64, _k uint64) {
 	var _t [1]Xmpz_srcptr
 	Xmpz_set_ui(tls, _r, uint64(bool2int(_k <= _n)))
 	if _k > (_n >> 1) {
@@ -3702,7 +3702,7 @@ func Xmpz_probab_prime_p(tls *crt.TLS, _n *[1]Xmpz_srcptr, _reps int32) (r0 int3
 	var _is_prime, _j int32
 	var _k uint64
 	var _nm1, _q, _y [1]Xmpz_srcptr
-	if (bool2int((_n[0].X_mp_size) != int32(0)) & int32(*(_n[0].X_mp_d))) == 0 {
+	if (bool2int((_n[0].X_mp_size) != mpzField(0)) & int32(*(_n[0].X_mp_d))) == 0 {
 		return func() int32 {
 			if Xmpz_cmpabs_ui(tls, _n, uint64(2)) == int32(0) {
 				return int32(2)
@@ -3723,7 +3723,7 @@ func Xmpz_probab_prime_p(tls *crt.TLS, _n *[1]Xmpz_srcptr, _reps int32) (r0 int3
 	Xmpz_init(tls, &_nm1)
 	Xmpz_init(tls, &_q)
 	Xmpz_init(tls, &_y)
-	_nm1[0].X_mp_size = int32(_mpz_abs_sub_ui(tls, &_nm1, _n, uint64(1)))
+	_nm1[0].X_mp_size = mpzField(_mpz_abs_sub_ui(tls, &_nm1, _n, uint64(1)))
 	_k = Xmpz_scan1(tls, &_nm1, 0)
 	Xmpz_tdiv_q_2exp(tls, &_q, &_nm1, _k)
 	*func() *int32 { _j = int32(0); return &_is_prime }() = int32(1)
@@ -3808,7 +3808,7 @@ func Xmpz_clrbit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint64) {
 	if Xmpz_tstbit(tls, _d, _bit_index) == 0 {
 		goto _0
 	}
-	if (_d[0].X_mp_size) >= int32(0) {
+	if (_d[0].X_mp_size) >= mpzField(0) {
 		_mpz_abs_sub_bit(tls, _d, _bit_index)
 		goto _2
 	}
@@ -3818,7 +3818,7 @@ _0:
 }
 
 func Xmpz_combit(tls *crt.TLS, _d *[1]Xmpz_srcptr, _bit_index uint64) {
-	if (Xmpz_tstbit(tls, _d, _bit_index) ^ bool2int((_d[0].X_mp_size) < int32(0))) != 0 {
+	if (Xmpz_tstbit(tls, _d, _bit_index) ^ bool2int((_d[0].X_mp_size) < mpzField(0))) != 0 {
 		_mpz_abs_sub_bit(tls, _d, _bit_index)
 		goto _1
 	}
@@ -3836,14 +3836,14 @@ func Xmpz_and(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	var _ux, _vx, _rx, _uc, _vc, _rc, _ul, _vl, _rl uint64
 	var _up, _vp, _rp *uint64
 	var _2___mpz_srcptr_swap__tmp *Xmpz_srcptr
-	_un = int64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = int64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
 	}())
-	_vn = int64(func() int32 {
-		if (_v[0].X_mp_size) >= int32(0) {
+	_vn = int64(func() mpzField {
+		if (_v[0].X_mp_size) >= mpzField(0) {
 			return (_v[0].X_mp_size)
 		}
 		return (-(_v[0].X_mp_size))
@@ -3859,11 +3859,11 @@ func Xmpz_and(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	_vn = _3___mp_size_t_swap__tmp
 _4:
 	if _vn == 0 {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
-	_uc = uint64(bool2int((_u[0].X_mp_size) < int32(0)))
-	_vc = uint64(bool2int((_v[0].X_mp_size) < int32(0)))
+	_uc = uint64(bool2int((_u[0].X_mp_size) < mpzField(0)))
+	_vc = uint64(bool2int((_v[0].X_mp_size) < mpzField(0)))
 	_rc = _uc & _vc
 	_ux = -_uc
 	_vx = -_vc
@@ -3913,7 +3913,7 @@ _16:
 	}
 	_rn = _mpn_normalized_size(tls, _rp, _rn)
 _18:
-	_r[0].X_mp_size = int32(func() int64 {
+	_r[0].X_mp_size = mpzField(func() int64 {
 		if _rx != 0 {
 			return (-_rn)
 		}
@@ -3926,14 +3926,14 @@ func Xmpz_ior(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	var _ux, _vx, _rx, _uc, _vc, _rc, _ul, _vl, _rl uint64
 	var _up, _vp, _rp *uint64
 	var _2___mpz_srcptr_swap__tmp *Xmpz_srcptr
-	_un = int64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = int64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
 	}())
-	_vn = int64(func() int32 {
-		if (_v[0].X_mp_size) >= int32(0) {
+	_vn = int64(func() mpzField {
+		if (_v[0].X_mp_size) >= mpzField(0) {
 			return (_v[0].X_mp_size)
 		}
 		return (-(_v[0].X_mp_size))
@@ -3952,8 +3952,8 @@ _4:
 		Xmpz_set(tls, _r, _u)
 		return
 	}
-	_uc = uint64(bool2int((_u[0].X_mp_size) < int32(0)))
-	_vc = uint64(bool2int((_v[0].X_mp_size) < int32(0)))
+	_uc = uint64(bool2int((_u[0].X_mp_size) < mpzField(0)))
+	_vc = uint64(bool2int((_v[0].X_mp_size) < mpzField(0)))
 	_rc = _uc | _vc
 	_ux = -_uc
 	_vx = -_vc
@@ -4003,7 +4003,7 @@ _16:
 	}
 	_rn = _mpn_normalized_size(tls, _rp, _rn)
 _18:
-	_r[0].X_mp_size = int32(func() int64 {
+	_r[0].X_mp_size = mpzField(func() int64 {
 		if _rx != 0 {
 			return (-_rn)
 		}
@@ -4016,14 +4016,14 @@ func Xmpz_xor(tls *crt.TLS, _r *[1]Xmpz_srcptr, _u *[1]Xmpz_srcptr, _v *[1]Xmpz_
 	var _ux, _vx, _rx, _uc, _vc, _rc, _ul, _vl, _rl uint64
 	var _up, _vp, _rp *uint64
 	var _2___mpz_srcptr_swap__tmp *Xmpz_srcptr
-	_un = int64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = int64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
 	}())
-	_vn = int64(func() int32 {
-		if (_v[0].X_mp_size) >= int32(0) {
+	_vn = int64(func() mpzField {
+		if (_v[0].X_mp_size) >= mpzField(0) {
 			return (_v[0].X_mp_size)
 		}
 		return (-(_v[0].X_mp_size))
@@ -4042,8 +4042,8 @@ _4:
 		Xmpz_set(tls, _r, _u)
 		return
 	}
-	_uc = uint64(bool2int((_u[0].X_mp_size) < int32(0)))
-	_vc = uint64(bool2int((_v[0].X_mp_size) < int32(0)))
+	_uc = uint64(bool2int((_u[0].X_mp_size) < mpzField(0)))
+	_vc = uint64(bool2int((_v[0].X_mp_size) < mpzField(0)))
 	_rc = _uc ^ _vc
 	_ux = -_uc
 	_vx = -_vc
@@ -4087,7 +4087,7 @@ _14:
 	}
 	_un = _mpn_normalized_size(tls, _rp, _un)
 _16:
-	_r[0].X_mp_size = int32(func() int64 {
+	_r[0].X_mp_size = mpzField(func() int64 {
 		if _rx != 0 {
 			return (-_un)
 		}
@@ -4206,7 +4206,7 @@ func Xmpz_fits_ulong_p(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 int32) {
 }
 
 func Xmpz_get_si(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 int64) {
-	if (_u[0].X_mp_size) < int32(0) {
+	if (_u[0].X_mp_size) < mpzField(0) {
 		return int64(-1) - int64(((*(_u[0].X_mp_d))-uint64(1))&uint64(9223372036854775807))
 	}
 	return int64(Xmpz_get_ui(tls, _u) & uint64(9223372036854775807))
@@ -4216,8 +4216,8 @@ func Xmpz_get_d(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 float64) {
 	var _un int64
 	var _x, _B float64
 	_B = 1.8446744073709552e+19
-	_un = int64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = int64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -4231,15 +4231,15 @@ _3:
 		_x = (_B * _x) + float64(*elem0(_u[0].X_mp_d, uintptr(preInc1(&_un, -1))))
 		goto _3
 	}
-	if (_u[0].X_mp_size) < int32(0) {
+	if (_u[0].X_mp_size) < mpzField(0) {
 		_x = -_x
 	}
 	return _x
 }
 
 func Xmpz_size(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 uint64) {
-	return uint64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	return uint64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -4247,8 +4247,8 @@ func Xmpz_size(tls *crt.TLS, _u *[1]Xmpz_srcptr) (r0 uint64) {
 }
 
 func Xmpz_getlimbn(tls *crt.TLS, _u *[1]Xmpz_srcptr, _n int64) (r0 uint64) {
-	if (_n >= 0) && (_n < int64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	if (_n >= 0) && (_n < int64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -4288,7 +4288,7 @@ func Xmpz_set_d(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x float64) {
 	var _B, _Bi float64
 	var _rp *uint64
 	if (_x != _x) || (_x == (_x * 0.5)) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_sign = bool2int(_x < float64(0))
@@ -4296,7 +4296,7 @@ func Xmpz_set_d(tls *crt.TLS, _r *[1]Xmpz_srcptr, _x float64) {
 		_x = -_x
 	}
 	if _x < float64(1) {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return
 	}
 	_B = 1.8446744073709552e+19
@@ -4330,7 +4330,7 @@ _10:
 		*elem0(_rp, uintptr(_i)) = _f
 		goto _10
 	}
-	_r[0].X_mp_size = int32(func() int64 {
+	_r[0].X_mp_size = mpzField(func() int64 {
 		if _sign != 0 {
 			return (-_rn)
 		}
@@ -4372,8 +4372,8 @@ _1:
 	if _sp == nil {
 		_sp = (*int8)(_gmp_allocate_func(tls, uint64(1)+_sn))
 	}
-	_un = int64(func() int32 {
-		if (_u[0].X_mp_size) >= int32(0) {
+	_un = int64(func() mpzField {
+		if (_u[0].X_mp_size) >= mpzField(0) {
 			return (_u[0].X_mp_size)
 		}
 		return (-(_u[0].X_mp_size))
@@ -4384,7 +4384,7 @@ _1:
 		return _sp
 	}
 	_i = 0
-	if (_u[0].X_mp_size) < int32(0) {
+	if (_u[0].X_mp_size) < mpzField(0) {
 		*elem5(_sp, uintptr(postInc2(&_i, uint64(1)))) = int8(45)
 	}
 	_bits = _mpn_base_power_of_two_p(tls, uint32(_base))
@@ -4449,7 +4449,7 @@ _3:
 _10:
 _2:
 	if (*_sp) == 0 {
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return int32(-1)
 	}
 	_dp = (*uint8)(_gmp_allocate_func(tls, crt.Xstrlen(tls, _sp)))
@@ -4477,7 +4477,7 @@ _12:
 _26:
 	if _6_digit >= uint32(_base) {
 		_gmp_free_func(tls, unsafe.Pointer(_dp), 0)
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return int32(-1)
 	}
 	*elem3(_dp, uintptr(postInc2(&_dn, uint64(1)))) = uint8(_6_digit)
@@ -4487,7 +4487,7 @@ _13:
 _15:
 	if _dn == 0 {
 		_gmp_free_func(tls, unsafe.Pointer(_dp), 0)
-		_r[0].X_mp_size = int32(0)
+		_r[0].X_mp_size = mpzField(0)
 		return int32(-1)
 	}
 	_bits = _mpn_base_power_of_two_p(tls, uint32(_base))
@@ -4516,7 +4516,7 @@ _15:
 _32:
 
 	_gmp_free_func(tls, unsafe.Pointer(_dp), 0)
-	_r[0].X_mp_size = int32(func() int64 {
+	_r[0].X_mp_size = mpzField(func() int64 {
 		if _sign != 0 {
 			return (-_rn)
 		}
@@ -4604,7 +4604,7 @@ _11:
 	}
 	_i = _mpn_normalized_size(tls, _rp, _i)
 _18:
-	_r[0].X_mp_size = int32(_i)
+	_r[0].X_mp_size = mpzField(_i)
 }
 
 func _gmp_detect_endian(tls *crt.TLS) (r0 int32) {
@@ -4759,8 +4759,8 @@ type Tmpn_base_info struct {
 } // t8 struct{exp uint32,bb uint64}
 
 type Xmpz_srcptr struct {
-	X_mp_alloc int32
-	X_mp_size  int32
+	X_mp_alloc mpzField
+	X_mp_size  mpzField
 	X_mp_d     *uint64
 }                       // t9 struct{_mp_alloc int32,_mp_size int32,_mp_d *uint64}
 func str(n int) *int8   { return (*int8)(unsafe.Pointer(&strTab[n])) }
@@ -0,0 +1,163 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"github.com/cznic/ccgo/crt"
+)
+
+// Modulus performs repeated modular arithmetic under a fixed modulus m. The
+// reduction constants are computed once by NewModulus.
+//
+// For an odd m the operands and results of MulMod, SqrMod and ExpMod are in
+// Montgomery form, a*R mod m, where R is 2^(limbBits*n) and n is the number
+// of limbs of m. Values are converted using ToMontgomery and FromMontgomery.
+// For an even m the Montgomery form is not available and both conversions
+// only reduce their argument. AddMod and SubMod work with either form.
+//
+// All methods accept operands outside of [0, m), those are first reduced
+// modulo m. Results are always in [0, m). Destinations may alias operands.
+//
+// A Modulus must not be used by multiple goroutines concurrently.
+type Modulus struct {
+	m    [1]Xmpz_srcptr // Read only view of mp.
+	mp   []limb
+	mont bool
+	z    modReducer
+
+	a, b []limb // n limbs of scratch space each.
+}
+
+// NewModulus returns a Modulus for |m|. It panics if m is zero.
+func NewModulus(tls *crt.TLS, m *[1]Xmpz_srcptr) *Modulus {
+	mn := mpzAbsSize(m)
+	if mn == 0 {
+		panic("NewModulus: zero modulus")
+	}
+
+	mp := append([]limb(nil), mpzLimbs(m)...)
+	z := &Modulus{
+		mp:   mp,
+		mont: mp[0]&1 != 0,
+		z:    newReducer(tls, mp),
+		a:    make([]limb, mn),
+		b:    make([]limb, mn),
+	}
+	Xmpz_roinit_n(tls, &z.m, &mp[0], mn)
+	return z
+}
+
+// Montgomery reports whether values are in Montgomery form, ie. whether the
+// modulus is odd.
+func (z *Modulus) Montgomery() bool { return z.mont }
+
+// Modulus sets r to the modulus, which is always positive.
+func (z *Modulus) Modulus(tls *crt.TLS, r *[1]Xmpz_srcptr) { Xmpz_set(tls, r, &z.m) }
+
+// load sets s to a mod m.
+func (z *Modulus) load(tls *crt.TLS, s []limb, a *[1]Xmpz_srcptr) {
+	if a[0].X_mp_size < 0 || Xmpz_cmp(tls, a, &z.m) >= 0 {
+		var t [1]Xmpz_srcptr
+		Xmpz_init(tls, &t)
+		Xmpz_mod(tls, &t, a, &z.m)
+		z.load(tls, s, &t)
+		Xmpz_clear(tls, &t)
+		return
+	}
+
+	n := copy(s, mpzLimbs(a))
+	for i := n; i < len(s); i++ {
+		s[i] = 0
+	}
+}
+
+// ToMontgomery sets r to the Montgomery form of a.
+func (z *Modulus) ToMontgomery(tls *crt.TLS, r, a *[1]Xmpz_srcptr) {
+	z.load(tls, z.a, a)
+	z.z.to(tls, z.a, z.a)
+	mpzSetLimbs(tls, r, z.a)
+}
+
+// FromMontgomery sets r to the value whose Montgomery form is a.
+func (z *Modulus) FromMontgomery(tls *crt.TLS, r, a *[1]Xmpz_srcptr) {
+	z.load(tls, z.a, a)
+	z.z.from(tls, z.a, z.a)
+	mpzSetLimbs(tls, r, z.a)
+}
+
+// MulMod sets r to a*b mod m.
+func (z *Modulus) MulMod(tls *crt.TLS, r, a, b *[1]Xmpz_srcptr) {
+	z.load(tls, z.a, a)
+	z.load(tls, z.b, b)
+	z.z.mul(tls, z.a, z.a, z.b)
+	mpzSetLimbs(tls, r, z.a)
+}
+
+// SqrMod sets r to a^2 mod m.
+func (z *Modulus) SqrMod(tls *crt.TLS, r, a *[1]Xmpz_srcptr) {
+	z.load(tls, z.a, a)
+	z.z.mul(tls, z.a, z.a, z.a)
+	mpzSetLimbs(tls, r, z.a)
+}
+
+// AddMod sets r to a+b mod m.
+func (z *Modulus) AddMod(tls *crt.TLS, r, a, b *[1]Xmpz_srcptr) {
+	n := mpSize(len(z.mp))
+	z.load(tls, z.a, a)
+	z.load(tls, z.b, b)
+	if Xmpn_add_n(tls, &z.a[0], &z.a[0], &z.b[0], n) != 0 || Xmpn_cmp(tls, &z.a[0], &z.mp[0], n) >= 0 {
+		Xmpn_sub_n(tls, &z.a[0], &z.a[0], &z.mp[0], n)
+	}
+	mpzSetLimbs(tls, r, z.a)
+}
+
+// SubMod sets r to a-b mod m.
+func (z *Modulus) SubMod(tls *crt.TLS, r, a, b *[1]Xmpz_srcptr) {
+	n := mpSize(len(z.mp))
+	z.load(tls, z.a, a)
+	z.load(tls, z.b, b)
+	if Xmpn_sub_n(tls, &z.a[0], &z.a[0], &z.b[0], n) != 0 {
+		Xmpn_add_n(tls, &z.a[0], &z.a[0], &z.mp[0], n)
+	}
+	mpzSetLimbs(tls, r, z.a)
+}
+
+// ExpMod sets r to a^e mod m. A negative e requires a to be invertible
+// modulo m, otherwise ExpMod panics.
+func (z *Modulus) ExpMod(tls *crt.TLS, r, a, e *[1]Xmpz_srcptr) {
+	z.load(tls, z.a, a)
+	if e[0].X_mp_size < 0 {
+		// The inverse of the Montgomery form of a is not the Montgomery
+		// form of the inverse of a.
+		var t [1]Xmpz_srcptr
+		Xmpz_init(tls, &t)
+		z.z.from(tls, z.a, z.a)
+		mpzSetLimbs(tls, &t, z.a)
+		if Xmpz_invert(tls, &t, &t, &z.m) == 0 {
+			Xmpz_clear(tls, &t)
+			panic("Modulus.ExpMod: negative exponent and non-invertible base")
+		}
+
+		z.load(tls, z.a, &t)
+		Xmpz_clear(tls, &t)
+		z.z.to(tls, z.a, z.a)
+	}
+
+	switch {
+	case e[0].X_mp_size == 0:
+		// The representation of one, which is R mod m or 1 mod m.
+		z.b[0] = 1
+		for i := 1; i < len(z.b); i++ {
+			z.b[i] = 0
+		}
+		if len(z.mp) == 1 && z.mp[0] == 1 {
+			z.b[0] = 0
+		}
+		z.z.to(tls, z.a, z.b)
+	default:
+		powWindow(tls, z.z, z.a, z.a, mpzLimbs(e), nil)
+	}
+	mpzSetLimbs(tls, r, z.a)
+}
@@ -0,0 +1,340 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"math/bits"
+	"unsafe"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// This file implements the limb level primitives whose C versions emulate
+// double limb arithmetic by splitting limbs in halves. The Go compiler turns
+// the math/bits functions used here into single instructions. A limb and an
+// uint have the same size on all supported platforms.
+
+func mulWW(x, y limb) (hi, lo limb) {
+	h, l := bits.Mul(uint(x), uint(y))
+	return limb(h), limb(l)
+}
+
+func addWWW(x, y, c limb) (s, cy limb) {
+	r, cc := bits.Add(uint(x), uint(y), uint(c))
+	return limb(r), limb(cc)
+}
+
+func subWWW(x, y, b limb) (d, bw limb) {
+	r, bb := bits.Sub(uint(x), uint(y), uint(b))
+	return limb(r), limb(bb)
+}
+
+// add_ssaaaa
+func add2(ah, al, bh, bl limb) (sh, sl limb) {
+	sl, c := addWWW(al, bl, 0)
+	return ah + bh + c, sl
+}
+
+// sub_ddmmss
+func sub2(ah, al, bh, bl limb) (dh, dl limb) {
+	dl, b := subWWW(al, bl, 0)
+	return ah - bh - b, dl
+}
+
+// limbs returns {p, n} as a slice, or nil if p is nil.
+func limbs(p *limb, n mpSize) []limb {
+	if p == nil || n <= 0 {
+		return nil
+	}
+
+	return unsafe.Slice(p, n)
+}
+
+// addN sets r to a + b and returns the carry. All slices must have the same
+// length.
+func addN(r, a, b []limb) (c limb) {
+	b = b[:len(r)]
+	a = a[:len(r)]
+	for i := range r {
+		r[i], c = addWWW(a[i], b[i], c)
+	}
+	return c
+}
+
+// subN sets r to a - b and returns the borrow. All slices must have the same
+// length.
+func subN(r, a, b []limb) (c limb) {
+	b = b[:len(r)]
+	a = a[:len(r)]
+	for i := range r {
+		r[i], c = subWWW(a[i], b[i], c)
+	}
+	return c
+}
+
+// lshift sets r to u shifted left by 0 < cnt < limbBits bits and returns the
+// bits shifted out. The slices must have the same length and r may overlap u
+// if it does not start below it.
+func lshift(r, u []limb, cnt uint) limb {
+	n := len(u)
+	if n == 0 {
+		return 0
+	}
+
+	r = r[:n]
+	tnc := limbBits - cnt
+	high := u[n-1]
+	ret := high >> tnc
+	for i := n - 1; i > 0; i-- {
+		low := u[i-1]
+		r[i] = high<<cnt | low>>tnc
+		high = low
+	}
+	r[0] = high << cnt
+	return ret
+}
+
+// rshift sets r to u shifted right by 0 < cnt < limbBits bits and returns the
+// bits shifted out in the most significant bits of the result. The slices
+// must have the same length and r may overlap u if it does not start above
+// it.
+func rshift(r, u []limb, cnt uint) limb {
+	n := len(u)
+	if n == 0 {
+		return 0
+	}
+
+	r = r[:n]
+	tnc := limbBits - cnt
+	low := u[0]
+	ret := low << tnc
+	for i := 0; i < n-1; i++ {
+		high := u[i+1]
+		r[i] = low>>cnt | high<<tnc
+		low = high
+	}
+	r[n-1] = low >> cnt
+	return ret
+}
+
+// mul1 sets r to u * v and returns the high limb of the product.
+func mul1(r, u []limb, v limb) (c limb) {
+	u = u[:len(r)]
+	for i, x := range u {
+		hi, lo := mulWW(x, v)
+		var cy limb
+		r[i], cy = addWWW(lo, c, 0)
+		c = hi + cy
+	}
+	return c
+}
+
+// addmul1 adds u * v to r and returns the high limb of the sum.
+func addmul1(r, u []limb, v limb) (c limb) {
+	u = u[:len(r)]
+	for i, x := range u {
+		hi, lo := mulWW(x, v)
+		var c1, c2 limb
+		lo, c1 = addWWW(lo, c, 0)
+		r[i], c2 = addWWW(r[i], lo, 0)
+		c = hi + c1 + c2
+	}
+	return c
+}
+
+// submul1 subtracts u * v from r and returns the borrow limb.
+func submul1(r, u []limb, v limb) (c limb) {
+	u = u[:len(r)]
+	for i, x := range u {
+		hi, lo := mulWW(x, v)
+		var c1, c2 limb
+		lo, c1 = addWWW(lo, c, 0)
+		r[i], c2 = subWWW(r[i], lo, 0)
+		c = hi + c1 + c2
+	}
+	return c
+}
+
+// Xmpn_invert_3by2 returns floor((B^3-1)/(u1*B+u0)) - B, where B = 2^limbBits
+// and the most significant bit of u1 is set. Xmpn_invert_3by2(tls, u1, 0) is
+// the 2/1 inverse of u1.
+func Xmpn_invert_3by2(tls *crt.TLS, u1, u0 limb) limb {
+	// B^2 - 1 - B*u1 = ^u1*B + B - 1.
+	q, rr := bits.Div(uint(^u1), ^uint(0), uint(u1))
+	m, r := limb(q), limb(rr)
+
+	// Now m is the 2/1 inverse of u1. If u0 > 0, adjust it to become a 3/2
+	// inverse.
+	if u0 > 0 {
+		r = ^r
+		r += u0
+		if r < u0 {
+			m--
+			if r >= u1 {
+				m--
+				r -= u1
+			}
+			r -= u1
+		}
+		th, tl := mulWW(u0, m)
+		r += th
+		if r < th {
+			m--
+			if r > u1 || r == u1 && tl > u0 {
+				m--
+			}
+		}
+	}
+	return m
+}
+
+// udivQRNNDPreinv returns the quotient and remainder of nh*B+nl divided by
+// the normalized d, where di is the inverse of d and nh < d.
+func udivQRNNDPreinv(nh, nl, d, di limb) (q, r limb) {
+	qh, ql := mulWW(nh, di)
+	qh, ql = add2(qh, ql, nh+1, nl)
+	r = nl - qh*d
+	if r > ql {
+		qh--
+		r += d
+	}
+	if r >= d {
+		r -= d
+		qh++
+	}
+	return qh, r
+}
+
+// udivQR3by2 divides n2:n1:n0 by the normalized d1:d0, where dinv is the 3/2
+// inverse of d1:d0 and n2:n1 < d1:d0. It returns the quotient limb and the
+// two limb remainder.
+func udivQR3by2(n2, n1, n0, d1, d0, dinv limb) (q, r1, r0 limb) {
+	q, q0 := mulWW(n2, dinv)
+	q, q0 = add2(q, q0, n2, n1)
+
+	// Compute the two most significant limbs of n - q'd.
+	r1 = n1 - d1*q
+	r1, r0 = sub2(r1, n0, d1, d0)
+	t1, t0 := mulWW(d0, q)
+	r1, r0 = sub2(r1, r0, t1, t0)
+	q++
+
+	// Conditionally adjust q and the remainders.
+	if r1 >= q0 {
+		q--
+		r1, r0 = add2(r1, r0, d1, d0)
+	}
+	if r1 >= d1 && (r1 > d1 || r0 >= d0) {
+		q++
+		r1, r0 = sub2(r1, r0, d1, d0)
+	}
+	return q, r1, r0
+}
+
+// _mpn_div_qr_1_preinv sets {qp, nn}, if qp is not nil, to the quotient of
+// {np, nn} and the single limb divisor described by inv and returns the
+// remainder.
+func _mpn_div_qr_1_preinv(tls *crt.TLS, qp, np *limb, nn mpSize, inv *Tgmp_div_inverse) limb {
+	var tp *limb
+	var r limb
+	n := limbs(np, nn)
+	if inv.Xshift > 0 {
+		tp = _gmp_xalloc_limbs(tls, nn)
+		r = Xmpn_lshift(tls, tp, np, nn, inv.Xshift)
+		n = limbs(tp, nn)
+	}
+
+	d := inv.Xd1
+	di := inv.Xdi
+	q := limbs(qp, nn)
+	for i := len(n) - 1; i >= 0; i-- {
+		var qi limb
+		qi, r = udivQRNNDPreinv(r, n[i], d, di)
+		if q != nil {
+			q[i] = qi
+		}
+	}
+	if inv.Xshift > 0 {
+		_gmp_free_func(tls, unsafe.Pointer(tp), 0)
+	}
+	return r >> inv.Xshift
+}
+
+// _mpn_div_qr_2_preinv sets {qp, nn-1}, if qp is not nil, to the quotient of
+// {np, nn} and the two limb divisor described by inv and {rp, 2} to the
+// remainder. Requires nn >= 2.
+func _mpn_div_qr_2_preinv(tls *crt.TLS, qp, rp, np *limb, nn mpSize, inv *Tgmp_div_inverse) {
+	var tp *limb
+	var r1 limb
+	shift := inv.Xshift
+	d1 := inv.Xd1
+	d0 := inv.Xd0
+	di := inv.Xdi
+	n := limbs(np, nn)
+	if shift > 0 {
+		tp = _gmp_xalloc_limbs(tls, nn)
+		r1 = Xmpn_lshift(tls, tp, np, nn, shift)
+		n = limbs(tp, nn)
+	}
+
+	r0 := n[nn-1]
+	q := limbs(qp, nn-1)
+	for i := nn - 2; i >= 0; i-- {
+		var qi limb
+		qi, r1, r0 = udivQR3by2(r1, r0, n[i], d1, d0, di)
+		if q != nil {
+			q[i] = qi
+		}
+	}
+	if shift > 0 {
+		r0 = r0>>shift | r1<<(limbBits-shift)
+		r1 >>= shift
+		_gmp_free_func(tls, unsafe.Pointer(tp), 0)
+	}
+	r := limbs(rp, 2)
+	r[1] = r1
+	r[0] = r0
+}
+
+// _mpn_div_qr_pi1 divides n1:{np, nn} by the normalized {dp, dn}, dn > 2,
+// where dinv is the 3/2 inverse of the two most significant divisor limbs.
+// It sets {qp, nn-dn+1}, if qp is not nil, to the quotient and {np, dn} to
+// the remainder.
+func _mpn_div_qr_pi1(tls *crt.TLS, qp, np *limb, nn mpSize, n1 limb, dp *limb, dn mpSize, dinv limb) {
+	n := limbs(np, nn)
+	d := limbs(dp, dn)
+	q := limbs(qp, nn-dn+1)
+	d1 := d[dn-1]
+	d0 := d[dn-2]
+
+	// Iteration variable is the index of the q limb.
+	//
+	// We divide <n1, np[dn-1+i], np[dn-2+i], np[dn-3+i],..., np[i]>
+	// by            <d1,          d0,        dp[dn-3],  ..., dp[0] >
+	for i := nn - dn; i >= 0; i-- {
+		var qi limb
+		n0 := n[dn-1+i]
+		if n1 == d1 && n0 == d0 {
+			qi = ^limb(0)
+			Xmpn_submul_1(tls, &n[i], dp, dn, qi)
+			n1 = n[dn-1+i] // Update n1, last loop's value will now be invalid.
+		} else {
+			qi, n1, n0 = udivQR3by2(n1, n0, n[dn-2+i], d1, d0, dinv)
+			cy := Xmpn_submul_1(tls, &n[i], dp, dn-2, qi)
+			var cy1 limb
+			n0, cy1 = subWWW(n0, cy, 0)
+			n1, cy = subWWW(n1, cy1, 0)
+			n[dn-2+i] = n0
+			if cy != 0 {
+				n1 += d1 + Xmpn_add_n(tls, &n[i], &n[i], dp, dn-1)
+				qi--
+			}
+		}
+		if q != nil {
+			q[i] = qi
+		}
+	}
+	n[dn-1] = n1
+}
@@ -0,0 +1,97 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !purego
+// +build !purego
+
+package minigmp
+
+import (
+	"github.com/cznic/ccgo/crt"
+)
+
+var (
+	hasADX  bool // ADCX/ADOX
+	hasBMI2 bool // MULX
+)
+
+func init() {
+	if max, _, _, _ := cpuid(0, 0); max >= 7 {
+		_, ebx, _, _ := cpuid(7, 0)
+		hasBMI2 = ebx&(1<<8) != 0
+		hasADX = ebx&(1<<19) != 0
+	}
+}
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+//go:noescape
+func mpnAddN(rp, ap, bp *uint64, n int64) (cy uint64)
+
+//go:noescape
+func mpnSubN(rp, ap, bp *uint64, n int64) (cy uint64)
+
+//go:noescape
+func mpnLshift(rp, up *uint64, n int64, cnt uint32) (r uint64)
+
+//go:noescape
+func mpnRshift(rp, up *uint64, n int64, cnt uint32) (r uint64)
+
+//go:noescape
+func mpnMul1MULX(rp, up *uint64, n int64, v uint64) (cy uint64)
+
+//go:noescape
+func mpnAddmul1ADX(rp, up *uint64, n int64, v uint64) (cy uint64)
+
+//go:noescape
+func mpnSubmul1MULX(rp, up *uint64, n int64, v uint64) (cy uint64)
+
+// Xmpn_add_n sets {rp, n} to {ap, n} + {bp, n} and returns the carry.
+func Xmpn_add_n(tls *crt.TLS, rp, ap, bp *limb, n mpSize) limb { return mpnAddN(rp, ap, bp, n) }
+
+// Xmpn_sub_n sets {rp, n} to {ap, n} - {bp, n} and returns the borrow.
+func Xmpn_sub_n(tls *crt.TLS, rp, ap, bp *limb, n mpSize) limb { return mpnSubN(rp, ap, bp, n) }
+
+// Xmpn_lshift sets {rp, n} to {up, n} shifted left by 0 < cnt < limbBits
+// bits and returns the bits shifted out.
+func Xmpn_lshift(tls *crt.TLS, rp, up *limb, n mpSize, cnt uint32) limb {
+	return mpnLshift(rp, up, n, cnt)
+}
+
+// Xmpn_rshift sets {rp, n} to {up, n} shifted right by 0 < cnt < limbBits
+// bits and returns the bits shifted out in the most significant bits of the
+// result.
+func Xmpn_rshift(tls *crt.TLS, rp, up *limb, n mpSize, cnt uint32) limb {
+	return mpnRshift(rp, up, n, cnt)
+}
+
+// Xmpn_mul_1 sets {rp, n} to {up, n} * vl and returns the high limb of the
+// product.
+func Xmpn_mul_1(tls *crt.TLS, rp, up *limb, n mpSize, vl limb) limb {
+	if hasBMI2 {
+		return mpnMul1MULX(rp, up, n, vl)
+	}
+
+	return mul1(limbs(rp, n), limbs(up, n), vl)
+}
+
+// Xmpn_addmul_1 adds {up, n} * vl to {rp, n} and returns the high limb of
+// the sum.
+func Xmpn_addmul_1(tls *crt.TLS, rp, up *limb, n mpSize, vl limb) limb {
+	if hasBMI2 && hasADX {
+		return mpnAddmul1ADX(rp, up, n, vl)
+	}
+
+	return addmul1(limbs(rp, n), limbs(up, n), vl)
+}
+
+// Xmpn_submul_1 subtracts {up, n} * vl from {rp, n} and returns the borrow
+// limb.
+func Xmpn_submul_1(tls *crt.TLS, rp, up *limb, n mpSize, vl limb) limb {
+	if hasBMI2 {
+		return mpnSubmul1MULX(rp, up, n, vl)
+	}
+
+	return submul1(limbs(rp, n), limbs(up, n), vl)
+}
@@ -0,0 +1,399 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !purego
+// +build !purego
+
+#include "textflag.h"
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func mpnAddN(rp, ap, bp *uint64, n int64) (cy uint64)
+TEXT ·mpnAddN(SB), NOSPLIT, $0-40
+	MOVQ rp+0(FP), DI
+	MOVQ ap+8(FP), SI
+	MOVQ bp+16(FP), DX
+	MOVQ n+24(FP), CX
+	XORQ AX, AX // saved carry
+	XORQ BX, BX // index
+	TESTQ CX, CX
+	JLE  addNDone
+	MOVQ CX, R9
+	ANDQ $3, R9
+	SHRQ $2, CX
+	TESTQ CX, CX // clears CF
+	JZ   addN1
+
+addN4:
+	MOVQ  0(SI)(BX*8), R10
+	MOVQ  8(SI)(BX*8), R11
+	MOVQ  16(SI)(BX*8), R12
+	MOVQ  24(SI)(BX*8), R13
+	ADCQ  0(DX)(BX*8), R10
+	ADCQ  8(DX)(BX*8), R11
+	ADCQ  16(DX)(BX*8), R12
+	ADCQ  24(DX)(BX*8), R13
+	MOVQ  R10, 0(DI)(BX*8)
+	MOVQ  R11, 8(DI)(BX*8)
+	MOVQ  R12, 16(DI)(BX*8)
+	MOVQ  R13, 24(DI)(BX*8)
+	LEAQ  4(BX), BX
+	DECQ  CX
+	JNZ   addN4
+	SBBQ  AX, AX // save carry
+	TESTQ R9, R9
+	JZ    addNDone
+	ADDQ  AX, AX // restore carry
+
+addN1:
+	MOVQ 0(SI)(BX*8), R10
+	ADCQ 0(DX)(BX*8), R10
+	MOVQ R10, 0(DI)(BX*8)
+	INCQ BX
+	DECQ R9
+	JNZ  addN1
+	SBBQ AX, AX // save carry
+
+addNDone:
+	NEGQ AX
+	MOVQ AX, cy+32(FP)
+	RET
+
+// func mpnSubN(rp, ap, bp *uint64, n int64) (cy uint64)
+TEXT ·mpnSubN(SB), NOSPLIT, $0-40
+	MOVQ rp+0(FP), DI
+	MOVQ ap+8(FP), SI
+	MOVQ bp+16(FP), DX
+	MOVQ n+24(FP), CX
+	XORQ AX, AX // saved borrow
+	XORQ BX, BX // index
+	TESTQ CX, CX
+	JLE  subNDone
+	MOVQ CX, R9
+	ANDQ $3, R9
+	SHRQ $2, CX
+	TESTQ CX, CX // clears CF
+	JZ   subN1
+
+subN4:
+	MOVQ  0(SI)(BX*8), R10
+	MOVQ  8(SI)(BX*8), R11
+	MOVQ  16(SI)(BX*8), R12
+	MOVQ  24(SI)(BX*8), R13
+	SBBQ  0(DX)(BX*8), R10
+	SBBQ  8(DX)(BX*8), R11
+	SBBQ  16(DX)(BX*8), R12
+	SBBQ  24(DX)(BX*8), R13
+	MOVQ  R10, 0(DI)(BX*8)
+	MOVQ  R11, 8(DI)(BX*8)
+	MOVQ  R12, 16(DI)(BX*8)
+	MOVQ  R13, 24(DI)(BX*8)
+	LEAQ  4(BX), BX
+	DECQ  CX
+	JNZ   subN4
+	SBBQ  AX, AX // save borrow
+	TESTQ R9, R9
+	JZ    subNDone
+	ADDQ  AX, AX // restore borrow
+
+subN1:
+	MOVQ 0(SI)(BX*8), R10
+	SBBQ 0(DX)(BX*8), R10
+	MOVQ R10, 0(DI)(BX*8)
+	INCQ BX
+	DECQ R9
+	JNZ  subN1
+	SBBQ AX, AX // save borrow
+
+subNDone:
+	NEGQ AX
+	MOVQ AX, cy+32(FP)
+	RET
+
+// func mpnLshift(rp, up *uint64, n int64, cnt uint32) (r uint64)
+//
+// The loop runs from the most significant limb down, so rp >= up may
+// overlap.
+TEXT ·mpnLshift(SB), NOSPLIT, $0-40
+	MOVQ rp+0(FP), DI
+	MOVQ up+8(FP), SI
+	MOVQ n+16(FP), BX
+	MOVL cnt+24(FP), CX
+	XORQ AX, AX
+	TESTQ BX, BX
+	JLE  lshiftRet
+	LEAQ (SI)(BX*8), SI
+	LEAQ (DI)(BX*8), DI
+	MOVQ -8(SI), R8
+	SHLQ CX, R8, AX // bits shifted out
+	DECQ BX
+	MOVQ BX, R9
+	ANDQ $3, R9
+	SHRQ $2, BX
+	TESTQ R9, R9
+	JZ   lshift4
+
+lshift1:
+	MOVQ -16(SI), R10
+	SHLQ CX, R10, R8
+	MOVQ R8, -8(DI)
+	MOVQ R10, R8
+	LEAQ -8(SI), SI
+	LEAQ -8(DI), DI
+	DECQ R9
+	JNZ  lshift1
+
+lshift4:
+	TESTQ BX, BX
+	JZ    lshiftLast
+
+lshift4Loop:
+	MOVQ -16(SI), R9
+	MOVQ -24(SI), R10
+	MOVQ -32(SI), R11
+	MOVQ -40(SI), R12
+	SHLQ CX, R9, R8
+	SHLQ CX, R10, R9
+	SHLQ CX, R11, R10
+	SHLQ CX, R12, R11
+	MOVQ R8, -8(DI)
+	MOVQ R9, -16(DI)
+	MOVQ R10, -24(DI)
+	MOVQ R11, -32(DI)
+	MOVQ R12, R8
+	LEAQ -32(SI), SI
+	LEAQ -32(DI), DI
+	DECQ BX
+	JNZ  lshift4Loop
+
+lshiftLast:
+	SHLQ CX, R8
+	MOVQ R8, -8(DI)
+
+lshiftRet:
+	MOVQ AX, r+32(FP)
+	RET
+
+// func mpnRshift(rp, up *uint64, n int64, cnt uint32) (r uint64)
+//
+// The loop runs from the least significant limb up, so rp <= up may
+// overlap.
+TEXT ·mpnRshift(SB), NOSPLIT, $0-40
+	MOVQ rp+0(FP), DI
+	MOVQ up+8(FP), SI
+	MOVQ n+16(FP), BX
+	MOVL cnt+24(FP), CX
+	XORQ AX, AX
+	TESTQ BX, BX
+	JLE  rshiftRet
+	MOVQ 0(SI), R8
+	SHRQ CX, R8, AX // bits shifted out
+	DECQ BX
+	MOVQ BX, R9
+	ANDQ $3, R9
+	SHRQ $2, BX
+	TESTQ R9, R9
+	JZ   rshift4
+
+rshift1:
+	MOVQ 8(SI), R10
+	SHRQ CX, R10, R8
+	MOVQ R8, 0(DI)
+	MOVQ R10, R8
+	LEAQ 8(SI), SI
+	LEAQ 8(DI), DI
+	DECQ R9
+	JNZ  rshift1
+
+rshift4:
+	TESTQ BX, BX
+	JZ    rshiftLast
+
+rshift4Loop:
+	MOVQ 8(SI), R9
+	MOVQ 16(SI), R10
+	MOVQ 24(SI), R11
+	MOVQ 32(SI), R12
+	SHRQ CX, R9, R8
+	SHRQ CX, R10, R9
+	SHRQ CX, R11, R10
+	SHRQ CX, R12, R11
+	MOVQ R8, 0(DI)
+	MOVQ R9, 8(DI)
+	MOVQ R10, 16(DI)
+	MOVQ R11, 24(DI)
+	MOVQ R12, R8
+	LEAQ 32(SI), SI
+	LEAQ 32(DI), DI
+	DECQ BX
+	JNZ  rshift4Loop
+
+rshiftLast:
+	SHRQ CX, R8
+	MOVQ R8, 0(DI)
+
+rshiftRet:
+	MOVQ AX, r+32(FP)
+	RET
+
+// func mpnMul1MULX(rp, up *uint64, n int64, v uint64) (cy uint64)
+//
+// Requires BMI2.
+TEXT ·mpnMul1MULX(SB), NOSPLIT, $0-40
+	MOVQ rp+0(FP), DI
+	MOVQ up+8(FP), SI
+	MOVQ n+16(FP), CX
+	MOVQ v+24(FP), DX
+	XORQ BX, BX // carry limb
+	TESTQ CX, CX
+	JLE  mul1Done
+	MOVQ CX, R9
+	ANDQ $3, R9
+	SHRQ $2, CX
+	TESTQ R9, R9
+	JZ   mul14
+
+mul11:
+	MULXQ 0(SI), R10, R11
+	ADDQ  BX, R10
+	ADCQ  $0, R11
+	MOVQ  R10, 0(DI)
+	MOVQ  R11, BX
+	LEAQ  8(SI), SI
+	LEAQ  8(DI), DI
+	DECQ  R9
+	JNZ   mul11
+
+mul14:
+	TESTQ CX, CX
+	JZ    mul1Done
+
+mul14Loop:
+	MULXQ 0(SI), R10, R11
+	MULXQ 8(SI), R12, R13
+	ADDQ  BX, R10
+	ADCQ  R11, R12
+	MOVQ  R10, 0(DI)
+	MOVQ  R12, 8(DI)
+	MULXQ 16(SI), R10, R11
+	ADCQ  R13, R10
+	MULXQ 24(SI), R12, BX
+	ADCQ  R11, R12
+	ADCQ  $0, BX
+	MOVQ  R10, 16(DI)
+	MOVQ  R12, 24(DI)
+	LEAQ  32(SI), SI
+	LEAQ  32(DI), DI
+	DECQ  CX
+	JNZ   mul14Loop
+
+mul1Done:
+	MOVQ BX, cy+32(FP)
+	RET
+
+// func mpnAddmul1ADX(rp, up *uint64, n int64, v uint64) (cy uint64)
+//
+// Requires BMI2 and ADX. The carries from adding the high product limbs and
+// from adding {rp, n} are propagated in two independent chains through CF
+// and OF and merged into the carry limb after every iteration.
+TEXT ·mpnAddmul1ADX(SB), NOSPLIT, $0-40
+	MOVQ rp+0(FP), DI
+	MOVQ up+8(FP), SI
+	MOVQ n+16(FP), CX
+	MOVQ v+24(FP), DX
+	XORQ BX, BX  // carry limb
+	XORQ R14, R14 // zero
+	TESTQ CX, CX
+	JLE  addmul1Done
+	MOVQ CX, R9
+	ANDQ $3, R9
+	SHRQ $2, CX
+	TESTQ R9, R9
+	JZ   addmul14
+
+addmul11:
+	XORQ  AX, AX // clear CF and OF
+	MULXQ 0(SI), R10, R11
+	ADCXQ BX, R10
+	ADOXQ 0(DI), R10
+	MOVQ  R10, 0(DI)
+	MOVQ  R11, BX
+	ADCXQ R14, BX
+	ADOXQ R14, BX
+	LEAQ  8(SI), SI
+	LEAQ  8(DI), DI
+	DECQ  R9
+	JNZ   addmul11
+
+addmul14:
+	TESTQ CX, CX
+	JZ    addmul1Done
+
+addmul14Loop:
+	XORQ  AX, AX // clear CF and OF
+	MULXQ 0(SI), R10, R11
+	ADCXQ BX, R10
+	ADOXQ 0(DI), R10
+	MOVQ  R10, 0(DI)
+	MULXQ 8(SI), R10, BX
+	ADCXQ R11, R10
+	ADOXQ 8(DI), R10
+	MOVQ  R10, 8(DI)
+	MULXQ 16(SI), R10, R11
+	ADCXQ BX, R10
+	ADOXQ 16(DI), R10
+	MOVQ  R10, 16(DI)
+	MULXQ 24(SI), R10, BX
+	ADCXQ R11, R10
+	ADOXQ 24(DI), R10
+	MOVQ  R10, 24(DI)
+	ADCXQ R14, BX
+	ADOXQ R14, BX
+	LEAQ  32(SI), SI
+	LEAQ  32(DI), DI
+	DECQ  CX
+	JNZ   addmul14Loop
+
+addmul1Done:
+	MOVQ BX, cy+32(FP)
+	RET
+
+// func mpnSubmul1MULX(rp, up *uint64, n int64, v uint64) (cy uint64)
+//
+// Requires BMI2.
+TEXT ·mpnSubmul1MULX(SB), NOSPLIT, $0-40
+	MOVQ rp+0(FP), DI
+	MOVQ up+8(FP), SI
+	MOVQ n+16(FP), CX
+	MOVQ v+24(FP), DX
+	XORQ BX, BX // borrow limb
+	TESTQ CX, CX
+	JLE  submul1Done
+
+submul1:
+	MULXQ 0(SI), R10, R11
+	ADDQ  BX, R10
+	ADCQ  $0, R11
+	MOVQ  0(DI), R12
+	SUBQ  R10, R12
+	ADCQ  $0, R11
+	MOVQ  R12, 0(DI)
+	MOVQ  R11, BX
+	LEAQ  8(SI), SI
+	LEAQ  8(DI), DI
+	DECQ  CX
+	JNZ   submul1
+
+submul1Done:
+	MOVQ BX, cy+32(FP)
+	RET
@@ -0,0 +1,160 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !purego
+// +build !purego
+
+package minigmp
+
+import (
+	"testing"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+func TestMpnAsm(t *testing.T) {
+	if !hasBMI2 || !hasADX {
+		t.Log("BMI2 and/or ADX not available, testing only the baseline kernels")
+	}
+
+	tls := crt.NewTLS()
+	t.Cleanup(tls.Close)
+	type op2 struct {
+		name      string
+		asm, gen  func(rp, ap, bp *uint64, n int64) uint64
+		available bool
+	}
+	type op1 struct {
+		name      string
+		asm, gen  func(rp, up *uint64, n int64, v uint64) uint64
+		available bool
+		shift     bool
+	}
+	ops2 := []op2{
+		{"add_n", mpnAddN, func(rp, ap, bp *uint64, n int64) uint64 { return _mpn_add_n_generic(tls, rp, ap, bp, n) }, true},
+		{"sub_n", mpnSubN, func(rp, ap, bp *uint64, n int64) uint64 { return _mpn_sub_n_generic(tls, rp, ap, bp, n) }, true},
+	}
+	ops1 := []op1{
+		{"mul_1", mpnMul1MULX, func(rp, up *uint64, n int64, v uint64) uint64 { return _mpn_mul_1_generic(tls, rp, up, n, v) }, hasBMI2, false},
+		{"addmul_1", mpnAddmul1ADX, func(rp, up *uint64, n int64, v uint64) uint64 { return _mpn_addmul_1_generic(tls, rp, up, n, v) }, hasBMI2 && hasADX, false},
+		{"submul_1", mpnSubmul1MULX, func(rp, up *uint64, n int64, v uint64) uint64 { return _mpn_submul_1_generic(tls, rp, up, n, v) }, hasBMI2, false},
+		{
+			"lshift",
+			func(rp, up *uint64, n int64, v uint64) uint64 { return mpnLshift(rp, up, n, uint32(v)) },
+			func(rp, up *uint64, n int64, v uint64) uint64 { return _mpn_lshift_generic(tls, rp, up, n, uint32(v)) },
+			true, true,
+		},
+		{
+			"rshift",
+			func(rp, up *uint64, n int64, v uint64) uint64 { return mpnRshift(rp, up, n, uint32(v)) },
+			func(rp, up *uint64, n int64, v uint64) uint64 { return _mpn_rshift_generic(tls, rp, up, n, uint32(v)) },
+			true, true,
+		},
+	}
+	for n := 1; n < 40; n++ {
+		a, b, r1, r2 := cLimbs(t, tls, n), cLimbs(t, tls, n), cLimbs(t, tls, n), cLimbs(t, tls, n)
+		for i := 0; i < 100; i++ {
+			copy(a, rndLimbs(n))
+			copy(b, rndLimbs(n))
+			r := rndLimbs(n)
+			for _, op := range ops2 {
+				copy(r1, r)
+				copy(r2, r)
+				c1 := op.asm(&r1[0], &a[0], &b[0], int64(n))
+				c2 := op.gen(&r2[0], &a[0], &b[0], int64(n))
+				if c1 != c2 || !eqLimbs(r1, r2) {
+					t.Fatalf("%s n=%v: got %#x %#x, expected %#x %#x", op.name, n, c1, r1, c2, r2)
+				}
+
+				// In place.
+				copy(r1, a)
+				copy(r2, a)
+				c1 = op.asm(&r1[0], &r1[0], &b[0], int64(n))
+				c2 = op.gen(&r2[0], &r2[0], &b[0], int64(n))
+				if c1 != c2 || !eqLimbs(r1, r2) {
+					t.Fatalf("%s n=%v in place: got %#x %#x, expected %#x %#x", op.name, n, c1, r1, c2, r2)
+				}
+			}
+			for _, op := range ops1 {
+				if !op.available {
+					continue
+				}
+
+				v := rndLimbs(1)[0]
+				if op.shift {
+					v = uint64(1 + rnd.Intn(63))
+				}
+				copy(r1, r)
+				copy(r2, r)
+				c1 := op.asm(&r1[0], &a[0], int64(n), v)
+				c2 := op.gen(&r2[0], &a[0], int64(n), v)
+				if c1 != c2 || !eqLimbs(r1, r2) {
+					t.Fatalf("%s n=%v v=%#x: got %#x %#x, expected %#x %#x", op.name, n, v, c1, r1, c2, r2)
+				}
+
+				// In place.
+				copy(r1, a)
+				copy(r2, a)
+				c1 = op.asm(&r1[0], &r1[0], int64(n), v)
+				c2 = op.gen(&r2[0], &r2[0], int64(n), v)
+				if c1 != c2 || !eqLimbs(r1, r2) {
+					t.Fatalf("%s n=%v v=%#x in place: got %#x %#x, expected %#x %#x", op.name, n, v, c1, r1, c2, r2)
+				}
+			}
+		}
+	}
+}
+
+// TestMpnDispatch tests the Xmpn_*_1 functions with the instruction set
+// extensions as detected and with BMI2 and/or ADX forced off, so the Go
+// fallbacks are tested on amd64 regardless of the CPU.
+func TestMpnDispatch(t *testing.T) {
+	defer func(bmi2, adx bool) { hasBMI2, hasADX = bmi2, adx }(hasBMI2, hasADX)
+
+	tls := crt.NewTLS()
+	t.Cleanup(tls.Close)
+	type op struct {
+		name string
+		f    func(tls *crt.TLS, rp, up *uint64, n int64, v uint64) uint64
+		gen  func(tls *crt.TLS, rp, up *uint64, n int64, v uint64) uint64
+	}
+	ops := []op{
+		{"mul_1", Xmpn_mul_1, _mpn_mul_1_generic},
+		{"addmul_1", Xmpn_addmul_1, _mpn_addmul_1_generic},
+		{"submul_1", Xmpn_submul_1, _mpn_submul_1_generic},
+	}
+	for _, c := range []struct{ bmi2, adx bool }{
+		{hasBMI2, hasADX},
+		{hasBMI2, false},
+		{false, false},
+	} {
+		hasBMI2, hasADX = c.bmi2, c.adx
+		for n := 1; n < 40; n++ {
+			a, r1, r2 := cLimbs(t, tls, n), cLimbs(t, tls, n), cLimbs(t, tls, n)
+			for i := 0; i < 50; i++ {
+				copy(a, rndLimbs(n))
+				r := rndLimbs(n)
+				v := rndLimbs(1)[0]
+				for _, op := range ops {
+					copy(r1, r)
+					copy(r2, r)
+					c1 := op.f(tls, &r1[0], &a[0], int64(n), v)
+					c2 := op.gen(tls, &r2[0], &a[0], int64(n), v)
+					if c1 != c2 || !eqLimbs(r1, r2) {
+						t.Fatalf("%s bmi2=%v adx=%v n=%v v=%#x: got %#x %#x, expected %#x %#x", op.name, c.bmi2, c.adx, n, v, c1, r1, c2, r2)
+					}
+
+					// In place.
+					copy(r1, a)
+					copy(r2, a)
+					c1 = op.f(tls, &r1[0], &r1[0], int64(n), v)
+					c2 = op.gen(tls, &r2[0], &r2[0], int64(n), v)
+					if c1 != c2 || !eqLimbs(r1, r2) {
+						t.Fatalf("%s bmi2=%v adx=%v n=%v v=%#x in place: got %#x %#x, expected %#x %#x", op.name, c.bmi2, c.adx, n, v, c1, r1, c2, r2)
+					}
+				}
+			}
+		}
+	}
+}
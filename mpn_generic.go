@@ -0,0 +1,53 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !amd64 || purego
+// +build !amd64 purego
+
+package minigmp
+
+import (
+	"github.com/cznic/ccgo/crt"
+)
+
+// Xmpn_add_n sets {rp, n} to {ap, n} + {bp, n} and returns the carry.
+func Xmpn_add_n(tls *crt.TLS, rp, ap, bp *limb, n mpSize) limb {
+	return addN(limbs(rp, n), limbs(ap, n), limbs(bp, n))
+}
+
+// Xmpn_sub_n sets {rp, n} to {ap, n} - {bp, n} and returns the borrow.
+func Xmpn_sub_n(tls *crt.TLS, rp, ap, bp *limb, n mpSize) limb {
+	return subN(limbs(rp, n), limbs(ap, n), limbs(bp, n))
+}
+
+// Xmpn_lshift sets {rp, n} to {up, n} shifted left by 0 < cnt < limbBits
+// bits and returns the bits shifted out.
+func Xmpn_lshift(tls *crt.TLS, rp, up *limb, n mpSize, cnt uint32) limb {
+	return lshift(limbs(rp, n), limbs(up, n), uint(cnt))
+}
+
+// Xmpn_rshift sets {rp, n} to {up, n} shifted right by 0 < cnt < limbBits
+// bits and returns the bits shifted out in the most significant bits of the
+// result.
+func Xmpn_rshift(tls *crt.TLS, rp, up *limb, n mpSize, cnt uint32) limb {
+	return rshift(limbs(rp, n), limbs(up, n), uint(cnt))
+}
+
+// Xmpn_mul_1 sets {rp, n} to {up, n} * vl and returns the high limb of the
+// product.
+func Xmpn_mul_1(tls *crt.TLS, rp, up *limb, n mpSize, vl limb) limb {
+	return mul1(limbs(rp, n), limbs(up, n), vl)
+}
+
+// Xmpn_addmul_1 adds {up, n} * vl to {rp, n} and returns the high limb of
+// the sum.
+func Xmpn_addmul_1(tls *crt.TLS, rp, up *limb, n mpSize, vl limb) limb {
+	return addmul1(limbs(rp, n), limbs(up, n), vl)
+}
+
+// Xmpn_submul_1 subtracts {up, n} * vl from {rp, n} and returns the borrow
+// limb.
+func Xmpn_submul_1(tls *crt.TLS, rp, up *limb, n mpSize, vl limb) limb {
+	return submul1(limbs(rp, n), limbs(up, n), vl)
+}
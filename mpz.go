@@ -0,0 +1,63 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"math/bits"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// Helpers for the hand written mpz functions.
+
+func mpzAbsSize(x *[1]Xmpz_srcptr) mpSize {
+	if n := mpSize(x[0].X_mp_size); n >= 0 {
+		return n
+	}
+
+	return -mpSize(x[0].X_mp_size)
+}
+
+// mpzLimbs returns the limbs of |x|. The result aliases x.
+func mpzLimbs(x *[1]Xmpz_srcptr) []limb { return limbs(x[0].X_mp_d, mpzAbsSize(x)) }
+
+// mpzSetLimbs sets r to the non-negative value of s. s must not alias r.
+func mpzSetLimbs(tls *crt.TLS, r *[1]Xmpz_srcptr, s []limb) {
+	n := mpSize(len(s))
+	if n == 0 {
+		r[0].X_mp_size = 0
+		return
+	}
+
+	copy(limbs(Xmpz_limbs_write(tls, r, n), n), s)
+	Xmpz_limbs_finish(tls, r, n)
+}
+
+// mpzBitLen returns the number of bits of |x|.
+func mpzBitLen(x *[1]Xmpz_srcptr) int {
+	s := mpzLimbs(x)
+	if len(s) == 0 {
+		return 0
+	}
+
+	return (len(s)-1)*limbBits + bits.Len(uint(s[len(s)-1]))
+}
+
+// mpzBit returns bit i of |x|.
+func mpzBit(x *[1]Xmpz_srcptr, i int) limb {
+	s := mpzLimbs(x)
+	if j := i / limbBits; j < len(s) {
+		return s[j] >> uint(i%limbBits) & 1
+	}
+
+	return 0
+}
+
+// padLimbs returns s zero extended to n limbs in a new slice.
+func padLimbs(s []limb, n int) []limb {
+	r := make([]limb, n)
+	copy(r, s)
+	return r
+}
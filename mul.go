@@ -0,0 +1,164 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// DefaultMulParallelThreshold is the initial value of the parallel
+// multiplication threshold, in limbs.
+const DefaultMulParallelThreshold = 1 << 10
+
+var (
+	mulMu        sync.Mutex
+	mulHelpers   atomic.Value // chan struct{}, nil when parallelism is off
+	mulParallel  int32        // Guarded by mulMu.
+	mulThreshold int64        = DefaultMulParallelThreshold
+)
+
+// SetMulParallelism sets the maximum number of goroutines a single large
+// multiplication may use and returns the previous value. Values below 2, the
+// default, disable parallel multiplication.
+//
+// The limit bounds a pool of n-1 helper goroutines shared by all
+// multiplications in the process. A multiplication that cannot obtain a
+// helper computes the respective part of the product itself, so results are
+// always the same as those of the sequential algorithm.
+func SetMulParallelism(n int) (old int) {
+	mulMu.Lock()
+	defer mulMu.Unlock()
+
+	old = int(mulParallel)
+	if old < 1 {
+		old = 1
+	}
+	if n < 1 {
+		n = 1
+	}
+	mulParallel = int32(n)
+	var c chan struct{}
+	if n > 1 {
+		c = make(chan struct{}, n-1)
+	}
+	mulHelpers.Store(c)
+	return old
+}
+
+// SetMulParallelThreshold sets the minimum size, in limbs, of both the smaller
+// operand and of each part of the larger one, for which a multiplication is
+// split across goroutines. It returns the previous value. Values below 1 are
+// treated as 1.
+func SetMulParallelThreshold(limbs int) (old int) {
+	if limbs < 1 {
+		limbs = 1
+	}
+	return int(atomic.SwapInt64(&mulThreshold, int64(limbs)))
+}
+
+// Xmpn_mul sets {rp, un+vn} to the product of {up, un} and {vp, vn} and
+// returns the most significant limb of the result. It requires un >= vn > 0
+// and the destination must not overlap either of the sources.
+func Xmpn_mul(tls *crt.TLS, rp *limb, up *limb, un mpSize, vp *limb, vn mpSize) limb {
+	if k := mulParts(un, vn); k > 1 {
+		return mpnMulParallel(tls, rp, up, un, vp, vn, k)
+	}
+
+	return _mpn_mul_basecase(tls, rp, up, un, vp, vn)
+}
+
+// mulParts returns the number of parts a un by vn limb product should be
+// split into.
+func mulParts(un, vn mpSize) mpSize {
+	c, _ := mulHelpers.Load().(chan struct{})
+	if c == nil {
+		return 1
+	}
+
+	t := mpSize(atomic.LoadInt64(&mulThreshold))
+	if vn < t {
+		return 1
+	}
+
+	k := un / t
+	if n := mpSize(cap(c)) + 1; k > n {
+		k = n
+	}
+	return k
+}
+
+// mpnMulParallel computes the product of {up, un} and {vp, vn} by splitting
+// {up, un} into k parts. The partial products are computed concurrently as
+// long as helper goroutines are available and added together afterwards.
+func mpnMulParallel(tls *crt.TLS, rp *limb, up *limb, un mpSize, vp *limb, vn mpSize, k mpSize) limb {
+	c, _ := mulHelpers.Load().(chan struct{})
+	n := (un + k - 1) / k
+	parts := make([][]limb, k)
+	var wg sync.WaitGroup
+	for i := mpSize(1); i < k; i++ {
+		off := i * n
+		pn := n
+		if off+pn > un {
+			pn = un - off
+		}
+		if pn <= 0 {
+			break
+		}
+
+		p := make([]limb, pn+vn)
+		parts[i] = p
+		mul := func(tls *crt.TLS) { mpnMulPart(tls, &p[0], elem0(up, uintptr(off)), pn, vp, vn) }
+		select {
+		case c <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				tls := crt.NewTLS()
+
+				defer func() {
+					tls.Close()
+					<-c
+					wg.Done()
+				}()
+
+				mul(tls)
+			}()
+		default:
+			mul(tls)
+		}
+	}
+
+	// The first part goes directly to the destination. The rest of it is
+	// cleared to accumulate the other parts.
+	mpnMulPart(tls, rp, up, n, vp, vn)
+	rn := un + vn
+	Xmpn_zero(tls, elem0(rp, uintptr(n+vn)), rn-n-vn)
+	wg.Wait()
+	for i, p := range parts {
+		if len(p) == 0 {
+			continue
+		}
+
+		off := mpSize(i) * n
+		if Xmpn_add(tls, elem0(rp, uintptr(off)), elem0(rp, uintptr(off)), rn-off, &p[0], mpSize(len(p))) != 0 {
+			panic("internal error")
+		}
+	}
+	wipeLimbs(tls, parts...)
+	return *elem0(rp, uintptr(rn-1))
+}
+
+// mpnMulPart sets {rp, un+vn} to the product of {up, un} and {vp, vn} using
+// the basecase algorithm, regardless of which operand is larger.
+func mpnMulPart(tls *crt.TLS, rp *limb, up *limb, un mpSize, vp *limb, vn mpSize) {
+	if un >= vn {
+		_mpn_mul_basecase(tls, rp, up, un, vp, vn)
+		return
+	}
+
+	_mpn_mul_basecase(tls, rp, vp, vn, up, un)
+}
@@ -0,0 +1,248 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+const (
+	sievePrimesMax = 1 << 11 // Candidates are sieved by the odd primes below.
+	sieveMin       = 1 << 16 // Smaller candidates are tested without sieving.
+	sieveSize      = 1 << 10 // Number of odd candidates sieved at once.
+)
+
+// sievePrimes are the odd primes below sievePrimesMax.
+var sievePrimes = func() (r []ulong) {
+	composite := make([]bool, sievePrimesMax)
+	for i := 3; i < sievePrimesMax; i += 2 {
+		if composite[i] {
+			continue
+		}
+
+		r = append(r, ulong(i))
+		for j := i * i; j < sievePrimesMax; j += 2 * i {
+			composite[j] = true
+		}
+	}
+	return r
+}()
+
+// Xmpz_nextprime sets r to the least prime greater than n. The result is
+// prime in the sense of Xmpz_prime_p with PrimeBPSW.
+func Xmpz_nextprime(tls *crt.TLS, r, n *[1]Xmpz_srcptr) {
+	if Xmpz_cmp_ui(tls, n, 2) < 0 {
+		Xmpz_set_ui(tls, r, 2)
+		return
+	}
+
+	var x [1]Xmpz_srcptr
+	Xmpz_init(tls, &x)
+
+	defer Xmpz_clear(tls, &x)
+
+	Xmpz_add_ui(tls, &x, n, 1)
+	Xmpz_setbit(tls, &x, 0)
+	scanPrime(tls, &x, false, false)
+	Xmpz_swap(tls, r, &x)
+}
+
+// Xmpz_prevprime sets r to the greatest prime less than n and returns 2 if r
+// is prime and 1 if r is probably prime, in the sense of Xmpz_prime_p with
+// PrimeBPSW. If there is no such prime, n < 3, r is not modified and the
+// result is 0.
+func Xmpz_prevprime(tls *crt.TLS, r, n *[1]Xmpz_srcptr) int32 {
+	if Xmpz_cmp_ui(tls, n, 3) < 0 {
+		return 0
+	}
+
+	if Xmpz_cmp_ui(tls, n, 3) == 0 {
+		Xmpz_set_ui(tls, r, 2)
+		return 2
+	}
+
+	var x [1]Xmpz_srcptr
+	Xmpz_init(tls, &x)
+
+	defer Xmpz_clear(tls, &x)
+
+	Xmpz_sub_ui(tls, &x, n, 1)
+	if mpzLow(&x)&1 == 0 {
+		Xmpz_sub_ui(tls, &x, &x, 1)
+	}
+	p := scanPrime(tls, &x, true, false)
+	Xmpz_swap(tls, r, &x)
+	return p
+}
+
+// scanPrime sets the odd x >= 3 to the first of x, x+2, x+4, ..., or of x,
+// x-2, x-4, ... if down, which passes bpsw, or safePrime if safe, and returns
+// the result of the test. Going down, the scan stops at 3.
+func scanPrime(tls *crt.TLS, x *[1]Xmpz_srcptr, down, safe bool) int32 {
+	test, step := bpsw, Xmpz_add_ui
+	if safe {
+		test = safePrime
+	}
+	if down {
+		step = Xmpz_sub_ui
+	}
+	var s []bool
+	var y [1]Xmpz_srcptr
+	Xmpz_init(tls, &y)
+
+	defer Xmpz_clear(tls, &y)
+
+	for {
+		if Xmpz_cmp_ui(tls, x, sieveMin) < 0 || down && Xmpz_cmp_ui(tls, x, sieveMin+2*sieveSize) < 0 {
+			if r := test(tls, x); r != 0 {
+				return r
+			}
+
+			step(tls, x, x, 2)
+			continue
+		}
+
+		if s == nil {
+			s = make([]bool, sieveSize)
+		}
+		sieve(tls, s, x, down, safe)
+		for i, composite := range s {
+			if composite {
+				continue
+			}
+
+			step(tls, &y, x, ulong(2*i))
+			if r := test(tls, &y); r != 0 {
+				Xmpz_swap(tls, x, &y)
+				return r
+			}
+		}
+		step(tls, x, x, 2*sieveSize)
+	}
+}
+
+// sieve sets s[i] to whether x+2i, or x-2i if down, is divisible by one of
+// the sievePrimes or, if safe, whether twice the value plus one is. x must be
+// odd and greater than the sievePrimes.
+func sieve(tls *crt.TLS, s []bool, x *[1]Xmpz_srcptr, down, safe bool) {
+	for i := range s {
+		s[i] = false
+	}
+	// x+2i = c (mod p) for i = (c-x)/2 (mod p), 1/2 = (p+1)/2 (mod p).
+	mark := func(p, r, c ulong) {
+		d := (c + p - r) % p
+		if down {
+			d = (r + p - c) % p
+		}
+		for i := d * ((p + 1) / 2) % p; i < ulong(len(s)); i += p {
+			s[i] = true
+		}
+	}
+	for _, p := range sievePrimes {
+		r := Xmpz_fdiv_ui(tls, x, p)
+		mark(p, r, 0)
+		if safe {
+			// 2v+1 = 0 (mod p) for v = (p-1)/2 (mod p).
+			mark(p, r, (p-1)/2)
+		}
+	}
+}
+
+// safePrime returns the result of bpsw for both q and 2q+1, the smaller of
+// the two.
+func safePrime(tls *crt.TLS, q *[1]Xmpz_srcptr) int32 {
+	r := bpsw(tls, q)
+	if r == 0 {
+		return 0
+	}
+
+	var p [1]Xmpz_srcptr
+	Xmpz_init(tls, &p)
+
+	defer Xmpz_clear(tls, &p)
+
+	Xmpz_mul_2exp(tls, &p, q, 1)
+	Xmpz_add_ui(tls, &p, &p, 1)
+	if s := bpsw(tls, &p); s < r {
+		r = s
+	}
+	return r
+}
+
+// randOdd sets r to a random odd value of exactly bits bits, bits >= 2, read
+// from rand.
+func randOdd(tls *crt.TLS, r *[1]Xmpz_srcptr, rand io.Reader, bits ulong) error {
+	b := make([]byte, (bits+7)/8)
+	if _, err := io.ReadFull(rand, b); err != nil {
+		return err
+	}
+
+	Xmpz_set_big(tls, r, new(big.Int).SetBytes(b))
+	Xmpz_tdiv_r_2exp(tls, r, r, bits)
+	Xmpz_setbit(tls, r, bits-1)
+	Xmpz_setbit(tls, r, 0)
+	return nil
+}
+
+// Xmpz_rand_prime sets r to a random prime of exactly bits bits, reading
+// random bytes from rand. bits must be at least 2. The error of rand, if any,
+// is returned and r is not modified in that case. The result is prime in the
+// sense of Xmpz_prime_p with PrimeBPSW.
+func Xmpz_rand_prime(tls *crt.TLS, r *[1]Xmpz_srcptr, rand io.Reader, bits ulong) error {
+	if bits < 2 {
+		return errors.New("mpz_rand_prime: prime size must be at least 2 bits")
+	}
+
+	return randPrime(tls, r, rand, bits, false)
+}
+
+// Xmpz_rand_safe_prime sets r to a random safe prime p of exactly bits bits,
+// that is, p = 2q+1 where q is prime as well, reading random bytes from rand.
+// bits must be at least 3. The error of rand, if any, is returned and r is not
+// modified in that case. Both p and q are prime in the sense of Xmpz_prime_p
+// with PrimeBPSW.
+func Xmpz_rand_safe_prime(tls *crt.TLS, r *[1]Xmpz_srcptr, rand io.Reader, bits ulong) error {
+	if bits < 3 {
+		return errors.New("mpz_rand_safe_prime: prime size must be at least 3 bits")
+	}
+
+	var q [1]Xmpz_srcptr
+	Xmpz_init(tls, &q)
+
+	defer Xmpz_clear(tls, &q)
+
+	if err := randPrime(tls, &q, rand, bits-1, true); err != nil {
+		return err
+	}
+
+	Xmpz_mul_2exp(tls, r, &q, 1)
+	Xmpz_add_ui(tls, r, r, 1)
+	return nil
+}
+
+// randPrime sets r to the result of scanPrime from a random odd value of bits
+// bits, retrying until the result has bits bits.
+func randPrime(tls *crt.TLS, r *[1]Xmpz_srcptr, rand io.Reader, bits ulong, safe bool) error {
+	var x [1]Xmpz_srcptr
+	Xmpz_init(tls, &x)
+
+	defer Xmpz_clear(tls, &x)
+
+	for {
+		if err := randOdd(tls, &x, rand, bits); err != nil {
+			return err
+		}
+
+		scanPrime(tls, &x, false, safe)
+		if Xmpz_sizeinbase(tls, &x, 2) == sizeT(bits) {
+			Xmpz_swap(tls, r, &x)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,1877 @@
+// Code generated by 'go run wrappers.go', DO NOT EDIT.
+
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"unsafe"
+)
+
+// Gmp_randclear is like Xgmp_randclear but does not take a TLS.
+func Gmp_randclear(s *RandState) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xgmp_randclear(tls, s)
+}
+
+// Gmp_randinit_default is like Xgmp_randinit_default but does not take a TLS.
+func Gmp_randinit_default(s *RandState) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xgmp_randinit_default(tls, s)
+}
+
+// Gmp_randinit_lc_2exp is like Xgmp_randinit_lc_2exp but does not take a TLS.
+func Gmp_randinit_lc_2exp(s *RandState, a *[1]Xmpz_srcptr, c ulong, m2exp ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xgmp_randinit_lc_2exp(tls, s, a, c, m2exp)
+}
+
+// Gmp_randinit_mt is like Xgmp_randinit_mt but does not take a TLS.
+func Gmp_randinit_mt(s *RandState) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xgmp_randinit_mt(tls, s)
+}
+
+// Gmp_randinit_reader is like Xgmp_randinit_reader but does not take a TLS.
+func Gmp_randinit_reader(s *RandState, r io.Reader) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xgmp_randinit_reader(tls, s, r)
+}
+
+// Gmp_randseed is like Xgmp_randseed but does not take a TLS.
+func Gmp_randseed(s *RandState, seed *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xgmp_randseed(tls, s, seed)
+}
+
+// Gmp_randseed_ui is like Xgmp_randseed_ui but does not take a TLS.
+func Gmp_randseed_ui(s *RandState, seed ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xgmp_randseed_ui(tls, s, seed)
+}
+
+// Gmp_urandomb_ui is like Xgmp_urandomb_ui but does not take a TLS.
+func Gmp_urandomb_ui(s *RandState, n ulong) ulong {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xgmp_urandomb_ui(tls, s, n)
+}
+
+// Gmp_urandomm_ui is like Xgmp_urandomm_ui but does not take a TLS.
+func Gmp_urandomm_ui(s *RandState, n ulong) ulong {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xgmp_urandomm_ui(tls, s, n)
+}
+
+// Mpn_add is like Xmpn_add but does not take a TLS.
+func Mpn_add(rp *uint32, ap *uint32, an int32, bp *uint32, bn int32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_add(tls, rp, ap, an, bp, bn)
+}
+
+// Mpn_add_1 is like Xmpn_add_1 but does not take a TLS.
+func Mpn_add_1(rp *uint32, ap *uint32, n int32, b uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_add_1(tls, rp, ap, n, b)
+}
+
+// Mpn_add_n is like Xmpn_add_n but does not take a TLS.
+func Mpn_add_n(rp *limb, ap *limb, bp *limb, n mpSize) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_add_n(tls, rp, ap, bp, n)
+}
+
+// Mpn_addmul_1 is like Xmpn_addmul_1 but does not take a TLS.
+func Mpn_addmul_1(rp *limb, up *limb, n mpSize, vl limb) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_addmul_1(tls, rp, up, n, vl)
+}
+
+// Mpn_cmp is like Xmpn_cmp but does not take a TLS.
+func Mpn_cmp(ap *uint32, bp *uint32, n int32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_cmp(tls, ap, bp, n)
+}
+
+// Mpn_cnd_add_n is like Xmpn_cnd_add_n but does not take a TLS.
+func Mpn_cnd_add_n(cnd limb, rp *limb, s1p *limb, s2p *limb, n mpSize) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_cnd_add_n(tls, cnd, rp, s1p, s2p, n)
+}
+
+// Mpn_cnd_sub_n is like Xmpn_cnd_sub_n but does not take a TLS.
+func Mpn_cnd_sub_n(cnd limb, rp *limb, s1p *limb, s2p *limb, n mpSize) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_cnd_sub_n(tls, cnd, rp, s1p, s2p, n)
+}
+
+// Mpn_cnd_swap is like Xmpn_cnd_swap but does not take a TLS.
+func Mpn_cnd_swap(cnd limb, ap *limb, bp *limb, n mpSize) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_cnd_swap(tls, cnd, ap, bp, n)
+}
+
+// Mpn_com is like Xmpn_com but does not take a TLS.
+func Mpn_com(rp *uint32, up *uint32, n int32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_com(tls, rp, up, n)
+}
+
+// Mpn_copyd is like Xmpn_copyd but does not take a TLS.
+func Mpn_copyd(d *uint32, s *uint32, n int32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_copyd(tls, d, s, n)
+}
+
+// Mpn_copyi is like Xmpn_copyi but does not take a TLS.
+func Mpn_copyi(d *uint32, s *uint32, n int32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_copyi(tls, d, s, n)
+}
+
+// Mpn_get_str is like Xmpn_get_str but does not take a TLS.
+func Mpn_get_str(sp *uint8, base int32, up *uint32, un int32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_get_str(tls, sp, base, up, un)
+}
+
+// Mpn_invert_3by2 is like Xmpn_invert_3by2 but does not take a TLS.
+func Mpn_invert_3by2(u1 limb, u0 limb) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_invert_3by2(tls, u1, u0)
+}
+
+// Mpn_lshift is like Xmpn_lshift but does not take a TLS.
+func Mpn_lshift(rp *limb, up *limb, n mpSize, cnt uint32) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_lshift(tls, rp, up, n, cnt)
+}
+
+// Mpn_mul is like Xmpn_mul but does not take a TLS.
+func Mpn_mul(rp *limb, up *limb, un mpSize, vp *limb, vn mpSize) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_mul(tls, rp, up, un, vp, vn)
+}
+
+// Mpn_mul_1 is like Xmpn_mul_1 but does not take a TLS.
+func Mpn_mul_1(rp *limb, up *limb, n mpSize, vl limb) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_mul_1(tls, rp, up, n, vl)
+}
+
+// Mpn_mul_n is like Xmpn_mul_n but does not take a TLS.
+func Mpn_mul_n(rp *uint32, ap *uint32, bp *uint32, n int32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_mul_n(tls, rp, ap, bp, n)
+}
+
+// Mpn_neg is like Xmpn_neg but does not take a TLS.
+func Mpn_neg(rp *uint32, up *uint32, n int32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_neg(tls, rp, up, n)
+}
+
+// Mpn_perfect_square_p is like Xmpn_perfect_square_p but does not take a TLS.
+func Mpn_perfect_square_p(p *uint32, n int32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_perfect_square_p(tls, p, n)
+}
+
+// Mpn_popcount is like Xmpn_popcount but does not take a TLS.
+func Mpn_popcount(p *uint32, n int32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_popcount(tls, p, n)
+}
+
+// Mpn_rshift is like Xmpn_rshift but does not take a TLS.
+func Mpn_rshift(rp *limb, up *limb, n mpSize, cnt uint32) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_rshift(tls, rp, up, n, cnt)
+}
+
+// Mpn_scan0 is like Xmpn_scan0 but does not take a TLS.
+func Mpn_scan0(ptr *uint32, bit uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_scan0(tls, ptr, bit)
+}
+
+// Mpn_scan1 is like Xmpn_scan1 but does not take a TLS.
+func Mpn_scan1(ptr *uint32, bit uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_scan1(tls, ptr, bit)
+}
+
+// Mpn_sec_add_1 is like Xmpn_sec_add_1 but does not take a TLS.
+func Mpn_sec_add_1(rp *limb, ap *limb, n mpSize, b limb, tp *limb) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_add_1(tls, rp, ap, n, b, tp)
+}
+
+// Mpn_sec_add_1_itch is like Xmpn_sec_add_1_itch but does not take a TLS.
+func Mpn_sec_add_1_itch(n mpSize) mpSize {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_add_1_itch(tls, n)
+}
+
+// Mpn_sec_div_qr is like Xmpn_sec_div_qr but does not take a TLS.
+func Mpn_sec_div_qr(qp *limb, np *limb, nn mpSize, dp *limb, dn mpSize, tp *limb) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_div_qr(tls, qp, np, nn, dp, dn, tp)
+}
+
+// Mpn_sec_div_qr_itch is like Xmpn_sec_div_qr_itch but does not take a TLS.
+func Mpn_sec_div_qr_itch(nn mpSize, dn mpSize) mpSize {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_div_qr_itch(tls, nn, dn)
+}
+
+// Mpn_sec_div_r is like Xmpn_sec_div_r but does not take a TLS.
+func Mpn_sec_div_r(np *limb, nn mpSize, dp *limb, dn mpSize, tp *limb) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_sec_div_r(tls, np, nn, dp, dn, tp)
+}
+
+// Mpn_sec_div_r_itch is like Xmpn_sec_div_r_itch but does not take a TLS.
+func Mpn_sec_div_r_itch(nn mpSize, dn mpSize) mpSize {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_div_r_itch(tls, nn, dn)
+}
+
+// Mpn_sec_invert is like Xmpn_sec_invert but does not take a TLS.
+func Mpn_sec_invert(rp *limb, ap *limb, mp *limb, n mpSize, nbcnt ulong, tp *limb) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_invert(tls, rp, ap, mp, n, nbcnt, tp)
+}
+
+// Mpn_sec_invert_itch is like Xmpn_sec_invert_itch but does not take a TLS.
+func Mpn_sec_invert_itch(n mpSize) mpSize {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_invert_itch(tls, n)
+}
+
+// Mpn_sec_mul is like Xmpn_sec_mul but does not take a TLS.
+func Mpn_sec_mul(rp *limb, ap *limb, an mpSize, bp *limb, bn mpSize, tp *limb) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_sec_mul(tls, rp, ap, an, bp, bn, tp)
+}
+
+// Mpn_sec_mul_itch is like Xmpn_sec_mul_itch but does not take a TLS.
+func Mpn_sec_mul_itch(an mpSize, bn mpSize) mpSize {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_mul_itch(tls, an, bn)
+}
+
+// Mpn_sec_powm is like Xmpn_sec_powm but does not take a TLS.
+func Mpn_sec_powm(rp *limb, bp *limb, bn mpSize, ep *limb, enb ulong, mp *limb, n mpSize, tp *limb) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_sec_powm(tls, rp, bp, bn, ep, enb, mp, n, tp)
+}
+
+// Mpn_sec_powm_itch is like Xmpn_sec_powm_itch but does not take a TLS.
+func Mpn_sec_powm_itch(bn mpSize, enb ulong, n mpSize) mpSize {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_powm_itch(tls, bn, enb, n)
+}
+
+// Mpn_sec_sqr is like Xmpn_sec_sqr but does not take a TLS.
+func Mpn_sec_sqr(rp *limb, ap *limb, an mpSize, tp *limb) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_sec_sqr(tls, rp, ap, an, tp)
+}
+
+// Mpn_sec_sqr_itch is like Xmpn_sec_sqr_itch but does not take a TLS.
+func Mpn_sec_sqr_itch(an mpSize) mpSize {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_sqr_itch(tls, an)
+}
+
+// Mpn_sec_sub_1 is like Xmpn_sec_sub_1 but does not take a TLS.
+func Mpn_sec_sub_1(rp *limb, ap *limb, n mpSize, b limb, tp *limb) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_sub_1(tls, rp, ap, n, b, tp)
+}
+
+// Mpn_sec_sub_1_itch is like Xmpn_sec_sub_1_itch but does not take a TLS.
+func Mpn_sec_sub_1_itch(n mpSize) mpSize {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sec_sub_1_itch(tls, n)
+}
+
+// Mpn_sec_tabselect is like Xmpn_sec_tabselect but does not take a TLS.
+func Mpn_sec_tabselect(rp *limb, tab *limb, n mpSize, nents mpSize, which mpSize) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_sec_tabselect(tls, rp, tab, n, nents, which)
+}
+
+// Mpn_set_str is like Xmpn_set_str but does not take a TLS.
+func Mpn_set_str(rp *uint32, sp *uint8, sn uint32, base int32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_set_str(tls, rp, sp, sn, base)
+}
+
+// Mpn_sqr is like Xmpn_sqr but does not take a TLS.
+func Mpn_sqr(rp *uint32, ap *uint32, n int32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_sqr(tls, rp, ap, n)
+}
+
+// Mpn_sqrtrem is like Xmpn_sqrtrem but does not take a TLS.
+func Mpn_sqrtrem(sp *uint32, rp *uint32, p *uint32, n int32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sqrtrem(tls, sp, rp, p, n)
+}
+
+// Mpn_sub is like Xmpn_sub but does not take a TLS.
+func Mpn_sub(rp *uint32, ap *uint32, an int32, bp *uint32, bn int32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sub(tls, rp, ap, an, bp, bn)
+}
+
+// Mpn_sub_1 is like Xmpn_sub_1 but does not take a TLS.
+func Mpn_sub_1(rp *uint32, ap *uint32, n int32, b uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sub_1(tls, rp, ap, n, b)
+}
+
+// Mpn_sub_n is like Xmpn_sub_n but does not take a TLS.
+func Mpn_sub_n(rp *limb, ap *limb, bp *limb, n mpSize) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_sub_n(tls, rp, ap, bp, n)
+}
+
+// Mpn_submul_1 is like Xmpn_submul_1 but does not take a TLS.
+func Mpn_submul_1(rp *limb, up *limb, n mpSize, vl limb) limb {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_submul_1(tls, rp, up, n, vl)
+}
+
+// Mpn_zero is like Xmpn_zero but does not take a TLS.
+func Mpn_zero(rp *uint32, n int32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpn_zero(tls, rp, n)
+}
+
+// Mpn_zero_p is like Xmpn_zero_p but does not take a TLS.
+func Mpn_zero_p(rp *uint32, n int32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpn_zero_p(tls, rp, n)
+}
+
+// Mpz_abs is like Xmpz_abs but does not take a TLS.
+func Mpz_abs(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_abs(tls, r, u)
+}
+
+// Mpz_add is like Xmpz_add but does not take a TLS.
+func Mpz_add(r *[1]Xmpz_srcptr, a *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_add(tls, r, a, b)
+}
+
+// Mpz_add_ui is like Xmpz_add_ui but does not take a TLS.
+func Mpz_add_ui(r *[1]Xmpz_srcptr, a *[1]Xmpz_srcptr, b uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_add_ui(tls, r, a, b)
+}
+
+// Mpz_addmul is like Xmpz_addmul but does not take a TLS.
+func Mpz_addmul(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_addmul(tls, r, u, v)
+}
+
+// Mpz_addmul_ui is like Xmpz_addmul_ui but does not take a TLS.
+func Mpz_addmul_ui(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_addmul_ui(tls, r, u, v)
+}
+
+// Mpz_and is like Xmpz_and but does not take a TLS.
+func Mpz_and(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_and(tls, r, u, v)
+}
+
+// Mpz_bin_uiui is like Xmpz_bin_uiui but does not take a TLS.
+func Mpz_bin_uiui(r *[1]Xmpz_srcptr, n ulong, k ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_bin_uiui(tls, r, n, k)
+}
+
+// Mpz_bin_uiui_ctx is like Xmpz_bin_uiui_ctx but does not take a TLS.
+func Mpz_bin_uiui_ctx(ctx context.Context, r *[1]Xmpz_srcptr, n ulong, k ulong) error {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_bin_uiui_ctx(tls, ctx, r, n, k)
+}
+
+// Mpz_cdiv_q is like Xmpz_cdiv_q but does not take a TLS.
+func Mpz_cdiv_q(q *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_cdiv_q(tls, q, n, d)
+}
+
+// Mpz_cdiv_q_2exp is like Xmpz_cdiv_q_2exp but does not take a TLS.
+func Mpz_cdiv_q_2exp(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, cnt uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_cdiv_q_2exp(tls, r, u, cnt)
+}
+
+// Mpz_cdiv_q_ui is like Xmpz_cdiv_q_ui but does not take a TLS.
+func Mpz_cdiv_q_ui(q *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cdiv_q_ui(tls, q, n, d)
+}
+
+// Mpz_cdiv_qr is like Xmpz_cdiv_qr but does not take a TLS.
+func Mpz_cdiv_qr(q *[1]Xmpz_srcptr, r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_cdiv_qr(tls, q, r, n, d)
+}
+
+// Mpz_cdiv_qr_ui is like Xmpz_cdiv_qr_ui but does not take a TLS.
+func Mpz_cdiv_qr_ui(q *[1]Xmpz_srcptr, r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cdiv_qr_ui(tls, q, r, n, d)
+}
+
+// Mpz_cdiv_r is like Xmpz_cdiv_r but does not take a TLS.
+func Mpz_cdiv_r(r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_cdiv_r(tls, r, n, d)
+}
+
+// Mpz_cdiv_r_2exp is like Xmpz_cdiv_r_2exp but does not take a TLS.
+func Mpz_cdiv_r_2exp(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, cnt uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_cdiv_r_2exp(tls, r, u, cnt)
+}
+
+// Mpz_cdiv_r_ui is like Xmpz_cdiv_r_ui but does not take a TLS.
+func Mpz_cdiv_r_ui(r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cdiv_r_ui(tls, r, n, d)
+}
+
+// Mpz_cdiv_ui is like Xmpz_cdiv_ui but does not take a TLS.
+func Mpz_cdiv_ui(n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cdiv_ui(tls, n, d)
+}
+
+// Mpz_clear is like Xmpz_clear but does not take a TLS.
+func Mpz_clear(r *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_clear(tls, r)
+}
+
+// Mpz_clrbit is like Xmpz_clrbit but does not take a TLS.
+func Mpz_clrbit(d *[1]Xmpz_srcptr, bit_index uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_clrbit(tls, d, bit_index)
+}
+
+// Mpz_cmp is like Xmpz_cmp but does not take a TLS.
+func Mpz_cmp(a *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cmp(tls, a, b)
+}
+
+// Mpz_cmp_d is like Xmpz_cmp_d but does not take a TLS.
+func Mpz_cmp_d(x *[1]Xmpz_srcptr, d float64) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cmp_d(tls, x, d)
+}
+
+// Mpz_cmp_si is like Xmpz_cmp_si but does not take a TLS.
+func Mpz_cmp_si(u *[1]Xmpz_srcptr, v int32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cmp_si(tls, u, v)
+}
+
+// Mpz_cmp_ui is like Xmpz_cmp_ui but does not take a TLS.
+func Mpz_cmp_ui(u *[1]Xmpz_srcptr, v uint32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cmp_ui(tls, u, v)
+}
+
+// Mpz_cmpabs is like Xmpz_cmpabs but does not take a TLS.
+func Mpz_cmpabs(u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cmpabs(tls, u, v)
+}
+
+// Mpz_cmpabs_d is like Xmpz_cmpabs_d but does not take a TLS.
+func Mpz_cmpabs_d(x *[1]Xmpz_srcptr, d float64) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cmpabs_d(tls, x, d)
+}
+
+// Mpz_cmpabs_ui is like Xmpz_cmpabs_ui but does not take a TLS.
+func Mpz_cmpabs_ui(u *[1]Xmpz_srcptr, v uint32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_cmpabs_ui(tls, u, v)
+}
+
+// Mpz_com is like Xmpz_com but does not take a TLS.
+func Mpz_com(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_com(tls, r, u)
+}
+
+// Mpz_combit is like Xmpz_combit but does not take a TLS.
+func Mpz_combit(d *[1]Xmpz_srcptr, bit_index uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_combit(tls, d, bit_index)
+}
+
+// Mpz_congruent_p is like Xmpz_congruent_p but does not take a TLS.
+func Mpz_congruent_p(a *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr, m *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_congruent_p(tls, a, b, m)
+}
+
+// Mpz_crt is like Xmpz_crt but does not take a TLS.
+func Mpz_crt(r *[1]Xmpz_srcptr, m *[1]Xmpz_srcptr, c []Congruence) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_crt(tls, r, m, c)
+}
+
+// Mpz_divexact is like Xmpz_divexact but does not take a TLS.
+func Mpz_divexact(q *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_divexact(tls, q, n, d)
+}
+
+// Mpz_divexact_ui is like Xmpz_divexact_ui but does not take a TLS.
+func Mpz_divexact_ui(q *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_divexact_ui(tls, q, n, d)
+}
+
+// Mpz_divisible_p is like Xmpz_divisible_p but does not take a TLS.
+func Mpz_divisible_p(n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_divisible_p(tls, n, d)
+}
+
+// Mpz_divisible_ui_p is like Xmpz_divisible_ui_p but does not take a TLS.
+func Mpz_divisible_ui_p(n *[1]Xmpz_srcptr, d uint32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_divisible_ui_p(tls, n, d)
+}
+
+// Mpz_export is like Xmpz_export but does not take a TLS.
+func Mpz_export(r unsafe.Pointer, countp *uint32, order int32, size uint32, endian int32, nails uint32, u *[1]Xmpz_srcptr) unsafe.Pointer {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_export(tls, r, countp, order, size, endian, nails, u)
+}
+
+// Mpz_fac_ui is like Xmpz_fac_ui but does not take a TLS.
+func Mpz_fac_ui(r *[1]Xmpz_srcptr, n ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_fac_ui(tls, r, n)
+}
+
+// Mpz_fac_ui_ctx is like Xmpz_fac_ui_ctx but does not take a TLS.
+func Mpz_fac_ui_ctx(ctx context.Context, r *[1]Xmpz_srcptr, n ulong) error {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_fac_ui_ctx(tls, ctx, r, n)
+}
+
+// Mpz_fdiv_q is like Xmpz_fdiv_q but does not take a TLS.
+func Mpz_fdiv_q(q *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_fdiv_q(tls, q, n, d)
+}
+
+// Mpz_fdiv_q_2exp is like Xmpz_fdiv_q_2exp but does not take a TLS.
+func Mpz_fdiv_q_2exp(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, cnt uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_fdiv_q_2exp(tls, r, u, cnt)
+}
+
+// Mpz_fdiv_q_ui is like Xmpz_fdiv_q_ui but does not take a TLS.
+func Mpz_fdiv_q_ui(q *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_fdiv_q_ui(tls, q, n, d)
+}
+
+// Mpz_fdiv_qr is like Xmpz_fdiv_qr but does not take a TLS.
+func Mpz_fdiv_qr(q *[1]Xmpz_srcptr, r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_fdiv_qr(tls, q, r, n, d)
+}
+
+// Mpz_fdiv_qr_ui is like Xmpz_fdiv_qr_ui but does not take a TLS.
+func Mpz_fdiv_qr_ui(q *[1]Xmpz_srcptr, r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_fdiv_qr_ui(tls, q, r, n, d)
+}
+
+// Mpz_fdiv_r is like Xmpz_fdiv_r but does not take a TLS.
+func Mpz_fdiv_r(r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_fdiv_r(tls, r, n, d)
+}
+
+// Mpz_fdiv_r_2exp is like Xmpz_fdiv_r_2exp but does not take a TLS.
+func Mpz_fdiv_r_2exp(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, cnt uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_fdiv_r_2exp(tls, r, u, cnt)
+}
+
+// Mpz_fdiv_r_ui is like Xmpz_fdiv_r_ui but does not take a TLS.
+func Mpz_fdiv_r_ui(r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_fdiv_r_ui(tls, r, n, d)
+}
+
+// Mpz_fdiv_ui is like Xmpz_fdiv_ui but does not take a TLS.
+func Mpz_fdiv_ui(n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_fdiv_ui(tls, n, d)
+}
+
+// Mpz_fits_slong_p is like Xmpz_fits_slong_p but does not take a TLS.
+func Mpz_fits_slong_p(u *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_fits_slong_p(tls, u)
+}
+
+// Mpz_fits_ulong_p is like Xmpz_fits_ulong_p but does not take a TLS.
+func Mpz_fits_ulong_p(u *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_fits_ulong_p(tls, u)
+}
+
+// Mpz_gcd is like Xmpz_gcd but does not take a TLS.
+func Mpz_gcd(g *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_gcd(tls, g, u, v)
+}
+
+// Mpz_gcd_ui is like Xmpz_gcd_ui but does not take a TLS.
+func Mpz_gcd_ui(g *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_gcd_ui(tls, g, u, v)
+}
+
+// Mpz_gcdext is like Xmpz_gcdext but does not take a TLS.
+func Mpz_gcdext(g *[1]Xmpz_srcptr, s *[1]Xmpz_srcptr, t *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_gcdext(tls, g, s, t, u, v)
+}
+
+// Mpz_get_big is like Xmpz_get_big but does not take a TLS.
+func Mpz_get_big(z *big.Int, x *[1]Xmpz_srcptr) *big.Int {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_get_big(tls, z, x)
+}
+
+// Mpz_get_d is like Xmpz_get_d but does not take a TLS.
+func Mpz_get_d(u *[1]Xmpz_srcptr) float64 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_get_d(tls, u)
+}
+
+// Mpz_get_si is like Xmpz_get_si but does not take a TLS.
+func Mpz_get_si(u *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_get_si(tls, u)
+}
+
+// Mpz_get_str is like Xmpz_get_str but does not take a TLS.
+func Mpz_get_str(sp *int8, base int32, u *[1]Xmpz_srcptr) *int8 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_get_str(tls, sp, base, u)
+}
+
+// Mpz_get_ui is like Xmpz_get_ui but does not take a TLS.
+func Mpz_get_ui(u *[1]Xmpz_srcptr) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_get_ui(tls, u)
+}
+
+// Mpz_getlimbn is like Xmpz_getlimbn but does not take a TLS.
+func Mpz_getlimbn(u *[1]Xmpz_srcptr, n int32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_getlimbn(tls, u, n)
+}
+
+// Mpz_hamdist is like Xmpz_hamdist but does not take a TLS.
+func Mpz_hamdist(u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_hamdist(tls, u, v)
+}
+
+// Mpz_import is like Xmpz_import but does not take a TLS.
+func Mpz_import(r *[1]Xmpz_srcptr, count uint32, order int32, size uint32, endian int32, nails uint32, src unsafe.Pointer) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_import(tls, r, count, order, size, endian, nails, src)
+}
+
+// Mpz_init is like Xmpz_init but does not take a TLS.
+func Mpz_init(r *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_init(tls, r)
+}
+
+// Mpz_init2 is like Xmpz_init2 but does not take a TLS.
+func Mpz_init2(r *[1]Xmpz_srcptr, n ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_init2(tls, r, n)
+}
+
+// Mpz_init_set is like Xmpz_init_set but does not take a TLS.
+func Mpz_init_set(r *[1]Xmpz_srcptr, x *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_init_set(tls, r, x)
+}
+
+// Mpz_init_set_d is like Xmpz_init_set_d but does not take a TLS.
+func Mpz_init_set_d(r *[1]Xmpz_srcptr, x float64) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_init_set_d(tls, r, x)
+}
+
+// Mpz_init_set_si is like Xmpz_init_set_si but does not take a TLS.
+func Mpz_init_set_si(r *[1]Xmpz_srcptr, x int32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_init_set_si(tls, r, x)
+}
+
+// Mpz_init_set_str is like Xmpz_init_set_str but does not take a TLS.
+func Mpz_init_set_str(r *[1]Xmpz_srcptr, sp *int8, base int32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_init_set_str(tls, r, sp, base)
+}
+
+// Mpz_init_set_ui is like Xmpz_init_set_ui but does not take a TLS.
+func Mpz_init_set_ui(r *[1]Xmpz_srcptr, x uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_init_set_ui(tls, r, x)
+}
+
+// Mpz_invert is like Xmpz_invert but does not take a TLS.
+func Mpz_invert(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, m *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_invert(tls, r, u, m)
+}
+
+// Mpz_invert_batch is like Xmpz_invert_batch but does not take a TLS.
+func Mpz_invert_batch(r []*[1]Xmpz_srcptr, a []*[1]Xmpz_srcptr, m *[1]Xmpz_srcptr, g *[1]Xmpz_srcptr) int {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_invert_batch(tls, r, a, m, g)
+}
+
+// Mpz_ior is like Xmpz_ior but does not take a TLS.
+func Mpz_ior(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_ior(tls, r, u, v)
+}
+
+// Mpz_jacobi is like Xmpz_jacobi but does not take a TLS.
+func Mpz_jacobi(a *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_jacobi(tls, a, b)
+}
+
+// Mpz_kronecker is like Xmpz_kronecker but does not take a TLS.
+func Mpz_kronecker(a *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_kronecker(tls, a, b)
+}
+
+// Mpz_kronecker_si is like Xmpz_kronecker_si but does not take a TLS.
+func Mpz_kronecker_si(a *[1]Xmpz_srcptr, b long) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_kronecker_si(tls, a, b)
+}
+
+// Mpz_kronecker_ui is like Xmpz_kronecker_ui but does not take a TLS.
+func Mpz_kronecker_ui(a *[1]Xmpz_srcptr, b ulong) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_kronecker_ui(tls, a, b)
+}
+
+// Mpz_lcm is like Xmpz_lcm but does not take a TLS.
+func Mpz_lcm(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_lcm(tls, r, u, v)
+}
+
+// Mpz_lcm_ui is like Xmpz_lcm_ui but does not take a TLS.
+func Mpz_lcm_ui(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_lcm_ui(tls, r, u, v)
+}
+
+// Mpz_legendre is like Xmpz_legendre but does not take a TLS.
+func Mpz_legendre(a *[1]Xmpz_srcptr, p *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_legendre(tls, a, p)
+}
+
+// Mpz_limbs_finish is like Xmpz_limbs_finish but does not take a TLS.
+func Mpz_limbs_finish(x *[1]Xmpz_srcptr, xs mpSize) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_limbs_finish(tls, x, xs)
+}
+
+// Mpz_limbs_modify is like Xmpz_limbs_modify but does not take a TLS.
+func Mpz_limbs_modify(x *[1]Xmpz_srcptr, n int32) *uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_limbs_modify(tls, x, n)
+}
+
+// Mpz_limbs_read is like Xmpz_limbs_read but does not take a TLS.
+func Mpz_limbs_read(x *Xmpz_srcptr) *uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_limbs_read(tls, x)
+}
+
+// Mpz_limbs_write is like Xmpz_limbs_write but does not take a TLS.
+func Mpz_limbs_write(x *[1]Xmpz_srcptr, n int32) *uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_limbs_write(tls, x, n)
+}
+
+// Mpz_mod is like Xmpz_mod but does not take a TLS.
+func Mpz_mod(r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_mod(tls, r, n, d)
+}
+
+// Mpz_mod_ui is like Xmpz_mod_ui but does not take a TLS.
+func Mpz_mod_ui(r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_mod_ui(tls, r, n, d)
+}
+
+// Mpz_mul is like Xmpz_mul but does not take a TLS.
+func Mpz_mul(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_mul(tls, r, u, v)
+}
+
+// Mpz_mul_2exp is like Xmpz_mul_2exp but does not take a TLS.
+func Mpz_mul_2exp(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, n ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_mul_2exp(tls, r, u, n)
+}
+
+// Mpz_mul_2exp_ctx is like Xmpz_mul_2exp_ctx but does not take a TLS.
+func Mpz_mul_2exp_ctx(ctx context.Context, r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, n ulong) error {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_mul_2exp_ctx(tls, ctx, r, u, n)
+}
+
+// Mpz_mul_si is like Xmpz_mul_si but does not take a TLS.
+func Mpz_mul_si(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v int32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_mul_si(tls, r, u, v)
+}
+
+// Mpz_mul_ui is like Xmpz_mul_ui but does not take a TLS.
+func Mpz_mul_ui(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_mul_ui(tls, r, u, v)
+}
+
+// Mpz_neg is like Xmpz_neg but does not take a TLS.
+func Mpz_neg(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_neg(tls, r, u)
+}
+
+// Mpz_nextprime is like Xmpz_nextprime but does not take a TLS.
+func Mpz_nextprime(r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_nextprime(tls, r, n)
+}
+
+// Mpz_perfect_square_p is like Xmpz_perfect_square_p but does not take a TLS.
+func Mpz_perfect_square_p(u *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_perfect_square_p(tls, u)
+}
+
+// Mpz_popcount is like Xmpz_popcount but does not take a TLS.
+func Mpz_popcount(u *[1]Xmpz_srcptr) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_popcount(tls, u)
+}
+
+// Mpz_pow_ui is like Xmpz_pow_ui but does not take a TLS.
+func Mpz_pow_ui(r *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr, e ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_pow_ui(tls, r, b, e)
+}
+
+// Mpz_pow_ui_ctx is like Xmpz_pow_ui_ctx but does not take a TLS.
+func Mpz_pow_ui_ctx(ctx context.Context, r *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr, e ulong) error {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_pow_ui_ctx(tls, ctx, r, b, e)
+}
+
+// Mpz_powm is like Xmpz_powm but does not take a TLS.
+func Mpz_powm(r *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr, e *[1]Xmpz_srcptr, m *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_powm(tls, r, b, e, m)
+}
+
+// Mpz_powm_ctx is like Xmpz_powm_ctx but does not take a TLS.
+func Mpz_powm_ctx(ctx context.Context, r *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr, e *[1]Xmpz_srcptr, m *[1]Xmpz_srcptr) error {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_powm_ctx(tls, ctx, r, b, e, m)
+}
+
+// Mpz_powm_ui is like Xmpz_powm_ui but does not take a TLS.
+func Mpz_powm_ui(r *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr, elimb uint32, m *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_powm_ui(tls, r, b, elimb, m)
+}
+
+// Mpz_prevprime is like Xmpz_prevprime but does not take a TLS.
+func Mpz_prevprime(r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_prevprime(tls, r, n)
+}
+
+// Mpz_prime_p is like Xmpz_prime_p but does not take a TLS.
+func Mpz_prime_p(n *[1]Xmpz_srcptr, t PrimeTest, reps int32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_prime_p(tls, n, t, reps)
+}
+
+// Mpz_probab_prime_p is like Xmpz_probab_prime_p but does not take a TLS.
+func Mpz_probab_prime_p(n *[1]Xmpz_srcptr, reps int32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_probab_prime_p(tls, n, reps)
+}
+
+// Mpz_probab_prime_p_ctx is like Xmpz_probab_prime_p_ctx but does not take a TLS.
+func Mpz_probab_prime_p_ctx(ctx context.Context, n *[1]Xmpz_srcptr, reps int32) (int32, error) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_probab_prime_p_ctx(tls, ctx, n, reps)
+}
+
+// Mpz_rand_prime is like Xmpz_rand_prime but does not take a TLS.
+func Mpz_rand_prime(r *[1]Xmpz_srcptr, rand io.Reader, bits ulong) error {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_rand_prime(tls, r, rand, bits)
+}
+
+// Mpz_rand_safe_prime is like Xmpz_rand_safe_prime but does not take a TLS.
+func Mpz_rand_safe_prime(r *[1]Xmpz_srcptr, rand io.Reader, bits ulong) error {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_rand_safe_prime(tls, r, rand, bits)
+}
+
+// Mpz_realloc2 is like Xmpz_realloc2 but does not take a TLS.
+func Mpz_realloc2(x *[1]Xmpz_srcptr, n uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_realloc2(tls, x, n)
+}
+
+// Mpz_roinit_big is like Xmpz_roinit_big but does not take a TLS.
+func Mpz_roinit_big(r *[1]Xmpz_srcptr, x *big.Int) *Xmpz_srcptr {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_roinit_big(tls, r, x)
+}
+
+// Mpz_roinit_n is like Xmpz_roinit_n but does not take a TLS.
+func Mpz_roinit_n(x *[1]Xmpz_srcptr, xp *uint32, xs int32) *Xmpz_srcptr {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_roinit_n(tls, x, xp, xs)
+}
+
+// Mpz_root is like Xmpz_root but does not take a TLS.
+func Mpz_root(x *[1]Xmpz_srcptr, y *[1]Xmpz_srcptr, z ulong) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_root(tls, x, y, z)
+}
+
+// Mpz_rootrem is like Xmpz_rootrem but does not take a TLS.
+func Mpz_rootrem(x *[1]Xmpz_srcptr, r *[1]Xmpz_srcptr, y *[1]Xmpz_srcptr, z ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_rootrem(tls, x, r, y, z)
+}
+
+// Mpz_rrandomb is like Xmpz_rrandomb but does not take a TLS.
+func Mpz_rrandomb(r *[1]Xmpz_srcptr, s *RandState, n ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_rrandomb(tls, r, s, n)
+}
+
+// Mpz_scan0 is like Xmpz_scan0 but does not take a TLS.
+func Mpz_scan0(u *[1]Xmpz_srcptr, starting_bit uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_scan0(tls, u, starting_bit)
+}
+
+// Mpz_scan1 is like Xmpz_scan1 but does not take a TLS.
+func Mpz_scan1(u *[1]Xmpz_srcptr, starting_bit uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_scan1(tls, u, starting_bit)
+}
+
+// Mpz_set is like Xmpz_set but does not take a TLS.
+func Mpz_set(r *[1]Xmpz_srcptr, x *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_set(tls, r, x)
+}
+
+// Mpz_set_big is like Xmpz_set_big but does not take a TLS.
+func Mpz_set_big(r *[1]Xmpz_srcptr, x *big.Int) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_set_big(tls, r, x)
+}
+
+// Mpz_set_d is like Xmpz_set_d but does not take a TLS.
+func Mpz_set_d(r *[1]Xmpz_srcptr, x float64) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_set_d(tls, r, x)
+}
+
+// Mpz_set_si is like Xmpz_set_si but does not take a TLS.
+func Mpz_set_si(r *[1]Xmpz_srcptr, x int32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_set_si(tls, r, x)
+}
+
+// Mpz_set_str is like Xmpz_set_str but does not take a TLS.
+func Mpz_set_str(r *[1]Xmpz_srcptr, sp *int8, base int32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_set_str(tls, r, sp, base)
+}
+
+// Mpz_set_ui is like Xmpz_set_ui but does not take a TLS.
+func Mpz_set_ui(r *[1]Xmpz_srcptr, x uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_set_ui(tls, r, x)
+}
+
+// Mpz_setbit is like Xmpz_setbit but does not take a TLS.
+func Mpz_setbit(d *[1]Xmpz_srcptr, i ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_setbit(tls, d, i)
+}
+
+// Mpz_setbit_ctx is like Xmpz_setbit_ctx but does not take a TLS.
+func Mpz_setbit_ctx(ctx context.Context, d *[1]Xmpz_srcptr, i ulong) error {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_setbit_ctx(tls, ctx, d, i)
+}
+
+// Mpz_sgn is like Xmpz_sgn but does not take a TLS.
+func Mpz_sgn(u *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_sgn(tls, u)
+}
+
+// Mpz_si_kronecker is like Xmpz_si_kronecker but does not take a TLS.
+func Mpz_si_kronecker(a long, b *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_si_kronecker(tls, a, b)
+}
+
+// Mpz_size is like Xmpz_size but does not take a TLS.
+func Mpz_size(u *[1]Xmpz_srcptr) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_size(tls, u)
+}
+
+// Mpz_sizeinbase is like Xmpz_sizeinbase but does not take a TLS.
+func Mpz_sizeinbase(u *[1]Xmpz_srcptr, base int32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_sizeinbase(tls, u, base)
+}
+
+// Mpz_sqrt is like Xmpz_sqrt but does not take a TLS.
+func Mpz_sqrt(s *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_sqrt(tls, s, u)
+}
+
+// Mpz_sqrtmod is like Xmpz_sqrtmod but does not take a TLS.
+func Mpz_sqrtmod(r *[1]Xmpz_srcptr, a *[1]Xmpz_srcptr, p *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_sqrtmod(tls, r, a, p)
+}
+
+// Mpz_sqrtmod_factors is like Xmpz_sqrtmod_factors but does not take a TLS.
+func Mpz_sqrtmod_factors(r *[1]Xmpz_srcptr, a *[1]Xmpz_srcptr, f []PrimeFactor) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_sqrtmod_factors(tls, r, a, f)
+}
+
+// Mpz_sqrtmod_pow_ui is like Xmpz_sqrtmod_pow_ui but does not take a TLS.
+func Mpz_sqrtmod_pow_ui(r *[1]Xmpz_srcptr, a *[1]Xmpz_srcptr, p *[1]Xmpz_srcptr, k ulong) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_sqrtmod_pow_ui(tls, r, a, p, k)
+}
+
+// Mpz_sqrtrem is like Xmpz_sqrtrem but does not take a TLS.
+func Mpz_sqrtrem(s *[1]Xmpz_srcptr, r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_sqrtrem(tls, s, r, u)
+}
+
+// Mpz_sub is like Xmpz_sub but does not take a TLS.
+func Mpz_sub(r *[1]Xmpz_srcptr, a *[1]Xmpz_srcptr, b *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_sub(tls, r, a, b)
+}
+
+// Mpz_sub_ui is like Xmpz_sub_ui but does not take a TLS.
+func Mpz_sub_ui(r *[1]Xmpz_srcptr, a *[1]Xmpz_srcptr, b uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_sub_ui(tls, r, a, b)
+}
+
+// Mpz_submul is like Xmpz_submul but does not take a TLS.
+func Mpz_submul(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_submul(tls, r, u, v)
+}
+
+// Mpz_submul_ui is like Xmpz_submul_ui but does not take a TLS.
+func Mpz_submul_ui(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_submul_ui(tls, r, u, v)
+}
+
+// Mpz_swap is like Xmpz_swap but does not take a TLS.
+func Mpz_swap(u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_swap(tls, u, v)
+}
+
+// Mpz_tdiv_q is like Xmpz_tdiv_q but does not take a TLS.
+func Mpz_tdiv_q(q *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_tdiv_q(tls, q, n, d)
+}
+
+// Mpz_tdiv_q_2exp is like Xmpz_tdiv_q_2exp but does not take a TLS.
+func Mpz_tdiv_q_2exp(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, cnt uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_tdiv_q_2exp(tls, r, u, cnt)
+}
+
+// Mpz_tdiv_q_ui is like Xmpz_tdiv_q_ui but does not take a TLS.
+func Mpz_tdiv_q_ui(q *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_tdiv_q_ui(tls, q, n, d)
+}
+
+// Mpz_tdiv_qr is like Xmpz_tdiv_qr but does not take a TLS.
+func Mpz_tdiv_qr(q *[1]Xmpz_srcptr, r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_tdiv_qr(tls, q, r, n, d)
+}
+
+// Mpz_tdiv_qr_ui is like Xmpz_tdiv_qr_ui but does not take a TLS.
+func Mpz_tdiv_qr_ui(q *[1]Xmpz_srcptr, r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_tdiv_qr_ui(tls, q, r, n, d)
+}
+
+// Mpz_tdiv_r is like Xmpz_tdiv_r but does not take a TLS.
+func Mpz_tdiv_r(r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_tdiv_r(tls, r, n, d)
+}
+
+// Mpz_tdiv_r_2exp is like Xmpz_tdiv_r_2exp but does not take a TLS.
+func Mpz_tdiv_r_2exp(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, cnt uint32) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_tdiv_r_2exp(tls, r, u, cnt)
+}
+
+// Mpz_tdiv_r_ui is like Xmpz_tdiv_r_ui but does not take a TLS.
+func Mpz_tdiv_r_ui(r *[1]Xmpz_srcptr, n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_tdiv_r_ui(tls, r, n, d)
+}
+
+// Mpz_tdiv_ui is like Xmpz_tdiv_ui but does not take a TLS.
+func Mpz_tdiv_ui(n *[1]Xmpz_srcptr, d uint32) uint32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_tdiv_ui(tls, n, d)
+}
+
+// Mpz_tstbit is like Xmpz_tstbit but does not take a TLS.
+func Mpz_tstbit(d *[1]Xmpz_srcptr, bit_index uint32) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_tstbit(tls, d, bit_index)
+}
+
+// Mpz_ui_kronecker is like Xmpz_ui_kronecker but does not take a TLS.
+func Mpz_ui_kronecker(a ulong, b *[1]Xmpz_srcptr) int32 {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_ui_kronecker(tls, a, b)
+}
+
+// Mpz_ui_pow_ui is like Xmpz_ui_pow_ui but does not take a TLS.
+func Mpz_ui_pow_ui(r *[1]Xmpz_srcptr, b ulong, e ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_ui_pow_ui(tls, r, b, e)
+}
+
+// Mpz_ui_pow_ui_ctx is like Xmpz_ui_pow_ui_ctx but does not take a TLS.
+func Mpz_ui_pow_ui_ctx(ctx context.Context, r *[1]Xmpz_srcptr, b ulong, e ulong) error {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	return Xmpz_ui_pow_ui_ctx(tls, ctx, r, b, e)
+}
+
+// Mpz_ui_sub is like Xmpz_ui_sub but does not take a TLS.
+func Mpz_ui_sub(r *[1]Xmpz_srcptr, a uint32, b *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_ui_sub(tls, r, a, b)
+}
+
+// Mpz_urandomb is like Xmpz_urandomb but does not take a TLS.
+func Mpz_urandomb(r *[1]Xmpz_srcptr, s *RandState, n ulong) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_urandomb(tls, r, s, n)
+}
+
+// Mpz_urandomm is like Xmpz_urandomm but does not take a TLS.
+func Mpz_urandomm(r *[1]Xmpz_srcptr, s *RandState, n *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_urandomm(tls, r, s, n)
+}
+
+// Mpz_xor is like Xmpz_xor but does not take a TLS.
+func Mpz_xor(r *[1]Xmpz_srcptr, u *[1]Xmpz_srcptr, v *[1]Xmpz_srcptr) {
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	Xmpz_xor(tls, r, u, v)
+}
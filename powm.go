@@ -0,0 +1,318 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"math/bits"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// modReducer multiplies residues modulo a fixed modulus m of n limbs. The
+// residues are n limb slices using a representation specific to the
+// reducer.
+type modReducer interface {
+	// mul sets r to a*b. r may alias a and/or b.
+	mul(tls *crt.TLS, r, a, b []limb)
+	// to sets r to the representation of 0 <= a < m. r may alias a.
+	to(tls *crt.TLS, r, a []limb)
+	// from sets r to the value represented by a, 0 <= r < m. r may alias
+	// a.
+	from(tls *crt.TLS, r, a []limb)
+	// wipe zeroes the scratch space in the secure memory mode, see
+	// wipeLimbs.
+	wipe(tls *crt.TLS)
+}
+
+// mpnMod returns x mod m as len(m) limbs. The most significant limb of m must
+// not be zero. x is destroyed.
+func mpnMod(tls *crt.TLS, x, m []limb) []limb {
+	if len(x) >= len(m) {
+		_mpn_div_qr(tls, nil, &x[0], mpSize(len(x)), &m[0], mpSize(len(m)))
+		x = x[:len(m)]
+	}
+	return padLimbs(x, len(m))
+}
+
+// limbInverse returns 1/x mod B for an odd x.
+func limbInverse(x limb) limb {
+	// x*x == 1 mod 8, every Newton step doubles the number of correct bits.
+	inv := x
+	for i := 0; i < 5; i++ {
+		inv *= 2 - x*inv
+	}
+	return inv
+}
+
+// montgomery represents residues modulo an odd m as a*R mod m, R = B^n.
+type montgomery struct {
+	m    []limb
+	minv limb   // -1/m[0] mod B
+	rr   []limb // R^2 mod m
+	t    []limb // 2n limbs of scratch space
+}
+
+func newMontgomery(tls *crt.TLS, m []limb) *montgomery {
+	n := len(m)
+	x := make([]limb, 2*n+1)
+	x[2*n] = 1
+	return &montgomery{
+		m:    m,
+		minv: -limbInverse(m[0]),
+		rr:   mpnMod(tls, x, m),
+		t:    make([]limb, 2*n),
+	}
+}
+
+func (z *montgomery) mul(tls *crt.TLS, r, a, b []limb) {
+	n := mpSize(len(z.m))
+	if &a[0] == &b[0] {
+		Xmpn_sqr(tls, &z.t[0], &a[0], n)
+	} else {
+		Xmpn_mul_n(tls, &z.t[0], &a[0], &b[0], n)
+	}
+	z.redc(tls, r, z.t)
+}
+
+// redc sets r to t/R mod m, where t < m*R has 2n limbs. t is destroyed.
+func (z *montgomery) redc(tls *crt.TLS, r, t []limb) {
+	n := mpSize(len(z.m))
+	for i := range z.m {
+		// The carry belongs to t[i+n], it is added in the final step.
+		// Storing it in t[i], zeroed by the addmul, does not affect
+		// computing the remaining q's.
+		q := t[i] * z.minv
+		t[i] = Xmpn_addmul_1(tls, &t[i], &z.m[0], n, q)
+	}
+	if Xmpn_add_n(tls, &r[0], &t[n], &t[0], n) != 0 || Xmpn_cmp(tls, &r[0], &z.m[0], n) >= 0 {
+		Xmpn_sub_n(tls, &r[0], &r[0], &z.m[0], n)
+	}
+}
+
+func (z *montgomery) to(tls *crt.TLS, r, a []limb) { z.mul(tls, r, a, z.rr) }
+
+func (z *montgomery) wipe(tls *crt.TLS) { wipeLimbs(tls, z.t) }
+
+func (z *montgomery) from(tls *crt.TLS, r, a []limb) {
+	copy(z.t, a)
+	Xmpn_zero(tls, &z.t[len(a)], mpSize(len(a)))
+	z.redc(tls, r, z.t)
+}
+
+// barrett represents residues modulo m as is and reduces products using the
+// precomputed mu = floor(B^2n/m).
+type barrett struct {
+	m  []limb
+	mu []limb // n+2 limbs
+	p  []limb // 2n+3 limbs of scratch space
+	s  []limb // n+1 limbs of scratch space
+	t  []limb // 2n limbs of scratch space
+	u  []limb // 2n limbs of scratch space
+}
+
+func newBarrett(tls *crt.TLS, m []limb) *barrett {
+	n := len(m)
+	x := make([]limb, 2*n+1)
+	x[2*n] = 1
+	mu := make([]limb, n+2)
+	_mpn_div_qr(tls, &mu[0], &x[0], mpSize(len(x)), &m[0], mpSize(n))
+	return &barrett{
+		m:  m,
+		mu: mu,
+		p:  make([]limb, 2*n+3),
+		s:  make([]limb, n+1),
+		t:  make([]limb, 2*n),
+		u:  make([]limb, 2*n),
+	}
+}
+
+func (z *barrett) mul(tls *crt.TLS, r, a, b []limb) {
+	n := mpSize(len(z.m))
+	if &a[0] == &b[0] {
+		Xmpn_sqr(tls, &z.t[0], &a[0], n)
+	} else {
+		Xmpn_mul_n(tls, &z.t[0], &a[0], &b[0], n)
+	}
+	z.reduce(tls, r, z.t)
+}
+
+// reduce sets r to x mod m, where x < m^2 has 2n limbs. x may be z.t.
+func (z *barrett) reduce(tls *crt.TLS, r, x []limb) {
+	n := mpSize(len(z.m))
+
+	// q = floor(floor(x/B^(n-1)) * mu / B^(n+1)) <= x/m < B^n.
+	Xmpn_mul(tls, &z.p[0], &z.mu[0], n+2, &x[n-1], n+1)
+	q := z.p[n+1 : 2*n+1]
+
+	// s = x - q*m < 3m, computed modulo B^(n+1).
+	Xmpn_mul_n(tls, &z.u[0], &q[0], &z.m[0], n)
+	Xmpn_sub_n(tls, &z.s[0], &x[0], &z.u[0], n+1)
+	for z.s[n] != 0 || Xmpn_cmp(tls, &z.s[0], &z.m[0], n) >= 0 {
+		Xmpn_sub(tls, &z.s[0], &z.s[0], n+1, &z.m[0], n)
+	}
+	copy(r, z.s[:n])
+}
+
+func (z *barrett) to(tls *crt.TLS, r, a []limb)   { copy(r, a) }
+func (z *barrett) from(tls *crt.TLS, r, a []limb) { copy(r, a) }
+func (z *barrett) wipe(tls *crt.TLS)              { wipeLimbs(tls, z.p, z.s, z.t, z.u) }
+
+// newReducer returns a Montgomery reducer for an odd m and a Barrett reducer
+// otherwise. The most significant limb of m must not be zero.
+func newReducer(tls *crt.TLS, m []limb) modReducer {
+	if m[0]&1 != 0 {
+		return newMontgomery(tls, m)
+	}
+
+	return newBarrett(tls, m)
+}
+
+// windowBits returns the sliding window size for an exponent of ebits bits.
+func windowBits(ebits int) int {
+	switch {
+	case ebits <= 7:
+		return 1
+	case ebits <= 25:
+		return 2
+	case ebits <= 81:
+		return 3
+	case ebits <= 241:
+		return 4
+	case ebits <= 673:
+		return 5
+	case ebits <= 1793:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// powWindow sets r to b^e using z and left-to-right sliding window
+// exponentiation. b and r are in the representation of z, e > 0 must be
+// normalized. r may alias b.
+//
+// If done is closed before the computation completes, powWindow returns false
+// and r is not modified.
+//
+// The powers of b computed are wiped in the secure memory mode, the scratch
+// space of z is not.
+func powWindow(tls *crt.TLS, z modReducer, r, b, e []limb, done <-chan struct{}) bool {
+	n := len(b)
+	ebits := (len(e)-1)*limbBits + bits.Len(uint(e[len(e)-1]))
+	bit := func(i int) limb { return e[i/limbBits] >> uint(i%limbBits) & 1 }
+
+	// g[i] = b^(2i+1)
+	g := make([][]limb, 1<<uint(windowBits(ebits)-1))
+	var b2 []limb
+	x := make([]limb, n)
+
+	defer func() { wipeLimbs(tls, append(g, b2, x)...) }()
+
+	g[0] = append([]limb(nil), b...)
+	if len(g) > 1 {
+		b2 = make([]limb, n)
+		z.mul(tls, b2, g[0], g[0])
+		for i := 1; i < len(g); i++ {
+			g[i] = make([]limb, n)
+			z.mul(tls, g[i], g[i-1], b2)
+		}
+	}
+
+	k := windowBits(ebits)
+	started := false
+	for i := ebits - 1; i >= 0; {
+		if isDone(done) {
+			return false
+		}
+
+		if bit(i) == 0 {
+			z.mul(tls, x, x, x)
+			i--
+			continue
+		}
+
+		// Find the longest window e[i:l], l > i-k, ending in a one bit.
+		l := i - k + 1
+		if l < 0 {
+			l = 0
+		}
+		for bit(l) == 0 {
+			l++
+		}
+		w := 0
+		for j := i; j >= l; j-- {
+			w = w<<1 | int(bit(j))
+		}
+		switch {
+		case started:
+			for j := i; j >= l; j-- {
+				z.mul(tls, x, x, x)
+			}
+			z.mul(tls, x, x, g[w>>1])
+		default:
+			copy(x, g[w>>1])
+			started = true
+		}
+		i = l - 1
+	}
+	copy(r, x)
+	return true
+}
+
+// Xmpz_powm sets r to b^e mod m. Negative e requires b to be invertible
+// modulo m. The sign of m is ignored, the result is in [0, |m|).
+//
+// Odd moduli use Montgomery reduction, even moduli Barrett reduction.
+func Xmpz_powm(tls *crt.TLS, r, b, e, m *[1]Xmpz_srcptr) { mpzPowm(tls, r, b, e, m, nil) }
+
+// mpzPowm is Xmpz_powm. If done is closed before the computation completes,
+// mpzPowm returns false and r is not modified.
+func mpzPowm(tls *crt.TLS, r, b, e, m *[1]Xmpz_srcptr, done <-chan struct{}) bool {
+	mn := mpzAbsSize(m)
+	if mn == 0 {
+		_gmp_die(tls, str(198)) // mpz_powm: Zero modulo.
+	}
+
+	if e[0].X_mp_size == 0 {
+		Xmpz_set_ui(tls, r, 1)
+		return true
+	}
+
+	var base [1]Xmpz_srcptr
+	Xmpz_init(tls, &base)
+	switch {
+	case e[0].X_mp_size < 0:
+		if Xmpz_invert(tls, &base, b, m) == 0 {
+			Xmpz_clear(tls, &base)
+			_gmp_die(tls, str(221)) // mpz_powm: Negative exponent and non-invertible base.
+		}
+	default:
+		Xmpz_mod(tls, &base, b, m)
+	}
+
+	mp := append([]limb(nil), mpzLimbs(m)...)
+	x := make([]limb, mn)
+
+	defer func() {
+		Xmpz_clear(tls, &base)
+		wipeLimbs(tls, x)
+	}()
+
+	if mn > 1 || mp[0] != 1 {
+		z := newReducer(tls, mp)
+
+		defer z.wipe(tls)
+
+		copy(x, mpzLimbs(&base))
+		z.to(tls, x, x)
+		if !powWindow(tls, z, x, x, mpzLimbs(e), done) {
+			return false
+		}
+
+		z.from(tls, x, x)
+	}
+	mpzSetLimbs(tls, r, x)
+	return true
+}
@@ -0,0 +1,208 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"github.com/cznic/ccgo/crt"
+)
+
+// PrimeTest selects the algorithm of Xmpz_prime_p.
+type PrimeTest int
+
+const (
+	// PrimeMillerRabin is the test of Xmpz_probab_prime_p, reps rounds of
+	// Miller-Rabin with the bases j^2+j+41, j = 0, 1, ...
+	PrimeMillerRabin PrimeTest = iota
+
+	// PrimeBPSW is the Baillie-PSW test, a strong probable prime test to
+	// base 2 followed by a strong Lucas probable prime test with the
+	// parameters of Selfridge's method A, followed by reps rounds of
+	// PrimeMillerRabin. The result is exact for |n| < 2^64 and no
+	// composite passing the test is known.
+	PrimeBPSW
+)
+
+// Xmpz_prime_p returns 2 if |n| is prime, 1 if |n| is probably prime and 0 if
+// |n| is composite, using the test t. For |n| below 961 and for n with a prime
+// factor below 31 the result is exact for either test.
+func Xmpz_prime_p(tls *crt.TLS, n *[1]Xmpz_srcptr, t PrimeTest, reps int32) int32 {
+	switch t {
+	case PrimeMillerRabin:
+		return Xmpz_probab_prime_p(tls, n, reps)
+	case PrimeBPSW:
+		r := bpsw(tls, n)
+		if r == 1 && reps > 0 {
+			r = Xmpz_probab_prime_p(tls, n, reps)
+		}
+		return r
+	default:
+		panic("mpz_prime_p: invalid test")
+	}
+}
+
+// primeSmall returns the result of the exact cases of Xmpz_probab_prime_p, n
+// even, |n| < 961 or n having a prime factor below 31, and whether n is such a
+// case.
+func primeSmall(tls *crt.TLS, n *[1]Xmpz_srcptr) (int32, bool) {
+	if mpzLow(n)&1 == 0 || Xmpz_cmpabs_ui(tls, n, 31*31) < 0 {
+		return Xmpz_probab_prime_p(tls, n, 0), true
+	}
+
+	if Xmpz_gcd_ui(tls, nil, n, 3*5*7*11*13*17*19*23*29) != 1 {
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// bpsw returns the result of the Baillie-PSW test of |n|.
+func bpsw(tls *crt.TLS, n *[1]Xmpz_srcptr) int32 {
+	if r, ok := primeSmall(tls, n); ok {
+		return r
+	}
+
+	var m, nm1, q, y [1]Xmpz_srcptr
+	Xmpz_init(tls, &m)
+	Xmpz_init(tls, &nm1)
+	Xmpz_init(tls, &q)
+	Xmpz_init(tls, &y)
+
+	defer func() {
+		Xmpz_clear(tls, &m)
+		Xmpz_clear(tls, &nm1)
+		Xmpz_clear(tls, &q)
+		Xmpz_clear(tls, &y)
+	}()
+
+	// |n|-1 = 2^k*q, q odd.
+	Xmpz_abs(tls, &m, n)
+	Xmpz_sub_ui(tls, &nm1, &m, 1)
+	k := Xmpz_scan1(tls, &nm1, 0)
+	Xmpz_tdiv_q_2exp(tls, &q, &nm1, k)
+	Xmpz_set_ui(tls, &y, 2)
+	if _gmp_millerrabin(tls, &m, &nm1, &y, &q, k) == 0 || !strongLucas(tls, &m) {
+		return 0
+	}
+
+	// There are no base 2 strong pseudoprimes below 2^64 which are also
+	// strong Lucas pseudoprimes.
+	if Xmpz_sizeinbase(tls, &m, 2) <= 64 {
+		return 2
+	}
+
+	return 1
+}
+
+// strongLucas reports whether n is a strong Lucas probable prime with the
+// parameters P = 1 and Q = (1-D)/4, where D is the first of 5, -7, 9, -11, ...
+// with the Jacobi symbol (D/n) = -1. n must be odd, greater than 961 and
+// without prime factors below 31.
+func strongLucas(tls *crt.TLS, n *[1]Xmpz_srcptr) bool {
+	d := long(5)
+	for i := 0; ; i++ {
+		j := Xmpz_si_kronecker(tls, d, n)
+		if j == -1 {
+			break
+		}
+
+		if j == 0 && Xmpz_cmpabs_ui(tls, n, ulong(abs(d))) != 0 {
+			return false
+		}
+
+		// There is no such D for a square n, check after a few attempts.
+		if i == 10 && Xmpz_perfect_square_p(tls, n) != 0 {
+			return false
+		}
+
+		if d > 0 {
+			d = -d - 2
+		} else {
+			d = -d + 2
+		}
+	}
+	q := (1 - d) / 4
+
+	var u, v, qk, t, e [1]Xmpz_srcptr
+	Xmpz_init(tls, &u)
+	Xmpz_init(tls, &v)
+	Xmpz_init(tls, &qk)
+	Xmpz_init(tls, &t)
+	Xmpz_init(tls, &e)
+
+	defer func() {
+		Xmpz_clear(tls, &u)
+		Xmpz_clear(tls, &v)
+		Xmpz_clear(tls, &qk)
+		Xmpz_clear(tls, &t)
+		Xmpz_clear(tls, &e)
+	}()
+
+	// half sets x to x/2 (mod n), x in [0, n).
+	half := func(x *[1]Xmpz_srcptr) {
+		if mpzLow(x)&1 != 0 {
+			Xmpz_add(tls, x, x, n)
+		}
+		Xmpz_tdiv_q_2exp(tls, x, x, 1)
+	}
+
+	// n+1 = 2^s*e, e odd.
+	Xmpz_add_ui(tls, &e, n, 1)
+	s := Xmpz_scan1(tls, &e, 0)
+	Xmpz_tdiv_q_2exp(tls, &e, &e, s)
+
+	// Compute U_e, V_e and Q^e (mod n) from the most significant bit of e
+	// down, starting with U_1 = 1, V_1 = P = 1.
+	Xmpz_set_ui(tls, &u, 1)
+	Xmpz_set_ui(tls, &v, 1)
+	Xmpz_set_si(tls, &qk, q)
+	Xmpz_mod(tls, &qk, &qk, n)
+	for i := Xmpz_sizeinbase(tls, &e, 2) - 1; i > 0; i-- {
+		// U_2j = U_j*V_j, V_2j = V_j^2-2Q^j.
+		Xmpz_mul(tls, &u, &u, &v)
+		Xmpz_mod(tls, &u, &u, n)
+		Xmpz_mul(tls, &v, &v, &v)
+		Xmpz_submul_ui(tls, &v, &qk, 2)
+		Xmpz_mod(tls, &v, &v, n)
+		Xmpz_mul(tls, &qk, &qk, &qk)
+		Xmpz_mod(tls, &qk, &qk, n)
+		if Xmpz_tstbit(tls, &e, ulong(i-1)) != 0 {
+			// U_2j+1 = (P*U_2j+V_2j)/2, V_2j+1 = (D*U_2j+P*V_2j)/2.
+			Xmpz_mul_si(tls, &t, &u, d)
+			Xmpz_add(tls, &u, &u, &v)
+			Xmpz_mod(tls, &u, &u, n)
+			half(&u)
+			Xmpz_add(tls, &v, &v, &t)
+			Xmpz_mod(tls, &v, &v, n)
+			half(&v)
+			Xmpz_mul_si(tls, &qk, &qk, q)
+			Xmpz_mod(tls, &qk, &qk, n)
+		}
+	}
+	if u[0].X_mp_size == 0 || v[0].X_mp_size == 0 {
+		return true
+	}
+
+	// V_2j = V_j^2-2Q^j for e*2, ..., e*2^(s-1).
+	for ; s > 1; s-- {
+		Xmpz_mul(tls, &v, &v, &v)
+		Xmpz_submul_ui(tls, &v, &qk, 2)
+		Xmpz_mod(tls, &v, &v, n)
+		if v[0].X_mp_size == 0 {
+			return true
+		}
+
+		Xmpz_mul(tls, &qk, &qk, &qk)
+		Xmpz_mod(tls, &qk, &qk, n)
+	}
+	return false
+}
+
+func abs(n long) long {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
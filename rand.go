@@ -0,0 +1,249 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// maxUrandommIter is the number of attempts to get a random value below the
+// bound by rejection sampling, as in GMP.
+const maxUrandommIter = 80
+
+// RandState is the state of a random number generator, the counterpart of
+// gmp_randstate_t of GMP. It must be initialized by one of the
+// Xgmp_randinit_* functions before use and a RandState must not be used
+// concurrently.
+type RandState struct {
+	gen randGen
+}
+
+// randGen is a source of random bits.
+type randGen interface {
+	// get sets the first nbits bits of r to random bits, like _gmp_rand
+	// of GMP. The other bits of the limbs holding them are zero. Limbs
+	// above them may be written as well.
+	get(r []limb, nbits uint64)
+
+	// seed seeds the source.
+	seed(tls *crt.TLS, seed *[1]Xmpz_srcptr)
+}
+
+// readerGen is the randGen of Xgmp_randinit_reader.
+type readerGen struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (g *readerGen) get(r []limb, nbits uint64) {
+	const limbBytes = limbBits / 8
+	n := int((nbits + 7) / 8)
+	if cap(g.buf) < n {
+		g.buf = make([]byte, n)
+	}
+	b := g.buf[:n]
+	if _, err := io.ReadFull(g.r, b); err != nil {
+		panic(fmt.Errorf("gmp_rand: %v", err))
+	}
+
+	for i := range r[:(nbits+limbBits-1)/limbBits] {
+		r[i] = 0
+	}
+	for i, v := range b {
+		r[i/limbBytes] |= limb(v) << uint(i%limbBytes*8)
+	}
+	if k := nbits % limbBits; k != 0 {
+		r[nbits/limbBits] &= 1<<k - 1
+	}
+}
+
+func (g *readerGen) seed(tls *crt.TLS, seed *[1]Xmpz_srcptr) {
+	if s, ok := g.r.(interface{ Seed(int64) }); ok {
+		var t [1]Xmpz_srcptr
+		Xmpz_init(tls, &t)
+		Xmpz_tdiv_r_2exp(tls, &t, seed, 63)
+		s.Seed(Xmpz_get_big(tls, nil, &t).Int64())
+		Xmpz_clear(tls, &t)
+	}
+}
+
+// Xgmp_randinit_default initializes s to the default generator, which is, as
+// in GMP, the Mersenne Twister of Xgmp_randinit_mt.
+func Xgmp_randinit_default(tls *crt.TLS, s *RandState) { Xgmp_randinit_mt(tls, s) }
+
+// Xgmp_randinit_reader initializes s to a generator reading random bytes from
+// r, like crypto/rand.Reader or a *math/rand.Rand. Seeding s has an effect
+// only if r has a method Seed(int64), which is passed the least significant
+// 63 bits of the seed. The functions using s panic if reading from r fails.
+func Xgmp_randinit_reader(tls *crt.TLS, s *RandState, r io.Reader) {
+	s.gen = &readerGen{r: r}
+}
+
+// Xgmp_randclear releases the resources of s.
+func Xgmp_randclear(tls *crt.TLS, s *RandState) { s.gen = nil }
+
+// Xgmp_randseed seeds s with seed.
+func Xgmp_randseed(tls *crt.TLS, s *RandState, seed *[1]Xmpz_srcptr) { s.gen.seed(tls, seed) }
+
+// Xgmp_randseed_ui seeds s with seed.
+func Xgmp_randseed_ui(tls *crt.TLS, s *RandState, seed ulong) {
+	var t [1]Xmpz_srcptr
+	Xmpz_init_set_ui(tls, &t, seed)
+	s.gen.seed(tls, &t)
+	Xmpz_clear(tls, &t)
+}
+
+// Xgmp_urandomb_ui returns a uniformly distributed random value in [0, 2^n),
+// n is truncated to the size of an ulong.
+func Xgmp_urandomb_ui(tls *crt.TLS, s *RandState, n ulong) ulong {
+	nbits := uint64(n)
+	if nbits > limbBits {
+		nbits = limbBits
+	}
+	var a [1]limb
+	s.gen.get(a[:], nbits)
+	return ulong(a[0])
+}
+
+// Xgmp_urandomm_ui returns a uniformly distributed random value in [0, n).
+// n must not be zero.
+func Xgmp_urandomm_ui(tls *crt.TLS, s *RandState, n ulong) ulong {
+	if n == 0 {
+		panic("gmp_urandomm_ui: division by zero")
+	}
+
+	nbits := uint64(bits.Len64(uint64(n)))
+	if n&(n-1) == 0 {
+		nbits--
+	}
+	var a [1]limb
+	for i := 0; i < maxUrandommIter; i++ {
+		s.gen.get(a[:], nbits)
+		if ulong(a[0]) < n {
+			return ulong(a[0])
+		}
+	}
+	return ulong(a[0]) - n
+}
+
+// Xmpz_urandomb sets r to a uniformly distributed random value in [0, 2^n).
+func Xmpz_urandomb(tls *crt.TLS, r *[1]Xmpz_srcptr, s *RandState, n ulong) {
+	size := mpSize((uint64(n) + limbBits - 1) / limbBits)
+	if size == 0 {
+		r[0].X_mp_size = 0
+		return
+	}
+
+	rp := limbs(Xmpz_limbs_write(tls, r, size), size)
+	s.gen.get(rp, uint64(n))
+	Xmpz_limbs_finish(tls, r, size)
+}
+
+// Xmpz_urandomm sets r to a uniformly distributed random value in [0, |n|).
+// n must not be zero.
+func Xmpz_urandomm(tls *crt.TLS, r *[1]Xmpz_srcptr, s *RandState, n *[1]Xmpz_srcptr) {
+	if n[0].X_mp_size == 0 {
+		panic("mpz_urandomm: division by zero")
+	}
+
+	// Copy n if it is r or shares its limbs.
+	var t [1]Xmpz_srcptr
+	Xmpz_init_set(tls, &t, n)
+
+	defer Xmpz_clear(tls, &t)
+
+	np := mpzLimbs(&t)
+	size := mpSize(len(np))
+	nbits := uint64(mpzBitLen(&t))
+	if MpnPopcount(np) == 1 {
+		nbits--
+	}
+	rp := limbs(Xmpz_limbs_write(tls, r, size), size)
+	rp[size-1] = 0
+	for i := 0; ; i++ {
+		s.gen.get(rp, nbits)
+		if MpnCmp(rp, np) < 0 {
+			break
+		}
+
+		if i == maxUrandommIter-1 {
+			MpnSubN(rp, rp, np)
+			break
+		}
+	}
+	Xmpz_limbs_finish(tls, r, size)
+}
+
+// Xmpz_rrandomb sets r to a random value of exactly n bits with long runs of
+// zeros and ones in its binary representation. Such values are useful for
+// testing.
+func Xmpz_rrandomb(tls *crt.TLS, r *[1]Xmpz_srcptr, s *RandState, n ulong) {
+	size := mpSize((uint64(n) + limbBits - 1) / limbBits)
+	if size == 0 {
+		r[0].X_mp_size = 0
+		return
+	}
+
+	rp := limbs(Xmpz_limbs_write(tls, r, size), size)
+	rrandomb(rp, s, uint64(n))
+	Xmpz_limbs_finish(tls, r, size)
+}
+
+// rrandomb sets r to a random value of nbits bits, nbits > 0, alternating
+// runs of ones and zeros with random lengths. It is the algorithm of GMP.
+func rrandomb(r []limb, s *RandState, nbits uint64) {
+	const bitsPerRandCall = 32
+
+	// Set all the nbits bits.
+	for i := range r {
+		r[i] = ^limb(0)
+	}
+	if k := nbits % limbBits; k != 0 {
+		r[len(r)-1] >>= limbBits - k
+	}
+
+	var a [1]limb
+	s.gen.get(a[:], bitsPerRandCall)
+	capChunk := nbits / (uint64(a[0])%4 + 1)
+	if capChunk == 0 {
+		capChunk = 1
+	}
+	next := func(bi uint64) uint64 {
+		s.gen.get(a[:], bitsPerRandCall)
+		if c := 1 + uint64(a[0])%capChunk; bi > c {
+			return bi - c
+		}
+
+		return 0
+	}
+	for bi := nbits; ; {
+		// All the bits below bi are ones. Clearing bit hi and adding
+		// 1<<lo, lo < hi, leaves a run of zeros from bit lo to bit hi-1.
+		hi := next(bi)
+		if hi == 0 {
+			break
+		}
+
+		r[hi/limbBits] ^= 1 << (hi % limbBits)
+		lo := next(hi)
+		for i, c := lo/limbBits, limb(1)<<(lo%limbBits); c != 0; i++ {
+			v := r[i] + c
+			if r[i] = v; v >= c {
+				c = 0
+			} else {
+				c = 1
+			}
+		}
+		if lo == 0 {
+			break
+		}
+
+		bi = lo
+	}
+}
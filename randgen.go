@@ -0,0 +1,284 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"sync"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// The generators below are those of GMP 6. For the same seed they produce the
+// same bits as GMP does.
+
+const (
+	mtN      = 624  // Size of the Mersenne Twister state in words.
+	mtM      = 397  // Mersenne Twister shift.
+	mtWarmUp = 2000 // Number of outputs discarded after seeding.
+)
+
+// mtGen is the Mersenne Twister MT19937 generator of gmp_randinit_mt.
+type mtGen struct {
+	mt  [mtN]uint32
+	mti int
+}
+
+var mtDefault struct {
+	sync.Once
+	mtGen
+}
+
+// Xgmp_randinit_mt initializes s to the Mersenne Twister generator of GMP,
+// seeded by the default seed of GMP, 5489.
+func Xgmp_randinit_mt(tls *crt.TLS, s *RandState) {
+	mtDefault.Do(func() {
+		var seed [1]Xmpz_srcptr
+		Xmpz_init_set_ui(tls, &seed, 5489)
+		mtDefault.seed(tls, &seed)
+		Xmpz_clear(tls, &seed)
+	})
+	g := mtDefault.mtGen
+	s.gen = &g
+}
+
+// recalc computes the next mtN words of the state.
+func (g *mtGen) recalc() {
+	for i := range g.mt {
+		y := g.mt[i]&0x80000000 | g.mt[(i+1)%mtN]&0x7fffffff
+		x := g.mt[(i+mtM)%mtN] ^ y>>1
+		if y&1 != 0 {
+			x ^= 0x9908b0df
+		}
+		g.mt[i] = x
+	}
+}
+
+// next returns the next 32 bits.
+func (g *mtGen) next() uint32 {
+	if g.mti >= mtN {
+		g.recalc()
+		g.mti = 0
+	}
+	y := g.mt[g.mti]
+	g.mti++
+	y ^= y >> 11
+	y ^= y << 7 & 0x9d2c5680
+	y ^= y << 15 & 0xefc60000
+	return y ^ y>>18
+}
+
+// bits returns the next n <= limbBits bits, taking one or two words.
+func (g *mtGen) bits(n uint64) limb {
+	v := uint64(g.next())
+	if n > 32 {
+		v |= uint64(g.next()) << 32
+	}
+	if n < 64 {
+		v &= 1<<n - 1
+	}
+	return limb(v)
+}
+
+func (g *mtGen) get(r []limb, nbits uint64) {
+	n := nbits / limbBits
+	for i := range r[:n] {
+		r[i] = g.bits(limbBits)
+	}
+	if k := nbits % limbBits; k != 0 {
+		r[n] = g.bits(k)
+	}
+}
+
+// seed sets the state from seed mod 2^19937-20027 mangled by mtMangle.
+func (g *mtGen) seed(tls *crt.TLS, seed *[1]Xmpz_srcptr) {
+	var mod, s [1]Xmpz_srcptr
+	Xmpz_init(tls, &mod)
+	Xmpz_init(tls, &s)
+	Xmpz_setbit(tls, &mod, 19937)
+	Xmpz_sub_ui(tls, &mod, &mod, 20027)
+	Xmpz_mod(tls, &s, seed, &mod)
+	Xmpz_add_ui(tls, &s, &s, 2)
+	mtMangle(tls, &s)
+
+	// Bit 19936 goes to bit 31 of the first word, the other bits, least
+	// significant first, to the remaining words.
+	g.mt[0] = 0
+	if Xmpz_tstbit(tls, &s, 19936) != 0 {
+		g.mt[0] = 0x80000000
+	}
+	Xmpz_clrbit(tls, &s, 19936)
+	for i := range g.mt[1:] {
+		g.mt[i+1] = 0
+	}
+	i := 1
+	for _, v := range mpzLimbs(&s) {
+		for j := 0; j < limbBits && i < mtN; j += 32 {
+			g.mt[i] = uint32(uint64(v) >> uint(j))
+			i++
+		}
+	}
+	Xmpz_clear(tls, &mod)
+	Xmpz_clear(tls, &s)
+
+	for i := 0; i < mtWarmUp/mtN; i++ {
+		g.recalc()
+	}
+	g.mti = mtWarmUp % mtN
+}
+
+// mtMangle sets r to r^1074888996, reduced modulo 2^19937-20023 to fewer than
+// 19938 bits, but not necessarily to less than the modulus.
+func mtMangle(tls *crt.TLS, r *[1]Xmpz_srcptr) {
+	var t, b [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+	Xmpz_init_set(tls, &b, r)
+	reduce := func() {
+		for {
+			Xmpz_tdiv_q_2exp(tls, &t, r, 19937)
+			if t[0].X_mp_size == 0 {
+				break
+			}
+
+			Xmpz_tdiv_r_2exp(tls, r, r, 19937)
+			Xmpz_addmul_ui(tls, r, &t, 20023)
+		}
+	}
+	const e = 0x40118124
+	for bit := 0x20000000; bit != 0; bit >>= 1 {
+		Xmpz_mul(tls, r, r, r)
+		reduce()
+		if e&bit != 0 {
+			Xmpz_mul(tls, r, r, &b)
+			reduce()
+		}
+	}
+	Xmpz_clear(tls, &t)
+	Xmpz_clear(tls, &b)
+}
+
+// lcGen is the linear congruential generator of gmp_randinit_lc_2exp,
+// X = (a*X+c) mod 2^m2exp. Every step produces the upper half of X.
+type lcGen struct {
+	a     []limb // a mod 2^m2exp, at least one limb.
+	c     []limb // c, no limbs if zero.
+	x     []limb // X, (m2exp+limbBits-1)/limbBits limbs.
+	m2exp uint64
+}
+
+// Xgmp_randinit_lc_2exp initializes s to the linear congruential generator of
+// GMP, X = (a*X+c) mod 2^m2exp. m2exp must be at least 2. The initial seed is
+// one. Every step produces m2exp/2 random bits, the upper half of X, so the
+// quality of the generator depends on a good choice of the parameters.
+func Xgmp_randinit_lc_2exp(tls *crt.TLS, s *RandState, a *[1]Xmpz_srcptr, c, m2exp ulong) {
+	if m2exp < 2 {
+		panic("gmp_randinit_lc_2exp: m2exp must be at least 2")
+	}
+
+	var t [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+	Xmpz_fdiv_r_2exp(tls, &t, a, m2exp)
+	g := &lcGen{
+		a:     append([]limb{}, mpzLimbs(&t)...),
+		x:     make([]limb, (uint64(m2exp)+limbBits-1)/limbBits),
+		m2exp: uint64(m2exp),
+	}
+	Xmpz_clear(tls, &t)
+	if len(g.a) == 0 {
+		g.a = []limb{0}
+	}
+	if c != 0 {
+		g.c = []limb{limb(c)}
+	}
+	g.x[0] = 1
+	s.gen = g
+}
+
+func (g *lcGen) seed(tls *crt.TLS, seed *[1]Xmpz_srcptr) {
+	var t [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+	Xmpz_fdiv_r_2exp(tls, &t, seed, ulong(g.m2exp))
+	for i := range g.x {
+		g.x[i] = 0
+	}
+	copy(g.x, mpzLimbs(&t))
+	Xmpz_clear(tls, &t)
+}
+
+// step advances X and writes its upper (m2exp+1)/2 bits to r. Like GMP, it
+// writes whole limbs, so some bits above the result may be written as well.
+func (g *lcGen) step(r []limb) {
+	tn := len(g.x)
+	t := make([]limb, max(len(g.x)+len(g.a), tn)+1)
+	MpnMul(t[:len(g.x)+len(g.a)], g.x, g.a)
+	if len(g.c) != 0 {
+		MpnAdd(t[:tn], t[:tn], g.c)
+	}
+	t[g.m2exp/limbBits] &= 1<<(g.m2exp%limbBits) - 1
+	copy(g.x, t)
+
+	// Discard the lower m2exp/2 bits.
+	bits := g.m2exp / 2
+	xn := int(bits / limbBits)
+	if tn -= xn; tn <= 0 {
+		return
+	}
+
+	if cnt := uint(bits % limbBits); cnt != 0 {
+		u := make([]limb, tn)
+		MpnRshift(u, t[xn:xn+tn], cnt)
+		copy(t, u)
+		copy(r, t[:xn+1])
+		return
+	}
+
+	copy(r, t[xn:xn+tn])
+}
+
+func (g *lcGen) get(rp []limb, nbits uint64) {
+	// The writes of step may extend beyond rp, use a larger buffer.
+	chunk := g.m2exp / 2
+	tn := int((chunk + limbBits - 1) / limbBits)
+	w := make([]limb, len(rp)+tn+1)
+	copy(w, rp)
+	t := make([]limb, tn)
+	pos := uint64(0)
+	for ; pos+chunk <= nbits; pos += chunk {
+		r := w[pos/limbBits:]
+		s := uint(pos % limbBits)
+		if s == 0 {
+			g.step(r)
+			continue
+		}
+
+		// Shift the chunk into place.
+		g.step(t)
+		lo := r[0]
+		hi := MpnLshift(r[:tn], t, s)
+		r[0] |= lo
+		if chunk%limbBits+uint64(s) > limbBits {
+			r[tn] = hi
+		}
+	}
+	if pos != nbits {
+		r := w[pos/limbBits:]
+		s := uint(pos % limbBits)
+		n := int((nbits - pos + limbBits - 1) / limbBits)
+		g.step(t)
+		if s != 0 {
+			lo := r[0]
+			hi := MpnLshift(r[:n], t[:n], s)
+			r[0] |= lo
+			if pos+uint64(n)*limbBits-uint64(s) < nbits {
+				r[n] = hi
+			}
+		} else {
+			copy(r[:n], t[:n])
+		}
+		if k := nbits % limbBits; k != 0 {
+			w[nbits/limbBits] &= 1<<k - 1
+		}
+	}
+	copy(rp, w)
+}
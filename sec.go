@@ -0,0 +1,383 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"github.com/cznic/ccgo/crt"
+)
+
+// This file implements the side channel silent mpn functions of GMP. Their
+// control flow and memory access patterns depend only on the sizes of the
+// operands, never on their values. Conditions are turned into all zeros or
+// all ones masks instead of being branched on.
+//
+// The functions use the Go versions of the primitives in mpn.go, never the
+// assembly ones, so that the property can be verified on any platform by the
+// tests.
+//
+// Scratch space requirements are reported by the respective *_itch
+// functions, in limbs.
+
+// secMask returns all ones if cnd != 0 and zero otherwise.
+func secMask(cnd limb) limb { return -((cnd | -cnd) >> (limbBits - 1)) }
+
+// secSelect sets r to a if mask is all ones and leaves it unchanged if mask is
+// zero.
+func secSelect(mask limb, r, a []limb) {
+	a = a[:len(r)]
+	for i := range r {
+		r[i] ^= (r[i] ^ a[i]) & mask
+	}
+}
+
+// cndAddN sets r to a + (b & mask) and returns the carry.
+func cndAddN(mask limb, r, a, b []limb) (c limb) {
+	b = b[:len(r)]
+	a = a[:len(r)]
+	for i := range r {
+		r[i], c = addWWW(a[i], b[i]&mask, c)
+	}
+	return c
+}
+
+// cndSubN sets r to a - (b & mask) and returns the borrow.
+func cndSubN(mask limb, r, a, b []limb) (c limb) {
+	b = b[:len(r)]
+	a = a[:len(r)]
+	for i := range r {
+		r[i], c = subWWW(a[i], b[i]&mask, c)
+	}
+	return c
+}
+
+// cndNeg sets a to -a mod B^len(a) if mask is all ones.
+func cndNeg(mask limb, a []limb) {
+	c := mask & 1
+	for i := range a {
+		a[i], c = addWWW(a[i]^mask, 0, c)
+	}
+}
+
+// cndSwap exchanges a and b if mask is all ones.
+func cndSwap(mask limb, a, b []limb) {
+	b = b[:len(a)]
+	for i := range a {
+		t := (a[i] ^ b[i]) & mask
+		a[i] ^= t
+		b[i] ^= t
+	}
+}
+
+// secAdd1 sets r to a + b and returns the carry.
+func secAdd1(r, a []limb, b limb) limb {
+	a = a[:len(r)]
+	for i := range r {
+		r[i], b = addWWW(a[i], b, 0)
+	}
+	return b
+}
+
+// secSub1 sets r to a - b and returns the borrow.
+func secSub1(r, a []limb, b limb) limb {
+	a = a[:len(r)]
+	for i := range r {
+		r[i], b = subWWW(a[i], b, 0)
+	}
+	return b
+}
+
+// secMul sets r, len(a)+len(b) limbs, to a*b.
+func secMul(r, a, b []limb) {
+	an := len(a)
+	r[an] = mul1(r[:an], a, b[0])
+	for j := 1; j < len(b); j++ {
+		r[j+an] = addmul1(r[j:j+an], a, b[j])
+	}
+}
+
+// secTabselect sets r to the which'th entry of tab, which consists of nents
+// entries of len(r) limbs.
+func secTabselect(r, tab []limb, nents int, which limb) {
+	n := len(r)
+	for i := range r {
+		r[i] = 0
+	}
+	for k := 0; k < nents; k++ {
+		mask := ^secMask(limb(k) ^ which)
+		e := tab[k*n : k*n+n]
+		for i := range r {
+			r[i] |= e[i] & mask
+		}
+	}
+}
+
+// secDiv divides n by d, len(n) >= len(d) and the most significant limb of d
+// is not zero. The remainder replaces the low len(d) limbs of n. If q is not
+// nil, the quotient is added to its len(n)-len(d)+1 limbs, which should be
+// zero. t must have room for 2*len(d) limbs.
+//
+// The quotient is computed one bit at a time.
+func secDiv(q, n, d, t []limb) {
+	dn := len(d)
+	nn := len(n)
+	r := t[:dn]
+	s := t[dn : 2*dn]
+
+	// The dn-1 most significant limbs of n are less than d.
+	copy(r, n[nn-dn+1:])
+	r[dn-1] = 0
+	for i := (nn-dn+1)*limbBits - 1; i >= 0; i-- {
+		h := lshift(r, r, 1)
+		r[0] |= n[i/limbBits] >> uint(i%limbBits) & 1
+
+		// 2*d > h:r and h:r >= d iff h != 0 or r-d does not borrow.
+		bit := h | (subN(s, r, d) ^ 1)
+		secSelect(-bit, r, s)
+		if q != nil {
+			q[i/limbBits] |= bit << uint(i%limbBits)
+		}
+	}
+	copy(n, r)
+}
+
+// secRedc sets r to t/B^n mod m, where n = len(m), t < m*B^n has 2n limbs,
+// minv is -1/m[0] mod B and s has room for n limbs. t is destroyed.
+func secRedc(r, t, s, m []limb, minv limb) {
+	n := len(m)
+	for i := range m {
+		q := t[i] * minv
+		t[i] = addmul1(t[i:i+n], m, q)
+	}
+	c := addN(r, t[n:2*n], t[:n])
+	b := subN(s[:n], r, m)
+	secSelect(secMask(c|(b^1)), r, s)
+}
+
+// Xmpn_cnd_add_n sets {rp, n} to {s1p, n} + {s2p, n} if cnd is not zero and to
+// {s1p, n} otherwise. It returns the carry.
+func Xmpn_cnd_add_n(tls *crt.TLS, cnd limb, rp, s1p, s2p *limb, n mpSize) limb {
+	return cndAddN(secMask(cnd), limbs(rp, n), limbs(s1p, n), limbs(s2p, n))
+}
+
+// Xmpn_cnd_sub_n sets {rp, n} to {s1p, n} - {s2p, n} if cnd is not zero and to
+// {s1p, n} otherwise. It returns the borrow.
+func Xmpn_cnd_sub_n(tls *crt.TLS, cnd limb, rp, s1p, s2p *limb, n mpSize) limb {
+	return cndSubN(secMask(cnd), limbs(rp, n), limbs(s1p, n), limbs(s2p, n))
+}
+
+// Xmpn_cnd_swap exchanges {ap, n} and {bp, n} if cnd is not zero.
+func Xmpn_cnd_swap(tls *crt.TLS, cnd limb, ap, bp *limb, n mpSize) {
+	cndSwap(secMask(cnd), limbs(ap, n), limbs(bp, n))
+}
+
+// Xmpn_sec_add_1 sets {rp, n} to {ap, n} + b and returns the carry. Requires
+// n > 0.
+func Xmpn_sec_add_1(tls *crt.TLS, rp, ap *limb, n mpSize, b limb, tp *limb) limb {
+	return secAdd1(limbs(rp, n), limbs(ap, n), b)
+}
+
+// Xmpn_sec_add_1_itch returns the scratch space needed by Xmpn_sec_add_1.
+func Xmpn_sec_add_1_itch(tls *crt.TLS, n mpSize) mpSize { return 0 }
+
+// Xmpn_sec_sub_1 sets {rp, n} to {ap, n} - b and returns the borrow. Requires
+// n > 0.
+func Xmpn_sec_sub_1(tls *crt.TLS, rp, ap *limb, n mpSize, b limb, tp *limb) limb {
+	return secSub1(limbs(rp, n), limbs(ap, n), b)
+}
+
+// Xmpn_sec_sub_1_itch returns the scratch space needed by Xmpn_sec_sub_1.
+func Xmpn_sec_sub_1_itch(tls *crt.TLS, n mpSize) mpSize { return 0 }
+
+// Xmpn_sec_mul sets {rp, an+bn} to {ap, an} * {bp, bn}. Requires an >= bn > 0.
+// The destination must not overlap the sources.
+func Xmpn_sec_mul(tls *crt.TLS, rp, ap *limb, an mpSize, bp *limb, bn mpSize, tp *limb) {
+	secMul(limbs(rp, an+bn), limbs(ap, an), limbs(bp, bn))
+}
+
+// Xmpn_sec_mul_itch returns the scratch space needed by Xmpn_sec_mul.
+func Xmpn_sec_mul_itch(tls *crt.TLS, an, bn mpSize) mpSize { return 0 }
+
+// Xmpn_sec_sqr sets {rp, 2an} to the square of {ap, an}. Requires an > 0. The
+// destination must not overlap the source.
+func Xmpn_sec_sqr(tls *crt.TLS, rp, ap *limb, an mpSize, tp *limb) {
+	a := limbs(ap, an)
+	secMul(limbs(rp, 2*an), a, a)
+}
+
+// Xmpn_sec_sqr_itch returns the scratch space needed by Xmpn_sec_sqr.
+func Xmpn_sec_sqr_itch(tls *crt.TLS, an mpSize) mpSize { return 0 }
+
+// Xmpn_sec_tabselect sets {rp, n} to the which'th entry of the table of
+// nents entries of n limbs at tab.
+func Xmpn_sec_tabselect(tls *crt.TLS, rp, tab *limb, n, nents, which mpSize) {
+	secTabselect(limbs(rp, n), limbs(tab, n*nents), int(nents), limb(which))
+}
+
+// Xmpn_sec_div_qr divides {np, nn} by {dp, dn}, where nn >= dn > 0 and
+// dp[dn-1] != 0. It sets {qp, nn-dn} to the low limbs of the quotient,
+// returns its most significant limb and leaves the remainder in {np, dn}.
+func Xmpn_sec_div_qr(tls *crt.TLS, qp, np *limb, nn mpSize, dp *limb, dn mpSize, tp *limb) limb {
+	t := limbs(tp, Xmpn_sec_div_qr_itch(tls, nn, dn))
+	q := t[2*dn:]
+	for i := range q {
+		q[i] = 0
+	}
+	secDiv(q, limbs(np, nn), limbs(dp, dn), t)
+	copy(limbs(qp, nn-dn), q)
+	return q[nn-dn]
+}
+
+// Xmpn_sec_div_qr_itch returns the scratch space needed by Xmpn_sec_div_qr.
+func Xmpn_sec_div_qr_itch(tls *crt.TLS, nn, dn mpSize) mpSize { return nn + dn + 1 }
+
+// Xmpn_sec_div_r leaves the remainder of {np, nn} divided by {dp, dn} in {np,
+// dn}, where nn >= dn > 0 and dp[dn-1] != 0.
+func Xmpn_sec_div_r(tls *crt.TLS, np *limb, nn mpSize, dp *limb, dn mpSize, tp *limb) {
+	secDiv(nil, limbs(np, nn), limbs(dp, dn), limbs(tp, Xmpn_sec_div_r_itch(tls, nn, dn)))
+}
+
+// Xmpn_sec_div_r_itch returns the scratch space needed by Xmpn_sec_div_r.
+func Xmpn_sec_div_r_itch(tls *crt.TLS, nn, dn mpSize) mpSize { return 2 * dn }
+
+// secPowmWindow returns the window size Xmpn_sec_powm uses for an enb bit
+// exponent.
+func secPowmWindow(enb ulong) uint {
+	switch {
+	case enb <= 16:
+		return 2
+	case enb <= 64:
+		return 3
+	case enb <= 256:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// Xmpn_sec_powm sets {rp, n} to {bp, bn} raised to the power of the enb low
+// bits of {ep, ceil(enb/limbBits)}, modulo {mp, n}. The modulus must be odd
+// and mp[n-1] must not be zero. Requires bn > 0. The destination must not
+// overlap the sources.
+func Xmpn_sec_powm(tls *crt.TLS, rp, bp *limb, bn mpSize, ep *limb, enb ulong, mp *limb, n mpSize, tp *limb) {
+	k := secPowmWindow(enb)
+	nents := 1 << k
+	r := limbs(rp, n)
+	b := limbs(bp, bn)
+	e := limbs(ep, mpSize((enb+limbBits-1)/limbBits))
+	m := limbs(mp, n)
+	scratch := limbs(tp, Xmpn_sec_powm_itch(tls, bn, enb, n))
+	tab, scratch := scratch[:mpSize(nents)*n], scratch[mpSize(nents)*n:]
+	t, scratch := scratch[:2*n], scratch[2*n:]
+	s, x := scratch[:n], scratch[n:n+n+bn]
+	minv := -limbInverse(m[0])
+
+	// tab[0] = R mod m, tab[1] = b*R mod m.
+	for i := range x {
+		x[i] = 0
+	}
+	x[n] = 1
+	secDiv(nil, x[:n+1], m, t)
+	copy(tab, x[:n])
+	for i := range x[:n] {
+		x[i] = 0
+	}
+	copy(x[n:], b)
+	secDiv(nil, x, m, t)
+	copy(tab[n:], x[:n])
+	for i := 2; i < nents; i++ {
+		secMul(t, tab[(i-1)*int(n):i*int(n)], tab[n:2*n])
+		secRedc(tab[i*int(n):(i+1)*int(n)], t, s, m, minv)
+	}
+
+	// Fixed window exponentiation, every window costs k squarings and one
+	// multiplication.
+	copy(r, tab[:n])
+	for pos := (enb + ulong(k) - 1) / ulong(k) * ulong(k); pos > 0; {
+		w := ulong(k)
+		if pos > enb {
+			// The most significant window may be shorter.
+			w = enb - (pos - ulong(k))
+		}
+		pos -= ulong(k)
+		for i := ulong(0); i < w; i++ {
+			secMul(t, r, r)
+			secRedc(r, t, s, m, minv)
+		}
+		secTabselect(s, tab, nents, secGetBits(e, pos, w))
+		secMul(t, r, s)
+		secRedc(r, t, s, m, minv)
+	}
+
+	// Convert out of the Montgomery form.
+	copy(t, r)
+	for i := range t[n:] {
+		t[int(n)+i] = 0
+	}
+	secRedc(r, t, s, m, minv)
+}
+
+// secGetBits returns cnt <= limbBits bits of e starting at bit pos.
+func secGetBits(e []limb, pos, cnt ulong) limb {
+	i := pos / limbBits
+	sh := pos % limbBits
+	w := e[i] >> sh
+	if sh+cnt > limbBits {
+		w |= e[i+1] << (limbBits - sh)
+	}
+	return w & (1<<cnt - 1)
+}
+
+// Xmpn_sec_powm_itch returns the scratch space needed by Xmpn_sec_powm.
+func Xmpn_sec_powm_itch(tls *crt.TLS, bn mpSize, enb ulong, n mpSize) mpSize {
+	return (1<<secPowmWindow(enb)+4)*n + bn
+}
+
+// Xmpn_sec_invert sets {rp, n} to the inverse of {ap, n} modulo the odd {mp,
+// n} and returns 1 if it exists, otherwise it returns 0. {ap, n} must be less
+// than {mp, n} and is destroyed. nbcnt must be at least the sum of the bit
+// sizes of both, 2*n*limbBits is always enough.
+func Xmpn_sec_invert(tls *crt.TLS, rp, ap, mp *limb, n mpSize, nbcnt ulong, tp *limb) int32 {
+	a := limbs(ap, n)
+	m := limbs(mp, n)
+	v := limbs(rp, n)
+	t := limbs(tp, Xmpn_sec_invert_itch(tls, n))
+	b := t[:n]
+	u := t[n : 2*n]
+	m1h := t[2*n : 3*n] // (m+1)/2
+
+	// Maintain a = u * orig_a (mod m) and b = v * orig_a (mod m), b odd.
+	// The sum of the bit sizes of a and b decreases by at least one in
+	// every iteration as long as a > 0. In the end b = gcd(orig_a, m).
+	for i := range u {
+		u[i] = 0
+		v[i] = 0
+	}
+	u[0] = 1
+	copy(b, m)
+	rshift(m1h, m, 1)
+	secAdd1(m1h, m1h, 1)
+	for ; nbcnt > 0; nbcnt-- {
+		odd := -(a[0] & 1)
+		swap := -cndSubN(odd, a, a, b)
+		cndAddN(swap, b, b, a)
+		cndNeg(swap, a)
+		cndSwap(swap, u, v)
+		cy := cndSubN(odd, u, u, v)
+		cndAddN(-cy, u, u, m)
+		rshift(a, a, 1)
+		cy = rshift(u, u, 1) >> (limbBits - 1)
+		cndAddN(-cy, u, u, m1h)
+	}
+
+	// Return b == 1.
+	d := b[0] ^ 1
+	for _, v := range b[1:] {
+		d |= v
+	}
+	return int32(secMask(d) + 1)
+}
+
+// Xmpn_sec_invert_itch returns the scratch space needed by Xmpn_sec_invert.
+func Xmpn_sec_invert_itch(tls *crt.TLS, n mpSize) mpSize { return 3 * n }
@@ -0,0 +1,360 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The race detector instrumentation executes different code depending on the
+// state of its shadow memory.
+
+//go:build !race
+// +build !race
+
+package minigmp
+
+// The tests in this file check that the side channel silent functions execute
+// the same sequence of instructions regardless of the values of their
+// operands. The function under test runs in a child process, a copy of the
+// test binary, which the test single steps using ptrace, recording the
+// address of every executed instruction.
+
+import (
+	"debug/elf"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+const secTraceEnv = "MINIGMP_SECTRACE"
+
+// secTraceCases set up the operands of the traced operations. The operand
+// sizes are fixed, the values are derived from rnd and considered secret.
+var secTraceCases = []struct {
+	name  string
+	setup func(tls *crt.TLS, rnd *rand.Rand) func()
+	leaky bool // The control case, which must produce differing traces.
+}{
+	{"cmp", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		a := secTraceLimbs(rnd, 4)
+		b := append([]limb(nil), a...)
+		b[rnd.Intn(4)]++
+		return func() { Xmpn_cmp(tls, &a[0], &b[0], 4) }
+	}, true},
+	{"cnd_add_n", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		a, b, r := secTraceLimbs(rnd, 4), secTraceLimbs(rnd, 4), make([]limb, 4)
+		cnd := limb(rnd.Intn(2))
+		return func() { Xmpn_cnd_add_n(tls, cnd, &r[0], &a[0], &b[0], 4) }
+	}, false},
+	{"cnd_sub_n", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		a, b, r := secTraceLimbs(rnd, 4), secTraceLimbs(rnd, 4), make([]limb, 4)
+		cnd := limb(rnd.Intn(2))
+		return func() { Xmpn_cnd_sub_n(tls, cnd, &r[0], &a[0], &b[0], 4) }
+	}, false},
+	{"cnd_swap", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		a, b := secTraceLimbs(rnd, 4), secTraceLimbs(rnd, 4)
+		cnd := limb(rnd.Intn(2))
+		return func() { Xmpn_cnd_swap(tls, cnd, &a[0], &b[0], 4) }
+	}, false},
+	{"sec_add_1", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		a, r := secTraceLimbs(rnd, 4), make([]limb, 4)
+		b := secTraceLimbs(rnd, 1)[0]
+		return func() { Xmpn_sec_add_1(tls, &r[0], &a[0], 4, b, nil) }
+	}, false},
+	{"sec_sub_1", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		a, r := secTraceLimbs(rnd, 4), make([]limb, 4)
+		b := secTraceLimbs(rnd, 1)[0]
+		return func() { Xmpn_sec_sub_1(tls, &r[0], &a[0], 4, b, nil) }
+	}, false},
+	{"sec_mul", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		a, b, r := secTraceLimbs(rnd, 4), secTraceLimbs(rnd, 3), make([]limb, 7)
+		return func() { Xmpn_sec_mul(tls, &r[0], &a[0], 4, &b[0], 3, nil) }
+	}, false},
+	{"sec_sqr", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		a, r := secTraceLimbs(rnd, 4), make([]limb, 8)
+		return func() { Xmpn_sec_sqr(tls, &r[0], &a[0], 4, nil) }
+	}, false},
+	{"sec_tabselect", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		tab, r := secTraceLimbs(rnd, 2*8), make([]limb, 2)
+		which := mpSize(rnd.Intn(8))
+		return func() { Xmpn_sec_tabselect(tls, &r[0], &tab[0], 2, 8, which) }
+	}, false},
+	{"sec_div_qr", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		np, dp, qp := secTraceLimbs(rnd, 3), secTraceLimbs(rnd, 2), make([]limb, 1)
+		dp[1] |= 1
+		tp := make([]limb, Xmpn_sec_div_qr_itch(tls, 3, 2))
+		return func() { Xmpn_sec_div_qr(tls, &qp[0], &np[0], 3, &dp[0], 2, &tp[0]) }
+	}, false},
+	{"sec_div_r", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		np, dp := secTraceLimbs(rnd, 3), secTraceLimbs(rnd, 2)
+		dp[1] |= 1
+		tp := make([]limb, Xmpn_sec_div_r_itch(tls, 3, 2))
+		return func() { Xmpn_sec_div_r(tls, &np[0], 3, &dp[0], 2, &tp[0]) }
+	}, false},
+	{"sec_powm", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		bp, ep, mp, r := secTraceLimbs(rnd, 2), secTraceLimbs(rnd, 1), secTraceLimbs(rnd, 2), make([]limb, 2)
+		mp[0] |= 1
+		mp[1] |= 1
+		tp := make([]limb, Xmpn_sec_powm_itch(tls, 2, 10, 2))
+		return func() { Xmpn_sec_powm(tls, &r[0], &bp[0], 2, &ep[0], 10, &mp[0], 2, &tp[0]) }
+	}, false},
+	{"sec_invert", func(tls *crt.TLS, rnd *rand.Rand) func() {
+		ap, mp, r := secTraceLimbs(rnd, 2), secTraceLimbs(rnd, 2), make([]limb, 2)
+		mp[0] |= 1
+		mp[1] |= 1 << (limbBits - 1)
+		ap[1] &^= 1 << (limbBits - 1)
+		tp := make([]limb, Xmpn_sec_invert_itch(tls, 2))
+		// Fewer iterations than needed for the inverse are enough here.
+		return func() { Xmpn_sec_invert(tls, &r[0], &ap[0], &mp[0], 2, 64, &tp[0]) }
+	}, false},
+}
+
+// secTraceLimbs returns n random limbs, possibly all zeros or all ones.
+func secTraceLimbs(rnd *rand.Rand, n int) []limb {
+	r := make([]limb, n)
+	for i := range r {
+		switch rnd.Intn(4) {
+		case 0:
+			// nop
+		case 1:
+			r[i] = ^limb(0)
+		default:
+			r[i] = limb(rnd.Uint64())
+		}
+	}
+	return r
+}
+
+func init() {
+	s := os.Getenv(secTraceEnv)
+	if s == "" {
+		return
+	}
+
+	// This is the traced child. Package initialization runs on the main
+	// thread, which is the one started by exec and traced by the parent.
+	var name string
+	var seed int64
+	if _, err := fmt.Sscan(s, &name, &seed); err != nil {
+		panic(err)
+	}
+
+	for _, c := range secTraceCases {
+		if c.name != name {
+			continue
+		}
+
+		op := c.setup(crt.NewTLS(), rand.New(rand.NewSource(seed)))
+		runtime.GC()
+		debug.SetGCPercent(-1)
+		secTraceStop()
+		op()
+		secTraceStop()
+		os.Exit(0)
+	}
+	panic(name)
+}
+
+// secTraceStop stops the calling thread. RawSyscall does not enter the
+// scheduler, so the instructions executed around the stop do not depend on
+// how long it takes.
+func secTraceStop() {
+	syscall.RawSyscall(syscall.SYS_TGKILL, uintptr(syscall.Getpid()), uintptr(syscall.Gettid()), uintptr(syscall.SIGSTOP))
+}
+
+// secTraceCheckBinary skips the test if the traced child does not use the
+// same addresses as the test itself. The functions executed by the child
+// are identified using runtime.FuncForPC.
+func secTraceCheckBinary(t *testing.T) {
+	f, err := elf.Open(os.Args[0])
+	if err != nil {
+		t.Skip(err)
+	}
+
+	defer f.Close()
+
+	if f.Type != elf.ET_EXEC {
+		t.Skip("position independent test binary")
+	}
+}
+
+// secTraceFunc returns the entry point and name of the function containing
+// pc.
+func secTraceFunc(pc uint64) (uint64, string) {
+	f := runtime.FuncForPC(uintptr(pc))
+	if f == nil {
+		return 0, ""
+	}
+
+	return uint64(f.Entry()), f.Name()
+}
+
+func secTraceFuncName(pc uint64) string {
+	_, nm := secTraceFunc(pc)
+	return nm
+}
+
+// secTrace runs the named case in a child process and returns the number of
+// instructions executed between the two secTraceStop calls and a hash of
+// their addresses. It returns ok == false if the trace was disturbed by a
+// signal.
+func secTrace(t *testing.T, name string, seed int64) (n int, h uint64, ok bool) {
+	// All ptrace requests must come from the thread that started the child.
+	runtime.LockOSThread()
+
+	defer runtime.UnlockOSThread()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s %d", secTraceEnv, name, seed), "GODEBUG=asyncpreemptoff=1")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Ptrace: true}
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+
+	pid := cmd.Process.Pid
+	ok = true
+	var ws syscall.WaitStatus
+	wait := func() {
+		if _, err := syscall.Wait4(pid, &ws, 0, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if ws.Exited() || ws.Signaled() {
+			t.Fatalf("%s: child terminated: %v", name, ws)
+		}
+	}
+
+	// Skip the stop after exec and run until the first secTraceStop.
+	wait()
+	for {
+		if err := syscall.PtraceCont(pid, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		if wait(); ws.StopSignal() == syscall.SIGSTOP {
+			break
+		}
+	}
+
+	// The traced goroutine is preempted at function prologues when it runs
+	// for too long, which it does as it is being single stepped. The
+	// scheduler is entered through runtime.morestack, which returns to the
+	// start of the preempted function. Such excursions are removed from the
+	// trace together with the first execution of the function prologue.
+	var trace []uint64
+	var skipTo uint64
+	var regs syscall.PtraceRegs
+	for {
+		if err := syscall.PtraceSingleStep(pid); err != nil {
+			t.Fatal(err)
+		}
+
+		wait()
+		switch sig := ws.StopSignal(); sig {
+		case syscall.SIGTRAP:
+			if err := syscall.PtraceGetRegs(pid, &regs); err != nil {
+				t.Fatal(err)
+			}
+
+			pc := regs.Rip
+			switch {
+			case skipTo != 0:
+				if pc == skipTo {
+					skipTo = 0
+					trace = append(trace, pc)
+				}
+			case len(trace) != 0 && strings.HasPrefix(secTraceFuncName(pc), "runtime.morestack"):
+				skipTo, _ = secTraceFunc(trace[len(trace)-1])
+				for len(trace) != 0 && trace[len(trace)-1] != skipTo {
+					trace = trace[:len(trace)-1]
+				}
+				if len(trace) == 0 {
+					t.Fatalf("%s: cannot find the start of the preempted function", name)
+				}
+
+				trace = trace[:len(trace)-1]
+			default:
+				trace = append(trace, pc)
+			}
+			continue
+		case syscall.SIGSTOP:
+			// The second secTraceStop.
+		default:
+			ok = false
+			continue
+		}
+
+		break
+	}
+
+	h = 14695981039346656037 // FNV-1a
+	for _, pc := range trace {
+		h = (h ^ pc) * 1099511628211
+	}
+	n = len(trace)
+
+	// Let the child exit.
+	for {
+		if err := syscall.PtraceCont(pid, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := syscall.Wait4(pid, &ws, 0, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if ws.Exited() || ws.Signaled() {
+			if ws.ExitStatus() != 0 {
+				t.Fatalf("%s: child failed: %v", name, ws)
+			}
+
+			return n, h, ok
+		}
+	}
+}
+
+func TestMpnSecTrace(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short")
+	}
+
+	secTraceCheckBinary(t)
+
+	for _, c := range secTraceCases {
+		var n0 int
+		var h0 uint64
+		differ := false
+		for seed := int64(1); seed <= 4; seed++ {
+			var n int
+			var h uint64
+			ok := false
+			for retry := 0; !ok && retry < 3; retry++ {
+				n, h, ok = secTrace(t, c.name, seed)
+			}
+			if !ok {
+				t.Fatalf("%s: trace disturbed by signals", c.name)
+			}
+
+			switch {
+			case seed == 1:
+				n0, h0 = n, h
+			case n != n0 || h != h0:
+				if !c.leaky {
+					t.Fatalf("%s: trace depends on operand values: seed %v: %v instructions %#x, seed 1: %v instructions %#x", c.name, seed, n, h, n0, h0)
+				}
+
+				differ = true
+			}
+		}
+		if c.leaky && !differ {
+			t.Fatalf("%s: trace does not depend on operand values", c.name)
+		}
+
+		t.Logf("%s: %v instructions", c.name, n0)
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !mpsize64
+// +build !mpsize64
+
+package minigmp
+
+// mpzField is the type of the _mp_alloc and _mp_size fields of Xmpz_srcptr,
+// int in mini-gmp.
+type mpzField = int32
+
+// maxLimbs is the maximum size of a value in limbs, limited by the int32 size
+// fields of Xmpz_srcptr.
+const maxLimbs = 1<<31 - 1
@@ -0,0 +1,17 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build mpsize64
+// +build mpsize64
+
+package minigmp
+
+// mpzField is the type of the _mp_alloc and _mp_size fields of Xmpz_srcptr,
+// widened to mp_size_t by the mpsize64 build tag.
+type mpzField = int64
+
+// maxLimbs is the maximum size of a value in limbs. Sizes in bits of the sum
+// of two sizes in limbs, computed by mini-gmp in unsigned long, must not
+// overflow.
+const maxLimbs = 1<<57 - 1
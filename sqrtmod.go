@@ -0,0 +1,366 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"github.com/cznic/ccgo/crt"
+)
+
+// Xmpz_sqrtmod sets r to a square root of a modulo the prime p, the smaller of
+// the two roots in [0, p), and returns 1. If a is not a square modulo p, r is
+// not modified and the result is 0. If p is not prime, the result is 1 only if
+// a root was found nonetheless.
+func Xmpz_sqrtmod(tls *crt.TLS, r, a, p *[1]Xmpz_srcptr) int32 {
+	var b, x [1]Xmpz_srcptr
+	Xmpz_init(tls, &b)
+	Xmpz_init(tls, &x)
+
+	defer func() {
+		Xmpz_clear(tls, &b)
+		Xmpz_clear(tls, &x)
+	}()
+
+	Xmpz_mod(tls, &b, a, p)
+	if !sqrtPrime(tls, &x, &b, p) {
+		return 0
+	}
+
+	minRoot(tls, &x, p)
+	Xmpz_swap(tls, r, &x)
+	return 1
+}
+
+// Xmpz_sqrtmod_pow_ui sets r to a square root of a modulo p^k, p prime, and
+// returns 1. The root is the smaller of x and p^k-x, where x is the root found
+// by Hensel lifting a root modulo p. If a is not a square modulo p^k, r is not
+// modified and the result is 0.
+func Xmpz_sqrtmod_pow_ui(tls *crt.TLS, r, a, p *[1]Xmpz_srcptr, k ulong) int32 {
+	var b, q, x [1]Xmpz_srcptr
+	Xmpz_init(tls, &b)
+	Xmpz_init(tls, &q)
+	Xmpz_init(tls, &x)
+
+	defer func() {
+		Xmpz_clear(tls, &b)
+		Xmpz_clear(tls, &q)
+		Xmpz_clear(tls, &x)
+	}()
+
+	Xmpz_pow_ui(tls, &q, p, k)
+	Xmpz_mod(tls, &b, a, &q)
+	if b[0].X_mp_size != 0 {
+		// a = p^e*b, b coprime to p. A root is p^(e/2) times a root of b
+		// modulo p^(k-e).
+		e := ulong(0)
+		for Xmpz_divisible_p(tls, &b, p) != 0 {
+			Xmpz_divexact(tls, &b, &b, p)
+			e++
+		}
+		if e&1 != 0 || !sqrtPrimePower(tls, &x, &b, p, k-e) {
+			return 0
+		}
+
+		Xmpz_pow_ui(tls, &b, p, e/2)
+		Xmpz_mul(tls, &x, &x, &b)
+		Xmpz_mod(tls, &x, &x, &q)
+		minRoot(tls, &x, &q)
+	}
+	Xmpz_swap(tls, r, &x)
+	return 1
+}
+
+// Xmpz_sqrtmod_factors sets r to a square root of a modulo n in [0, n), where
+// n is the product of the powers P^K of f, and returns 1. The primes P must be
+// distinct. The root is combined by the Chinese remainder theorem from the
+// roots of Xmpz_sqrtmod_pow_ui modulo the powers. If a is not a square modulo
+// n, r is not modified and the result is 0.
+func Xmpz_sqrtmod_factors(tls *crt.TLS, r, a *[1]Xmpz_srcptr, f []PrimeFactor) int32 {
+	var m, p, q, x, y [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&m, &p, &q, &x, &y} {
+		Xmpz_init(tls, v)
+	}
+
+	defer func() {
+		for _, v := range []*[1]Xmpz_srcptr{&m, &p, &q, &x, &y} {
+			Xmpz_clear(tls, v)
+		}
+	}()
+
+	// x is a root modulo m, the product of the powers so far.
+	Xmpz_set_ui(tls, &m, 1)
+	for _, v := range f {
+		Xmpz_set_big(tls, &p, v.P)
+		if Xmpz_sqrtmod_pow_ui(tls, &y, a, &p, ulong(v.K)) == 0 {
+			return 0
+		}
+
+		Xmpz_pow_ui(tls, &q, &p, ulong(v.K))
+		crtMerge(tls, &x, &m, &y, &q)
+	}
+	Xmpz_swap(tls, r, &x)
+	return 1
+}
+
+// minRoot sets x, a root modulo m, to the smaller of x and m-x.
+func minRoot(tls *crt.TLS, x, m *[1]Xmpz_srcptr) {
+	var t [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+	Xmpz_sub(tls, &t, m, x)
+	if Xmpz_cmp(tls, &t, x) < 0 {
+		Xmpz_swap(tls, x, &t)
+	}
+	Xmpz_clear(tls, &t)
+}
+
+// sqrtPrime sets x to a square root of b modulo the prime p, b in [0, p), and
+// reports whether b is a square. The result is verified, so it is false for a
+// composite p unless the root found is valid.
+func sqrtPrime(tls *crt.TLS, x, b, p *[1]Xmpz_srcptr) bool {
+	if b[0].X_mp_size == 0 || Xmpz_cmp_ui(tls, p, 2) == 0 {
+		Xmpz_set(tls, x, b)
+		return true
+	}
+
+	if mpzLow(p)&1 == 0 || Xmpz_legendre(tls, b, p) != 1 {
+		return false
+	}
+
+	var e, t [1]Xmpz_srcptr
+	Xmpz_init(tls, &e)
+	Xmpz_init(tls, &t)
+
+	defer func() {
+		Xmpz_clear(tls, &e)
+		Xmpz_clear(tls, &t)
+	}()
+
+	switch mpzLow(p) & 7 {
+	case 3, 7:
+		// x = b^((p+1)/4).
+		Xmpz_add_ui(tls, &e, p, 1)
+		Xmpz_tdiv_q_2exp(tls, &e, &e, 2)
+		Xmpz_powm(tls, x, b, &e, p)
+	case 5:
+		// Atkin: v = (2b)^((p-5)/8), i = 2bv^2, x = bv(i-1).
+		Xmpz_tdiv_q_2exp(tls, &e, p, 3)
+		Xmpz_mul_2exp(tls, &t, b, 1)
+		Xmpz_powm(tls, &e, &t, &e, p)
+		Xmpz_mul(tls, x, b, &e)
+		Xmpz_mul(tls, &e, &e, &e)
+		Xmpz_mod(tls, &e, &e, p)
+		Xmpz_mul(tls, &e, &e, &t)
+		Xmpz_sub_ui(tls, &e, &e, 1)
+		Xmpz_mul(tls, x, x, &e)
+		Xmpz_mod(tls, x, x, p)
+	default:
+		// There is no non-residue modulo a perfect square.
+		if Xmpz_perfect_square_p(tls, p) != 0 {
+			return false
+		}
+
+		// Tonelli-Shanks takes about s^2/4 multiplications for p-1 =
+		// 2^s*q, Cipolla about six times the number of bits of p.
+		Xmpz_sub_ui(tls, &e, p, 1)
+		s := Xmpz_scan1(tls, &e, 0)
+		if uint64(s)*uint64(s) > 24*uint64(Xmpz_sizeinbase(tls, p, 2)) {
+			cipolla(tls, x, b, p)
+			break
+		}
+
+		tonelliShanks(tls, x, b, p, s)
+	}
+	Xmpz_mul(tls, &t, x, x)
+	return Xmpz_congruent_p(tls, &t, b, p) != 0
+}
+
+// tonelliShanks sets x to a square root of the quadratic residue b modulo the
+// prime p, p-1 = 2^s*q, q odd, using the Tonelli-Shanks algorithm.
+func tonelliShanks(tls *crt.TLS, x, b, p *[1]Xmpz_srcptr, s ulong) {
+	var c, q, t, u [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&c, &q, &t, &u} {
+		Xmpz_init(tls, v)
+	}
+
+	defer func() {
+		for _, v := range []*[1]Xmpz_srcptr{&c, &q, &t, &u} {
+			Xmpz_clear(tls, v)
+		}
+	}()
+
+	// c = z^q for the least non-residue z, x = b^((q+1)/2), t = b^q.
+	z := ulong(2)
+	for Xmpz_ui_kronecker(tls, z, p) != -1 {
+		z++
+	}
+	Xmpz_sub_ui(tls, &q, p, 1)
+	Xmpz_tdiv_q_2exp(tls, &q, &q, s)
+	Xmpz_set_ui(tls, &c, z)
+	Xmpz_powm(tls, &c, &c, &q, p)
+	Xmpz_add_ui(tls, &u, &q, 1)
+	Xmpz_tdiv_q_2exp(tls, &u, &u, 1)
+	Xmpz_powm(tls, x, b, &u, p)
+	Xmpz_powm(tls, &t, b, &q, p)
+
+	// Invariant: x^2 = bt, t has order 2^i, i < m, c has order 2^m.
+	for m := s; Xmpz_cmp_ui(tls, &t, 1) != 0; {
+		i := ulong(0)
+		Xmpz_set(tls, &u, &t)
+		for Xmpz_cmp_ui(tls, &u, 1) != 0 {
+			if i++; i == m {
+				// Not reached for a prime p and a residue b.
+				return
+			}
+
+			Xmpz_mul(tls, &u, &u, &u)
+			Xmpz_mod(tls, &u, &u, p)
+		}
+
+		// u = c^(2^(m-i-1)), x = xu, c = u^2, t = tc.
+		Xmpz_set(tls, &u, &c)
+		for j := i + 1; j < m; j++ {
+			Xmpz_mul(tls, &u, &u, &u)
+			Xmpz_mod(tls, &u, &u, p)
+		}
+		Xmpz_mul(tls, x, x, &u)
+		Xmpz_mod(tls, x, x, p)
+		Xmpz_mul(tls, &c, &u, &u)
+		Xmpz_mod(tls, &c, &c, p)
+		Xmpz_mul(tls, &t, &t, &c)
+		Xmpz_mod(tls, &t, &t, p)
+		m = i
+	}
+}
+
+// cipolla sets x to a square root of the quadratic residue b modulo the prime
+// p using Cipolla's algorithm, x = (t+w)^((p+1)/2) in GF(p^2) = GF(p)(w),
+// where w^2 = t^2-b is a non-residue.
+func cipolla(tls *crt.TLS, x, b, p *[1]Xmpz_srcptr) {
+	var d, e, u, v, y, s [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&d, &e, &u, &v, &y, &s} {
+		Xmpz_init(tls, v)
+	}
+
+	defer func() {
+		for _, v := range []*[1]Xmpz_srcptr{&d, &e, &u, &v, &y, &s} {
+			Xmpz_clear(tls, v)
+		}
+	}()
+
+	t := ulong(1)
+	for {
+		Xmpz_set_ui(tls, &d, t*t)
+		Xmpz_sub(tls, &d, &d, b)
+		Xmpz_mod(tls, &d, &d, p)
+		if Xmpz_legendre(tls, &d, p) == -1 {
+			break
+		}
+
+		t++
+	}
+
+	// (x+yw)(u+vw) = (xu+yvd)+(xv+yu)w, d = w^2.
+	mul := func(u, v *[1]Xmpz_srcptr) {
+		Xmpz_mul(tls, &s, x, u)
+		Xmpz_mul(tls, &e, &y, v)
+		Xmpz_mul(tls, &e, &e, &d)
+		Xmpz_add(tls, &s, &s, &e)
+		Xmpz_mul(tls, &e, x, v)
+		Xmpz_addmul(tls, &e, &y, u)
+		Xmpz_mod(tls, x, &s, p)
+		Xmpz_mod(tls, &y, &e, p)
+	}
+	Xmpz_add_ui(tls, &e, p, 1)
+	Xmpz_tdiv_q_2exp(tls, &e, &e, 1)
+	n := Xmpz_sizeinbase(tls, &e, 2)
+	bits := make([]bool, n)
+	for i := range bits {
+		bits[i] = Xmpz_tstbit(tls, &e, ulong(i)) != 0
+	}
+	Xmpz_set_ui(tls, x, 1)
+	Xmpz_set_ui(tls, &y, 0)
+	Xmpz_set_ui(tls, &u, t)
+	Xmpz_set_ui(tls, &v, 1)
+	for i := n - 1; ; i-- {
+		if bits[i] {
+			mul(&u, &v)
+		}
+		if i == 0 {
+			break
+		}
+
+		Xmpz_set(tls, &u, x)
+		Xmpz_set(tls, &v, &y)
+		mul(&u, &v)
+		Xmpz_set_ui(tls, &u, t)
+		Xmpz_set_ui(tls, &v, 1)
+	}
+}
+
+// sqrtPrimePower sets x to a square root of b modulo p^k, b coprime to the
+// prime p, and reports whether b is a square.
+func sqrtPrimePower(tls *crt.TLS, x, b, p *[1]Xmpz_srcptr, k ulong) bool {
+	if Xmpz_cmp_ui(tls, p, 2) == 0 {
+		return sqrt2k(tls, x, b, k)
+	}
+
+	var q, t, u [1]Xmpz_srcptr
+	for _, v := range []*[1]Xmpz_srcptr{&q, &t, &u} {
+		Xmpz_init(tls, v)
+	}
+
+	defer func() {
+		for _, v := range []*[1]Xmpz_srcptr{&q, &t, &u} {
+			Xmpz_clear(tls, v)
+		}
+	}()
+
+	Xmpz_mod(tls, &t, b, p)
+	if !sqrtPrime(tls, x, &t, p) {
+		return false
+	}
+
+	// Newton's iteration x = x-(x^2-b)/(2x) doubles the precision of x.
+	for e := ulong(1); e < k; {
+		e = min(2*e, k)
+		Xmpz_pow_ui(tls, &q, p, e)
+		Xmpz_mul_2exp(tls, &u, x, 1)
+		Xmpz_invert(tls, &u, &u, &q)
+		Xmpz_mul(tls, &t, x, x)
+		Xmpz_sub(tls, &t, &t, b)
+		Xmpz_mul(tls, &t, &t, &u)
+		Xmpz_sub(tls, x, x, &t)
+		Xmpz_mod(tls, x, x, &q)
+	}
+	return true
+}
+
+// sqrt2k sets x to a square root of the odd b modulo 2^k and reports whether b
+// is a square.
+func sqrt2k(tls *crt.TLS, x, b *[1]Xmpz_srcptr, k ulong) bool {
+	switch {
+	case k == 1:
+		Xmpz_set_ui(tls, x, 1)
+		return true
+	case k == 2:
+		Xmpz_set_ui(tls, x, 1)
+		return mpzLow(b)&3 == 1
+	case mpzLow(b)&7 != 1:
+		return false
+	}
+
+	// x^2 = b (mod 2^i). If not modulo 2^(i+1), (x+2^(i-1))^2 is.
+	var t [1]Xmpz_srcptr
+	Xmpz_init(tls, &t)
+	Xmpz_set_ui(tls, x, 1)
+	for i := ulong(3); i < k; i++ {
+		Xmpz_mul(tls, &t, x, x)
+		Xmpz_sub(tls, &t, &t, b)
+		if Xmpz_tstbit(tls, &t, i) != 0 {
+			Xmpz_setbit(tls, x, i-1)
+		}
+	}
+	Xmpz_clear(tls, &t)
+	return true
+}
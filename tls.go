@@ -0,0 +1,36 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"runtime"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// tlsFree holds the idle TLSs used by the functions not taking a TLS. They
+// belong to the default context. The list is bounded, putTLS closes the TLSs
+// not fitting in it.
+var tlsFree = make(chan *crt.TLS, 4*runtime.GOMAXPROCS(0))
+
+// getTLS returns an idle TLS, or a new one if there is none. The caller must
+// return it by a deferred putTLS, so a panic does not lose it.
+func getTLS() *crt.TLS {
+	select {
+	case tls := <-tlsFree:
+		return tls
+	default:
+		return crt.NewTLS()
+	}
+}
+
+// putTLS returns tls to the free list, or closes it if the list is full.
+func putTLS(tls *crt.TLS) {
+	select {
+	case tlsFree <- tls:
+	default:
+		tls.Close()
+	}
+}
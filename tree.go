@@ -0,0 +1,284 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// The functions below use the default context. The values they set must be
+// allocated by it, or not allocated yet, ie. initialized by Mpz_init or
+// Xmpz_init with a TLS not obtained from a Context.
+
+var treeParallel int32 = 1
+
+// SetTreeParallelism sets the maximum number of goroutines processing the
+// nodes of a level of a product tree, or of the remainder trees derived from
+// it, and returns the previous value. Values below 2, the default, disable
+// the parallelism. The results do not depend on the setting.
+func SetTreeParallelism(n int) (old int) {
+	if n < 1 {
+		n = 1
+	}
+	return int(atomic.SwapInt32(&treeParallel, int32(n)))
+}
+
+// eachNode calls f for every i in [0, n), concurrently if enabled by
+// SetTreeParallelism. The TLS passed to f belongs to the default context. A
+// panic of f stops the remaining calls and is propagated to the caller of
+// eachNode after all workers have returned.
+func eachNode(n int, f func(tls *crt.TLS, i int)) {
+	g := min(int(atomic.LoadInt32(&treeParallel)), n)
+	if g <= 1 {
+		tls := getTLS()
+
+		defer putTLS(tls)
+
+		for i := 0; i < n; i++ {
+			f(tls, i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	var next atomic.Int64
+	var failed atomic.Pointer[any] // The first panic value.
+	for j := 0; j < g; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			defer func() {
+				if e := recover(); e != nil {
+					failed.CompareAndSwap(nil, &e)
+					next.Store(int64(n))
+				}
+			}()
+
+			tls := getTLS()
+
+			defer putTLS(tls)
+
+			for i := int(next.Add(1) - 1); i < n; i = int(next.Add(1) - 1) {
+				f(tls, i)
+			}
+		}()
+	}
+	wg.Wait()
+	if e := failed.Load(); e != nil {
+		panic(*e)
+	}
+}
+
+// ProductTree is the balanced binary tree of the products of its leaves.
+// Levels[0] holds the leaves. A node of Levels[i+1] is the product of two
+// adjacent nodes of Levels[i], except that an odd last node is carried to the
+// next level unchanged. The last level holds only the root, the product of all
+// the leaves.
+//
+// The values of a ProductTree are allocated by the default context and must
+// not be modified. Clear releases them.
+type ProductTree struct {
+	Levels [][][1]Xmpz_srcptr
+}
+
+// NewProductTree returns the product tree with leaves set to x. x must not be
+// empty.
+func NewProductTree(x []*[1]Xmpz_srcptr) *ProductTree {
+	if len(x) == 0 {
+		panic("NewProductTree: no leaves")
+	}
+
+	leaves := make([][1]Xmpz_srcptr, len(x))
+	t := &ProductTree{Levels: [][][1]Xmpz_srcptr{leaves}}
+	ok := false
+
+	defer func() {
+		if !ok {
+			// A panic of a node, the nodes not initialized are zero.
+			t.Clear()
+		}
+	}()
+
+	eachNode(len(x), func(tls *crt.TLS, i int) { Xmpz_init_set(tls, &leaves[i], x[i]) })
+	for l := leaves; len(l) > 1; {
+		u := make([][1]Xmpz_srcptr, (len(l)+1)/2)
+		t.Levels = append(t.Levels, u)
+		eachNode(len(u), func(tls *crt.TLS, i int) {
+			Xmpz_init(tls, &u[i])
+			if 2*i+1 == len(l) {
+				Xmpz_set(tls, &u[i], &l[2*i])
+				return
+			}
+
+			Xmpz_mul(tls, &u[i], &l[2*i], &l[2*i+1])
+		})
+		l = u
+	}
+	ok = true
+	return t
+}
+
+// Root returns the root of t, the product of all its leaves.
+func (t *ProductTree) Root() *[1]Xmpz_srcptr { return &t.Levels[len(t.Levels)-1][0] }
+
+// Clear releases the values of t. t must not be used afterwards.
+func (t *ProductTree) Clear() {
+	for _, l := range t.Levels {
+		eachNode(len(l), func(tls *crt.TLS, i int) { Xmpz_clear(tls, &l[i]) })
+	}
+	t.Levels = nil
+}
+
+// down computes the values of a tree with the shape of t from the root to the
+// leaves. It sets the value of the root by root and the value of every other
+// node by node from the value of its parent and its index. The values of the
+// leaves are passed to leaf. Only two levels of values are kept at a time.
+// They are released if a function panics.
+func (t *ProductTree) down(root func(tls *crt.TLS, v *[1]Xmpz_srcptr), node func(tls *crt.TLS, v, parent *[1]Xmpz_srcptr, level, i int), leaf func(tls *crt.TLS, v *[1]Xmpz_srcptr, i int)) {
+	// The values not initialized, or cleared already, are zero.
+	u, l := make([][1]Xmpz_srcptr, 1), [][1]Xmpz_srcptr(nil)
+	release := func(v [][1]Xmpz_srcptr) {
+		eachNode(len(v), func(tls *crt.TLS, i int) {
+			Xmpz_clear(tls, &v[i])
+			v[i] = [1]Xmpz_srcptr{}
+		})
+	}
+
+	defer func() {
+		release(u)
+		release(l)
+	}()
+
+	eachNode(1, func(tls *crt.TLS, _ int) {
+		Xmpz_init(tls, &u[0])
+		root(tls, &u[0])
+	})
+	for level := len(t.Levels) - 2; level >= 0; level-- {
+		l = make([][1]Xmpz_srcptr, len(t.Levels[level]))
+		eachNode(len(l), func(tls *crt.TLS, i int) {
+			Xmpz_init(tls, &l[i])
+			node(tls, &l[i], &u[i/2], level, i)
+		})
+		release(u)
+		u, l = l, nil
+	}
+	eachNode(len(u), func(tls *crt.TLS, i int) {
+		leaf(tls, &u[i], i)
+		Xmpz_clear(tls, &u[i])
+		u[i] = [1]Xmpz_srcptr{}
+	})
+}
+
+// Remainders sets r[i] to n mod |x_i| for the leaves x_i of t, which must not
+// be zero. len(r) must be the number of leaves. The remainders are computed
+// by a remainder tree, reducing the remainder of a node modulo its children.
+func (t *ProductTree) Remainders(r []*[1]Xmpz_srcptr, n *[1]Xmpz_srcptr) {
+	if len(r) != len(t.Levels[0]) {
+		panic("ProductTree.Remainders: invalid number of results")
+	}
+
+	t.down(
+		func(tls *crt.TLS, v *[1]Xmpz_srcptr) { Xmpz_mod(tls, v, n, t.Root()) },
+		func(tls *crt.TLS, v, parent *[1]Xmpz_srcptr, level, i int) {
+			Xmpz_mod(tls, v, parent, &t.Levels[level][i])
+		},
+		func(tls *crt.TLS, v *[1]Xmpz_srcptr, i int) { Xmpz_swap(tls, r[i], v) },
+	)
+}
+
+// ScaledRemainders is like Remainders, but it uses the scaled remainder tree
+// of Bernstein. Only the root remainder needs a division, the value of a node
+// below is the fraction (n mod x)/x, x being the product of the node, in
+// fixed point with precision sufficient for the exact result. The fraction of
+// a node is the fractional part of the fraction of its parent times the
+// product of its sibling.
+func (t *ProductTree) ScaledRemainders(r []*[1]Xmpz_srcptr, n *[1]Xmpz_srcptr) {
+	if len(r) != len(t.Levels[0]) {
+		panic("ProductTree.ScaledRemainders: invalid number of results")
+	}
+
+	// Truncation errors of the fractions at most double with every level,
+	// guard bits make them vanish in the rounding of the final products.
+	guard := ulong(len(t.Levels) + 4)
+	prec := func(tls *crt.TLS, level, i int) ulong {
+		return ulong(Xmpz_sizeinbase(tls, &t.Levels[level][i], 2)) + guard
+	}
+	top := len(t.Levels) - 1
+	t.down(
+		func(tls *crt.TLS, v *[1]Xmpz_srcptr) {
+			// v = floor((n mod x)*2^p/|x|).
+			Xmpz_mod(tls, v, n, t.Root())
+			Xmpz_mul_2exp(tls, v, v, prec(tls, top, 0))
+			Xmpz_tdiv_q(tls, v, v, t.Root())
+			Xmpz_abs(tls, v, v)
+		},
+		func(tls *crt.TLS, v, parent *[1]Xmpz_srcptr, level, i int) {
+			p := prec(tls, level+1, i/2)
+			if n := len(t.Levels[level]); n%2 == 1 && i == n-1 {
+				// A carried node has the product of its parent.
+				Xmpz_set(tls, v, parent)
+				return
+			}
+
+			Xmpz_mul(tls, v, parent, &t.Levels[level][i^1])
+			Xmpz_abs(tls, v, v)
+			Xmpz_tdiv_r_2exp(tls, v, v, p)
+			Xmpz_tdiv_q_2exp(tls, v, v, p-prec(tls, level, i))
+		},
+		func(tls *crt.TLS, v *[1]Xmpz_srcptr, i int) {
+			// r = round(v*|x|/2^p) mod |x|.
+			x := &t.Levels[0][i]
+			p := prec(tls, 0, i)
+			Xmpz_mul(tls, v, v, x)
+			Xmpz_abs(tls, v, v)
+			Xmpz_tdiv_q_2exp(tls, v, v, p-1)
+			Xmpz_add_ui(tls, v, v, 1)
+			Xmpz_tdiv_q_2exp(tls, v, v, 1)
+			Xmpz_mod(tls, r[i], v, x)
+		},
+	)
+}
+
+// BatchGCD sets r[i] to gcd(x_i, x_0*...*x_{i-1}*x_{i+1}*...) for the values
+// x_i of x using the batch GCD algorithm of Bernstein: the remainders of the
+// product P of all values modulo x_i^2 are computed by a remainder tree and
+// then r[i] = gcd(x_i, (P mod x_i^2)/x_i). The values must not be zero. For
+// RSA moduli x, r[i] != 1 reveals a factor of x_i shared with another modulus.
+// len(r) must be len(x), r may be x.
+func BatchGCD(r, x []*[1]Xmpz_srcptr) {
+	if len(r) != len(x) {
+		panic("BatchGCD: invalid number of results")
+	}
+
+	if len(x) == 0 {
+		return
+	}
+
+	t := NewProductTree(x)
+
+	defer t.Clear()
+
+	t.down(
+		func(tls *crt.TLS, v *[1]Xmpz_srcptr) { Xmpz_abs(tls, v, t.Root()) },
+		func(tls *crt.TLS, v, parent *[1]Xmpz_srcptr, level, i int) {
+			var sq [1]Xmpz_srcptr
+			Xmpz_init(tls, &sq)
+
+			defer Xmpz_clear(tls, &sq)
+
+			Xmpz_mul(tls, &sq, &t.Levels[level][i], &t.Levels[level][i])
+			Xmpz_mod(tls, v, parent, &sq)
+		},
+		func(tls *crt.TLS, v *[1]Xmpz_srcptr, i int) {
+			x := &t.Levels[0][i]
+			Xmpz_divexact(tls, v, v, x)
+			Xmpz_gcd(tls, r[i], v, x)
+		},
+	)
+}
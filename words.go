@@ -0,0 +1,505 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+
+import (
+	"fmt"
+	"math/bits"
+	"unsafe"
+
+	"github.com/cznic/ccgo/crt"
+)
+
+// Word is a limb, an unsigned integer of WordBits bits.
+//
+// The Mpn* functions operate on natural numbers stored in []Word, least
+// significant word first. The lengths of the operands are the lengths of the
+// slices. The functions panic if the lengths do not satisfy the documented
+// requirements or if a result overlaps an operand it must not overlap. A
+// result may be the very same slice as an operand where the documentation
+// says so.
+type Word = limb
+
+// WordBits is the size of a Word in bits.
+const WordBits = limbBits
+
+// MaxWords is the maximum size of a value in Words. Functions whose result
+// would exceed it panic with a *LimitError, see SetMaxBits.
+//
+// The _mp_alloc and _mp_size fields of Xmpz_srcptr are 32 bit integers and
+// MaxWords is 2^31-1 on linux/amd64 and 2^26-1 on linux/386. On linux/amd64,
+// the mpsize64 build tag widens the fields to 64 bits and MaxWords to 2^57-1.
+const MaxWords = maxLimbs
+
+const wordSize = unsafe.Sizeof(Word(0))
+
+// mpnPanic panics with a message naming the function fn.
+func mpnPanic(fn, format string, args ...interface{}) {
+	panic(fmt.Sprintf("%s: %s", fn, fmt.Sprintf(format, args...)))
+}
+
+// overlap reports whether a and b share memory.
+func overlap(a, b []Word) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+
+	pa, pb := uintptr(unsafe.Pointer(&a[0])), uintptr(unsafe.Pointer(&b[0]))
+	return pa < pb+uintptr(len(b))*wordSize && pb < pa+uintptr(len(a))*wordSize
+}
+
+// same reports whether a and b start at the same address.
+func same(a, b []Word) bool {
+	return len(a) != 0 && len(b) != 0 && &a[0] == &b[0]
+}
+
+// cWords returns n > 0 words allocated by the Context of tls, holding a copy
+// of a, len(a) <= n. The Mpn* functions pass their operands to the generated
+// code only in such copies, the checkptr instrumentation enabled by -race
+// rejects its pointer arithmetic on Go memory. The other Mpn* functions use
+// the kernels of the mpn layer directly.
+func cWords(tls *crt.TLS, n int, a []Word) []Word {
+	r := unsafe.Slice((*Word)(_gmp_allocate_func(tls, sizeT(n)*sizeT(wordSize))), n)
+	copy(r, a)
+	return r
+}
+
+// freeWords releases words returned by cWords.
+func freeWords(tls *crt.TLS, s []Word) {
+	_gmp_free_func(tls, unsafe.Pointer(&s[0]), sizeT(len(s))*sizeT(wordSize))
+}
+
+// checkLen panics unless len(s) == n.
+func checkLen(fn, name string, s []Word, n int) {
+	if len(s) != n {
+		mpnPanic(fn, "len(%s) is %v, expected %v", name, len(s), n)
+	}
+}
+
+// checkInPlace panics if r overlaps a unless r and a are the same slice.
+func checkInPlace(fn string, r, a []Word, rName, aName string) {
+	if overlap(r, a) && !(same(r, a) && len(r) == len(a)) {
+		mpnPanic(fn, "%s partially overlaps %s", rName, aName)
+	}
+}
+
+// checkDisjoint panics if r overlaps a.
+func checkDisjoint(fn string, r, a []Word, rName, aName string) {
+	if overlap(r, a) {
+		mpnPanic(fn, "%s overlaps %s", rName, aName)
+	}
+}
+
+// MpnAddN sets r to a+b and returns the carry. All slices must have the same
+// length. r may be a and/or b.
+func MpnAddN(r, a, b []Word) (carry Word) {
+	const fn = "MpnAddN"
+	checkLen(fn, "a", a, len(r))
+	checkLen(fn, "b", b, len(r))
+	checkInPlace(fn, r, a, "r", "a")
+	checkInPlace(fn, r, b, "r", "b")
+	if len(r) == 0 {
+		return 0
+	}
+
+	return Mpn_add_n(&r[0], &a[0], &b[0], mpSize(len(r)))
+}
+
+// MpnSubN sets r to a-b and returns the borrow. All slices must have the
+// same length. r may be a and/or b.
+func MpnSubN(r, a, b []Word) (borrow Word) {
+	const fn = "MpnSubN"
+	checkLen(fn, "a", a, len(r))
+	checkLen(fn, "b", b, len(r))
+	checkInPlace(fn, r, a, "r", "a")
+	checkInPlace(fn, r, b, "r", "b")
+	if len(r) == 0 {
+		return 0
+	}
+
+	return Mpn_sub_n(&r[0], &a[0], &b[0], mpSize(len(r)))
+}
+
+// MpnAdd sets r to a+b and returns the carry. len(a) must be at least len(b)
+// and len(r) must be len(a). r may be a, but not b unless len(b) == len(a).
+func MpnAdd(r, a, b []Word) (carry Word) {
+	const fn = "MpnAdd"
+	checkLen(fn, "r", r, len(a))
+	if len(b) > len(a) {
+		mpnPanic(fn, "len(b) is %v, exceeds len(a) %v", len(b), len(a))
+	}
+
+	checkInPlace(fn, r, a, "r", "a")
+	checkInPlace(fn, r, b, "r", "b")
+	carry = MpnAddN(r[:len(b)], a[:len(b)], b)
+	if len(a) > len(b) {
+		carry = MpnAdd1(r[len(b):], a[len(b):], carry)
+	}
+	return carry
+}
+
+// MpnSub sets r to a-b and returns the borrow. len(a) must be at least len(b)
+// and len(r) must be len(a). r may be a, but not b unless len(b) == len(a).
+func MpnSub(r, a, b []Word) (borrow Word) {
+	const fn = "MpnSub"
+	checkLen(fn, "r", r, len(a))
+	if len(b) > len(a) {
+		mpnPanic(fn, "len(b) is %v, exceeds len(a) %v", len(b), len(a))
+	}
+
+	checkInPlace(fn, r, a, "r", "a")
+	checkInPlace(fn, r, b, "r", "b")
+	borrow = MpnSubN(r[:len(b)], a[:len(b)], b)
+	if len(a) > len(b) {
+		borrow = MpnSub1(r[len(b):], a[len(b):], borrow)
+	}
+	return borrow
+}
+
+// MpnAdd1 sets r to a+b and returns the carry. len(r) must be len(a). r may be
+// a.
+func MpnAdd1(r, a []Word, b Word) (carry Word) {
+	const fn = "MpnAdd1"
+	checkLen(fn, "r", r, len(a))
+	checkInPlace(fn, r, a, "r", "a")
+	carry = b
+	for i, v := range a {
+		r[i], carry = addWWW(v, carry, 0)
+	}
+	return carry
+}
+
+// MpnSub1 sets r to a-b and returns the borrow. len(r) must be len(a). r may
+// be a.
+func MpnSub1(r, a []Word, b Word) (borrow Word) {
+	const fn = "MpnSub1"
+	checkLen(fn, "r", r, len(a))
+	checkInPlace(fn, r, a, "r", "a")
+	borrow = b
+	for i, v := range a {
+		r[i], borrow = subWWW(v, borrow, 0)
+	}
+	return borrow
+}
+
+// MpnMul1 sets r to a*b and returns the most significant word of the
+// product. len(r) must be len(a). r may be a.
+func MpnMul1(r, a []Word, b Word) (hi Word) {
+	const fn = "MpnMul1"
+	checkLen(fn, "r", r, len(a))
+	checkInPlace(fn, r, a, "r", "a")
+	if len(r) == 0 {
+		return 0
+	}
+
+	return Mpn_mul_1(&r[0], &a[0], mpSize(len(r)), b)
+}
+
+// MpnAddMul1 adds a*b to r and returns the carry word. len(r) must be len(a).
+// r and a must not overlap.
+func MpnAddMul1(r, a []Word, b Word) (carry Word) {
+	const fn = "MpnAddMul1"
+	checkLen(fn, "r", r, len(a))
+	checkDisjoint(fn, r, a, "r", "a")
+	if len(r) == 0 {
+		return 0
+	}
+
+	return Mpn_addmul_1(&r[0], &a[0], mpSize(len(r)), b)
+}
+
+// MpnSubMul1 subtracts a*b from r and returns the borrow word. len(r) must be
+// len(a). r and a must not overlap.
+func MpnSubMul1(r, a []Word, b Word) (borrow Word) {
+	const fn = "MpnSubMul1"
+	checkLen(fn, "r", r, len(a))
+	checkDisjoint(fn, r, a, "r", "a")
+	if len(r) == 0 {
+		return 0
+	}
+
+	return Mpn_submul_1(&r[0], &a[0], mpSize(len(r)), b)
+}
+
+// MpnMul sets r to a*b. len(r) must be len(a)+len(b) and a and b must not be
+// empty. r must not overlap a or b.
+func MpnMul(r, a, b []Word) {
+	const fn = "MpnMul"
+	if len(a) == 0 || len(b) == 0 {
+		mpnPanic(fn, "empty operand")
+	}
+
+	checkLen(fn, "r", r, len(a)+len(b))
+	checkDisjoint(fn, r, a, "r", "a")
+	checkDisjoint(fn, r, b, "r", "b")
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	ca, cb, cr := cWords(tls, len(a), a), cWords(tls, len(b), b), cWords(tls, len(r), nil)
+
+	defer func() {
+		freeWords(tls, ca)
+		freeWords(tls, cb)
+		freeWords(tls, cr)
+	}()
+
+	Xmpn_mul(tls, &cr[0], &ca[0], mpSize(len(a)), &cb[0], mpSize(len(b)))
+	copy(r, cr)
+}
+
+// MpnSqr sets r to a^2. len(r) must be 2*len(a) and a must not be empty. r
+// must not overlap a.
+func MpnSqr(r, a []Word) {
+	const fn = "MpnSqr"
+	if len(a) == 0 {
+		mpnPanic(fn, "empty operand")
+	}
+
+	checkLen(fn, "r", r, 2*len(a))
+	checkDisjoint(fn, r, a, "r", "a")
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	ca, cr := cWords(tls, len(a), a), cWords(tls, len(r), nil)
+
+	defer func() {
+		freeWords(tls, ca)
+		freeWords(tls, cr)
+	}()
+
+	Xmpn_sqr(tls, &cr[0], &ca[0], mpSize(len(a)))
+	copy(r, cr)
+}
+
+func checkShift(fn string, r, a []Word, cnt uint) {
+	checkLen(fn, "r", r, len(a))
+	checkInPlace(fn, r, a, "r", "a")
+	if cnt == 0 || cnt >= WordBits {
+		mpnPanic(fn, "shift count %v not in [1, %v)", cnt, WordBits)
+	}
+}
+
+// MpnLshift sets r to a<<cnt and returns the bits shifted out, in the least
+// significant bits of the result. len(r) must be len(a) and 0 < cnt <
+// WordBits. r may be a.
+func MpnLshift(r, a []Word, cnt uint) (out Word) {
+	checkShift("MpnLshift", r, a, cnt)
+	if len(r) == 0 {
+		return 0
+	}
+
+	return Mpn_lshift(&r[0], &a[0], mpSize(len(r)), uint32(cnt))
+}
+
+// MpnRshift sets r to a>>cnt and returns the bits shifted out, in the most
+// significant bits of the result. len(r) must be len(a) and 0 < cnt <
+// WordBits. r may be a.
+func MpnRshift(r, a []Word, cnt uint) (out Word) {
+	checkShift("MpnRshift", r, a, cnt)
+	if len(r) == 0 {
+		return 0
+	}
+
+	return Mpn_rshift(&r[0], &a[0], mpSize(len(r)), uint32(cnt))
+}
+
+// MpnCmp returns -1, 0 or +1 depending on whether a < b, a == b or a > b. The
+// slices must have the same length.
+func MpnCmp(a, b []Word) int {
+	checkLen("MpnCmp", "b", b, len(a))
+	for i := len(a) - 1; i >= 0; i-- {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// MpnZeroP reports whether a is zero.
+func MpnZeroP(a []Word) bool {
+	for _, v := range a {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MpnNormalize returns a without its most significant zero words.
+func MpnNormalize(a []Word) []Word {
+	n := len(a)
+	for n > 0 && a[n-1] == 0 {
+		n--
+	}
+	return a[:n]
+}
+
+// MpnNeg sets r to -a modulo B^len(a), B = 2^WordBits, and returns 1 if a is
+// not zero, 0 otherwise. len(r) must be len(a). r may be a.
+func MpnNeg(r, a []Word) (borrow Word) {
+	const fn = "MpnNeg"
+	checkLen(fn, "r", r, len(a))
+	checkInPlace(fn, r, a, "r", "a")
+	i := 0
+	for ; i < len(a) && a[i] == 0; i++ {
+		r[i] = 0
+	}
+	if i == len(a) {
+		return 0
+	}
+
+	r[i] = -a[i]
+	for i++; i < len(a); i++ {
+		r[i] = ^a[i]
+	}
+	return 1
+}
+
+// MpnCom sets r to the one's complement of a. len(r) must be len(a). r may be
+// a.
+func MpnCom(r, a []Word) {
+	const fn = "MpnCom"
+	checkLen(fn, "r", r, len(a))
+	checkInPlace(fn, r, a, "r", "a")
+	for i, v := range a {
+		r[i] = ^v
+	}
+}
+
+// MpnPopcount returns the number of one bits of a.
+func MpnPopcount(a []Word) (n int) {
+	for _, v := range a {
+		n += bits.OnesCount(uint(v))
+	}
+	return n
+}
+
+// MpnDivQR sets q and r to the quotient and the remainder of n divided by d.
+// The most significant word of d must not be zero, len(n) must be at least
+// len(d), len(q) must be len(n)-len(d)+1 and len(r) must be len(d). q and r
+// must not overlap each other or d. n is not modified and may overlap q or r.
+func MpnDivQR(q, r, n, d []Word) {
+	const fn = "MpnDivQR"
+	switch {
+	case len(d) == 0 || d[len(d)-1] == 0:
+		mpnPanic(fn, "d is not normalized")
+	case len(n) < len(d):
+		mpnPanic(fn, "len(n) is %v, less than len(d) %v", len(n), len(d))
+	}
+
+	checkLen(fn, "q", q, len(n)-len(d)+1)
+	checkLen(fn, "r", r, len(d))
+	checkDisjoint(fn, q, r, "q", "r")
+	checkDisjoint(fn, q, d, "q", "d")
+	checkDisjoint(fn, r, d, "r", "d")
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	cq, cn, cd := cWords(tls, len(q), nil), cWords(tls, len(n), n), cWords(tls, len(d), d)
+
+	defer func() {
+		freeWords(tls, cq)
+		freeWords(tls, cn)
+		freeWords(tls, cd)
+	}()
+
+	_mpn_div_qr(tls, &cq[0], &cn[0], mpSize(len(n)), &cd[0], mpSize(len(d)))
+	copy(q, cq)
+	copy(r, cn)
+}
+
+// MpnDivRem1 sets q to n/d and returns the remainder. d must not be zero and
+// len(q) must be len(n). q may be n.
+func MpnDivRem1(q, n []Word, d Word) (rem Word) {
+	const fn = "MpnDivRem1"
+	if d == 0 {
+		mpnPanic(fn, "division by zero")
+	}
+
+	checkLen(fn, "q", q, len(n))
+	checkInPlace(fn, q, n, "q", "n")
+	if len(n) == 0 {
+		return 0
+	}
+
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	c := cWords(tls, len(n), n)
+
+	defer freeWords(tls, c)
+
+	rem = _mpn_div_qr_1(tls, &c[0], &c[0], mpSize(len(n)), d)
+	copy(q, c)
+	return rem
+}
+
+// MpnSqrtRem sets s to the integer square root of a and, if r is not nil, r
+// to the remainder a-s^2. It returns the number of words of the normalized
+// remainder. a must not be empty and its most significant word must not be
+// zero, len(s) must be (len(a)+1)/2 and len(r) must be len(a) unless r is nil.
+// s and r must not overlap a or each other.
+func MpnSqrtRem(s, r, a []Word) (rn int) {
+	const fn = "MpnSqrtRem"
+	if len(a) == 0 || a[len(a)-1] == 0 {
+		mpnPanic(fn, "a is not normalized")
+	}
+
+	checkLen(fn, "s", s, (len(a)+1)/2)
+	checkDisjoint(fn, s, a, "s", "a")
+	if r != nil {
+		checkLen(fn, "r", r, len(a))
+		checkDisjoint(fn, r, a, "r", "a")
+		checkDisjoint(fn, r, s, "r", "s")
+	}
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	ca, cs, cr := cWords(tls, len(a), a), cWords(tls, len(s), nil), cWords(tls, len(a), nil)
+
+	defer func() {
+		freeWords(tls, ca)
+		freeWords(tls, cs)
+		freeWords(tls, cr)
+	}()
+
+	var rp *Word
+	if r != nil {
+		rp = &cr[0]
+	}
+	rn = int(Xmpn_sqrtrem(tls, &cs[0], rp, &ca[0], mpSize(len(a))))
+	copy(s, cs)
+	copy(r, cr)
+	return rn
+}
+
+// MpnPerfectSquareP reports whether a is a perfect square. The most
+// significant word of a must not be zero.
+func MpnPerfectSquareP(a []Word) bool {
+	if len(a) == 0 || a[len(a)-1] == 0 {
+		mpnPanic("MpnPerfectSquareP", "a is not normalized")
+	}
+
+	tls := getTLS()
+
+	defer putTLS(tls)
+
+	c := cWords(tls, len(a), a)
+
+	defer freeWords(tls, c)
+
+	return Xmpn_perfect_square_p(tls, &c[0], mpSize(len(a))) != 0
+}
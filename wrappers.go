@@ -0,0 +1,264 @@
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+// Command wrappers writes notls_$GOOS_$GOARCH.go, containing a variant not
+// taking a TLS of every exported Xmp*_ and Xgmp_ function of the package.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const prologue = `// Code generated by 'go run wrappers.go', DO NOT EDIT.
+
+// Copyright 2017 The Minigmp Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package minigmp
+`
+
+var (
+	oArch = flag.String("arch", "", "GOARCH, all supported architectures if empty")
+)
+
+type fn struct {
+	name    string   // Name of the wrapped function.
+	params  []string // Names, without the TLS.
+	types   []string // Types, without the TLS.
+	results []string
+	imports []string // Import paths of the packages used by the types.
+}
+
+func main() {
+	log.SetFlags(log.Lshortfile | log.Lmicroseconds)
+	flag.Parse()
+	arches := []string{"386", "amd64"}
+	if *oArch != "" {
+		arches = []string{*oArch}
+	}
+	for _, arch := range arches {
+		gen(arch)
+	}
+}
+
+// gen writes the wrappers for linux/arch.
+func gen(arch string) {
+	ctx := build.Default
+	ctx.GOOS = "linux"
+	ctx.GOARCH = arch
+	ctx.CgoEnabled = false
+	matches, err := filepath.Glob("*.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	fns := map[string]*fn{}
+	for _, v := range matches {
+		if strings.HasSuffix(v, "_test.go") || strings.HasPrefix(v, "notls_") {
+			continue
+		}
+
+		if ok, err := ctx.MatchFile(".", v); err != nil || !ok {
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, v, nil, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		imports := map[string]string{}
+		for _, v := range f.Imports {
+			pth := strings.Trim(v.Path.Value, `"`)
+			nm := pth[strings.LastIndex(pth, "/")+1:]
+			if v.Name != nil {
+				nm = v.Name.Name
+			}
+			imports[nm] = pth
+		}
+		for _, d := range f.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil {
+				if x := wrap(fset, fd, imports); x != nil {
+					fns[strings.ToUpper(x.name[1:2])+x.name[2:]] = x
+				}
+			}
+		}
+	}
+
+	var names []string
+	for k := range fns {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	b.WriteString(prologue)
+	imports := map[string]bool{}
+	for _, f := range fns {
+		for _, v := range f.imports {
+			imports[v] = true
+		}
+	}
+	if len(imports) != 0 {
+		var a []string
+		for k := range imports {
+			a = append(a, k)
+		}
+		sort.Strings(a)
+		b.WriteString("\nimport (\n")
+		for _, v := range a {
+			fmt.Fprintf(&b, "\t%q\n", v)
+		}
+		b.WriteString(")\n")
+	}
+	for _, k := range names {
+		f := fns[k]
+		var params []string
+		for i, v := range f.params {
+			params = append(params, fmt.Sprintf("%s %s", v, f.types[i]))
+		}
+		results := strings.Join(f.results, ", ")
+		if len(f.results) > 1 {
+			results = "(" + results + ")"
+		}
+		args := strings.Join(append([]string{"tls"}, f.params...), ", ")
+		fmt.Fprintf(&b, "\n// %s is like %s but does not take a TLS.\n", k, f.name)
+		fmt.Fprintf(&b, "func %s(%s) %s {\n", k, strings.Join(params, ", "), results)
+		b.WriteString("\ttls := getTLS()\n\n\tdefer putTLS(tls)\n\n")
+		switch {
+		case len(f.results) == 0:
+			fmt.Fprintf(&b, "\t%s(%s)\n", f.name, args)
+		default:
+			fmt.Fprintf(&b, "\treturn %s(%s)\n", f.name, args)
+		}
+		b.WriteString("}\n")
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatalf("%s\n%v", b.Bytes(), err)
+	}
+
+	if err := ioutil.WriteFile(fmt.Sprintf("notls_linux_%s.go", arch), src, 0664); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// wrap returns the wrapper of fd, or nil if fd is not an exported Xmp*_ or Xgmp_
+// function with a TLS first parameter and no other use of package crt.
+func wrap(fset *token.FileSet, fd *ast.FuncDecl, imports map[string]string) *fn {
+	nm := fd.Name.Name
+	if !strings.HasPrefix(nm, "Xmp") && !strings.HasPrefix(nm, "Xgmp") || !strings.Contains(nm, "_") {
+		return nil
+	}
+
+	if len(fd.Type.Params.List) == 0 {
+		return nil
+	}
+
+	var names, types []string
+	for i, v := range fd.Type.Params.List {
+		typ := expr(fset, v.Type)
+		if i == 0 {
+			if typ != "*crt.TLS" || len(v.Names) != 1 {
+				return nil
+			}
+
+			continue
+		}
+
+		if strings.Contains(typ, "crt.") {
+			return nil
+		}
+
+		for _, n := range v.Names {
+			names = append(names, param(n.Name))
+			types = append(types, typ)
+		}
+	}
+
+	var results []string
+	if r := fd.Type.Results; r != nil {
+		for _, v := range r.List {
+			typ := expr(fset, v.Type)
+			if strings.Contains(typ, "crt.") {
+				return nil
+			}
+
+			n := len(v.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				results = append(results, typ)
+			}
+		}
+	}
+	var paths []string
+	for i, v := range fd.Type.Params.List {
+		if i != 0 {
+			paths = append(paths, uses(v.Type, imports)...)
+		}
+	}
+	if r := fd.Type.Results; r != nil {
+		for _, v := range r.List {
+			paths = append(paths, uses(v.Type, imports)...)
+		}
+	}
+	return &fn{name: nm, params: names, types: types, results: results, imports: paths}
+}
+
+// uses returns the import paths of the packages referred to by n.
+func uses(n ast.Expr, imports map[string]string) (r []string) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		if x, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := x.X.(*ast.Ident); ok && imports[id.Name] != "" {
+				r = append(r, imports[id.Name])
+			}
+		}
+		return true
+	})
+	return r
+}
+
+// param returns the wrapper parameter name for the parameter nm, stripping the
+// leading underscore of the generated code where possible.
+func param(nm string) string {
+	s := strings.TrimLeft(nm, "_")
+	if s == "" || s == "tls" || token.Lookup(s).IsKeyword() || strings.HasPrefix(s, "r") && len(s) == 2 && s[1] >= '0' && s[1] <= '9' {
+		return nm
+	}
+
+	return s
+}
+
+func expr(fset *token.FileSet, n ast.Expr) string {
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, fset, n); err != nil {
+		log.Fatal(err)
+	}
+
+	return b.String()
+}